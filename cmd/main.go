@@ -3,16 +3,23 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/askwhyharsh/lazytrader/internal/config"
 	"github.com/askwhyharsh/lazytrader/internal/database"
+	"github.com/askwhyharsh/lazytrader/internal/events"
 	"github.com/askwhyharsh/lazytrader/internal/listener"
+	"github.com/askwhyharsh/lazytrader/internal/notify"
+	"github.com/askwhyharsh/lazytrader/internal/polymarket"
 
-	// "github.com/askwhyharsh/lazytrader/internal/executor"
+	"github.com/askwhyharsh/lazytrader/internal/executor"
 	"github.com/askwhyharsh/lazytrader/internal/ingestion"
 	"github.com/askwhyharsh/lazytrader/internal/server"
 )
@@ -25,47 +32,167 @@ func main() {
 	// Load configuration
 	cfg, err := config.Load("./config.yaml")
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		slog.Error("failed to load config", "err", err)
+		os.Exit(1)
 	}
+	setupLogging(cfg)
 
 	// Initialize database
 	db, err := database.New(cfg.DatabasePath)
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		slog.Error("failed to initialize database", "path", cfg.DatabasePath, "err", err)
+		os.Exit(1)
 	}
 	defer db.Close()
 
-	// Initialize components
-	ingestor := ingestion.New(cfg, db)
-	// exec := executor.New(cfg, db)
+	// Components communicate through the event bus
+	bus := events.New()
 
-	// Start ingestion service (event listener)
-	go func() {
-		if err := ingestor.Start(ctx); err != nil {
-			log.Printf("Ingestion service error: %v", err)
+	// Token IDs are named after their market and outcome, shared so every
+	// component benefits from one cache
+	gamma := polymarket.NewClient(&http.Client{Timeout: 10 * time.Second}).WithBaseURL(polymarket.GAMMA_API_URL)
+	gamma.Retries = cfg.APIRetryAttempts
+	gamma.Backoff = cfg.APIRetryBackoff
+	tokens := polymarket.NewTokenResolver(gamma)
+
+	// Every subsystem runs until ctx is cancelled; shutdown waits for them
+	var wg sync.WaitGroup
+	run := func(name string, fn func() error) {
+		wg.Go(func() {
+			if err := fn(); err != nil && !errors.Is(err, context.Canceled) {
+				slog.Error("subsystem failed", "subsystem", name, "err", err)
+			}
+			slog.Info("subsystem stopped", "subsystem", name)
+		})
+	}
+
+	run("Database maintenance", func() error {
+		db.RunMaintenance(ctx, cfg.DBOptimizeInterval, cfg.DBVacuumInterval)
+		return nil
+	})
+
+	// Detected signals are announced on Telegram when it's configured
+	notifier := notify.NewTelegramNotifier(cfg, tokens)
+	run("Telegram notifier", func() error { return notifier.Start(ctx, bus) })
+
+	// Each strategy gets its own ingestion, listener and scoped database.
+	// The first strategy also serves the unprefixed API routes.
+	var srv *server.Server
+	var botTargets []notify.Target
+	for _, scfg := range cfg.ForStrategies() {
+		sdb := db.ForStrategy(scfg.StrategyID)
+		slog.Info("starting strategy", "strategy", scfg.StrategyID)
+
+		// Initialize components
+		ingestor := ingestion.New(scfg, sdb)
+
+		// Copy trading is opt-in; without it signals are only detected
+		var exec *executor.Executor
+		if cfg.ExecutorEnabled {
+			exec = executor.New(scfg, sdb, bus, tokens)
+			run("Executor ("+scfg.StrategyID+")", func() error { return exec.Start(ctx) })
 		}
-	}()
 
-	// start listener
-	lister , _ := listener.NewPolymarketListener(cfg, db)
-	go func() {
-		if err :=lister.Start(ctx); err != nil {
-			log.Printf("Listener service error: %v",err)
+		// Start ingestion service (event listener)
+		run("Ingestion service ("+scfg.StrategyID+")", func() error { return ingestor.Start(ctx) })
+
+		// start listener
+		var lister *listener.PolymarketListener
+		if scfg.SignalSource != "dataapi" {
+			lister, err = listener.NewPolymarketListener(scfg, sdb, bus)
+			if err != nil {
+				slog.Error("failed to start listener, check polygon_rpc_url and polygon_rpc_urls",
+					"strategy", scfg.StrategyID, "err", err)
+				os.Exit(1)
+			}
+			run("Listener service ("+scfg.StrategyID+")", func() error { return lister.Start(ctx) })
 		}
-	}()
 
-	// Start HTTP server
-	srv := server.New(cfg, db)
-	go func() {
-		if err := srv.Start(); err != nil {
-			log.Printf("Server error: %v", err)
+		// Keep open positions marked to market
+		prices := executor.NewPriceRefresher(scfg, sdb)
+		run("Price refresher ("+scfg.StrategyID+")", func() error { return prices.Start(ctx) })
+
+		// Data API poller as an alternative or extra signal source
+		if scfg.SignalSource != "onchain" {
+			poller := listener.NewDataAPIPoller(scfg, sdb, bus)
+			run("Data API poller ("+scfg.StrategyID+")", func() error { return poller.Start(ctx) })
 		}
-	}()
+
+		if srv == nil {
+			srv = server.New(scfg, sdb, bus, tokens, exec, lister, ingestor)
+		}
+		srv.AddStrategy(scfg.StrategyID, scfg, sdb, exec, lister, ingestor)
+
+		// Telegram commands report on and control every strategy
+		target := notify.Target{ID: scfg.StrategyID, DB: sdb}
+		if exec != nil {
+			target.Trader = exec
+		}
+		botTargets = append(botTargets, target)
+
+		// Trading endpoints wait until this strategy's components are up
+		awaitReady(srv, "ingestion:"+scfg.StrategyID, ingestor.Ready())
+		if lister != nil {
+			awaitReady(srv, "listener:"+scfg.StrategyID, lister.Ready())
+		}
+	}
+
+	run("Telegram command listener", func() error { return notifier.Listen(ctx, botTargets) })
+
+	// Start HTTP server
+	run("HTTP server", srv.Start)
 
 	// Wait for shutdown signal
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChan // block until signal is received
-	log.Println("Shutting down gracefully...")
+	slog.Info("shutting down gracefully")
 
-}
\ No newline at end of file
+	// Stop taking requests and let in-flight ones finish, then stop the
+	// background loops; trades already started still run to completion
+	shutdownCtx, done := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer done()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("HTTP server shutdown failed", "err", err)
+	}
+	cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		slog.Info("all subsystems stopped")
+	case <-shutdownCtx.Done():
+		slog.Warn("gave up waiting for subsystems", "timeout", cfg.ShutdownTimeout)
+	}
+}
+
+// setupLogging makes slog's default logger, which the standard log package
+// also writes through, use the configured level and format
+func setupLogging(cfg *config.Config) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		level = slog.LevelInfo
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler = slog.NewTextHandler(os.Stderr, opts)
+	if cfg.LogFormat == "json" {
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	}
+	slog.SetDefault(slog.New(handler))
+}
+
+// awaitReady registers a component with the server's readiness gate and
+// marks it ready once its ready channel closes
+func awaitReady(srv *server.Server, component string, ready <-chan struct{}) {
+	markReady := srv.Require(component)
+	go func() {
+		<-ready
+		slog.Info("component ready", "component", component)
+		markReady()
+	}()
+}
@@ -3,7 +3,11 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -12,27 +16,222 @@ type Config struct {
 	// Database
 	DatabasePath string `yaml:"database_path"`
 
+	// How long an Idempotency-Key replays its original response
+	IdempotencyKeyTTL time.Duration `yaml:"idempotency_key_ttl"`
+
+	// Database maintenance: PRAGMA optimize and VACUUM intervals
+	DBOptimizeInterval time.Duration `yaml:"db_optimize_interval"`
+	DBVacuumInterval   time.Duration `yaml:"db_vacuum_interval"`
+
+	// Strategies run side by side with their own capital, positions and
+	// tracked traders. Empty runs a single "default" strategy.
+	Strategies []StrategyConfig `yaml:"strategies"`
+	StrategyID string           `yaml:"-"` // Set on per-strategy configs
+
+	// HTTP server port and timeouts
+	HTTPPort              string        `yaml:"http_port"`
+	HTTPReadTimeout       time.Duration `yaml:"http_read_timeout"`
+	HTTPReadHeaderTimeout time.Duration `yaml:"http_read_header_timeout"`
+	HTTPWriteTimeout      time.Duration `yaml:"http_write_timeout"`
+	HTTPIdleTimeout       time.Duration `yaml:"http_idle_timeout"`
+
+	// Bearer token required by every route except health checks and
+	// /metrics. Empty leaves the API open, for local development.
+	APIKey string `yaml:"api_key"`
+
+	// Browser origins allowed to call the API, e.g. "https://dash.example.com",
+	// or "*" for any. Empty sends no CORS headers, so only same-origin pages can.
+	AllowedOrigins []string `yaml:"allowed_origins"`
+
+	// Requests per minute allowed from each client IP
+	HTTPRateLimit int `yaml:"http_rate_limit"`
+
+	// How long shutdown waits for requests, trades and loops to finish
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
 	// Polymarket
 	TopTradersCount     int     `yaml:"top_traders_count"`
 	MinProfitThreshold  float64 `yaml:"min_profit_threshold"`
 	CopyTradeMultiplier float64 `yaml:"copy_trade_multiplier"`
+	SizingMode          string  `yaml:"sizing_mode"` // "proportional" or "fixed"
+
+	// Tracked set ranking: weights of normalized PnL, win rate and consistency
+	ScoreWeightPnL         float64 `yaml:"score_weight_pnl"`
+	ScoreWeightWinRate     float64 `yaml:"score_weight_win_rate"`
+	ScoreWeightConsistency float64 `yaml:"score_weight_consistency"`
+
+	FixedCopyAmount float64 `yaml:"fixed_copy_amount"` // USDC per copied buy in fixed mode
+
+	// Polymarket Data API request retries (network errors, 429s and 5xxs)
+	APIRetryAttempts int           `yaml:"api_retry_attempts"`
+	APIRetryBackoff  time.Duration `yaml:"api_retry_backoff"`
+
+	// Traders off the leaderboard this long stop being tracked
+	TraderStaleAfter time.Duration `yaml:"trader_stale_after"`
+
+	// How long a trader's computed win rate is reused before re-fetching
+	WinRateCacheTTL time.Duration `yaml:"win_rate_cache_ttl"`
+
+	// How often the leaderboard is refreshed
+	LeaderboardPollInterval time.Duration `yaml:"leaderboard_poll_interval"`
+
+	// Leaderboard page fetched each refresh
+	LeaderboardTimePeriod string `yaml:"leaderboard_time_period"` // "day", "week" or "month"
+	LeaderboardOrderBy    string `yaml:"leaderboard_order_by"`    // "PNL" or "VOL"
+	LeaderboardLimit      int    `yaml:"leaderboard_limit"`
+
+	// Leaderboard refresh retries
+	LeaderboardRetryAttempts int           `yaml:"leaderboard_retry_attempts"`
+	LeaderboardRetryBackoff  time.Duration `yaml:"leaderboard_retry_backoff"`
+	LeaderboardDegradedAfter int           `yaml:"leaderboard_degraded_after"` // failed cycles before alerting
+
+	// Executor
+	MaxConcurrentTrades int     `yaml:"max_concurrent_trades"` // In-flight trade submissions
+	SignalMaxAttempts   int     `yaml:"signal_max_attempts"`   // Transient failures before dead-lettering
+	ReserveBalance      float64 `yaml:"reserve_balance"`       // USDC never spent on copies, kept for gas and exits
+	MaxTradeNotional    float64 `yaml:"max_trade_notional"`    // USDC cap per copied trade, 0 disables
+	MaxVaultFraction    float64 `yaml:"max_vault_fraction"`    // Largest share of the USDC balance one buy may use
+
+	// How often open positions are marked to the CLOB midpoint
+	PriceRefreshInterval time.Duration `yaml:"price_refresh_interval"`
+	MinTradeNotional     float64       `yaml:"min_trade_notional"` // Smaller copies are skipped
+
+	// Fee gate: skip copies whose estimated fees exceed this fraction of notional
+	MaxFeeFraction   float64 `yaml:"max_fee_fraction"`
+	ClobFeeBps       float64 `yaml:"clob_fee_bps"`
+	GasTokenPriceUSD float64 `yaml:"gas_token_price_usd"` // POL price used to convert gas costs
+
+	// Copies are trimmed until their estimated price impact is within this, 0 disables
+	MaxPriceImpactBps float64 `yaml:"max_price_impact_bps"`
+
+	// Per-market max_price_impact_bps by condition ID, for markets with thinner
+	// or deeper books; 0 turns the check off for that market
+	MarketMaxPriceImpactBps map[string]float64 `yaml:"market_max_price_impact_bps"`
+
+	// Copied exits wait this long and are dropped if the trader re-enters, 0 disables
+	ExitConfirmationDelay time.Duration `yaml:"exit_confirmation_delay"`
+
+	// Skip buys of an outcome when we hold another outcome of the same market
+	AvoidSelfHedging bool `yaml:"avoid_self_hedging"`
+
+	// Trading pauses while the newest leaderboard data is older than this, 0 disables
+	MaxLeaderboardStaleness time.Duration `yaml:"max_leaderboard_staleness"`
+
+	// Markets copied from, by condition ID, slug or category (case-insensitive).
+	// A non-empty allowlist copies only matching markets; the denylist wins.
+	MarketAllowlist []string `yaml:"market_allowlist"`
+	MarketDenylist  []string `yaml:"market_denylist"`
+
+	// When copy trades may execute; detection keeps running outside it
+	TradingSchedule TradingSchedule `yaml:"trading_schedule"`
 
 	// Telegram
-	TelegramBotToken string  `yaml:"telegram_bot_token"`
-	TelegramChatID   int64   `yaml:"telegram_chat_id"`
+	TelegramBotToken string `yaml:"telegram_bot_token"`
+	TelegramChatID   int64  `yaml:"telegram_chat_id"`
+
+	// More than TelegramMaxMessages in one window are sent as a digest
+	TelegramMaxMessages  int           `yaml:"telegram_max_messages"`
+	TelegramDigestWindow time.Duration `yaml:"telegram_digest_window"`
 
 	// Wallet
-	PrivateKey      string `yaml:"private_key"`
-	WalletAddress   string `yaml:"wallet_address"`
-	PolygonRPCURL   string `yaml:"polygon_rpc_url"`
+	PrivateKey    string `yaml:"private_key"` // Plaintext fallback, prefer keystore_path
+	WalletAddress string `yaml:"wallet_address"`
+	PolygonRPCURL string `yaml:"polygon_rpc_url"`
+
+	// Encrypted JSON keystore holding the signing key. Its passphrase is
+	// only read from LAZYTRADER_KEYSTORE_PASSPHRASE, never from the file.
+	KeystorePath       string `yaml:"keystore_path"`
+	KeystorePassphrase string `yaml:"-"`
+
+	// Endpoints tried in order, failing over to the next when one is down.
+	// polygon_rpc_url, when set, goes first.
+	PolygonRPCURLs []string `yaml:"polygon_rpc_urls"`
+
+	// Polymarket CLOB L2 API credentials for the wallet, and how its orders
+	// are signed: 0 = EOA, 1 = Polymarket proxy, 2 = Gnosis Safe
+	ClobAPIKey        string `yaml:"clob_api_key"`
+	ClobAPISecret     string `yaml:"clob_api_secret"`
+	ClobAPIPassphrase string `yaml:"clob_api_passphrase"`
+	ClobSignatureType int    `yaml:"clob_signature_type"`
+
+	// Where copy signals come from: "onchain", "dataapi" or "both" (deduped)
+	SignalSource        string        `yaml:"signal_source"`
+	DataAPIPollInterval time.Duration `yaml:"data_api_poll_interval"` // Per-trader trade history polling
+
+	// Listener
+	HeaderBufferSize  int           `yaml:"header_buffer_size"`  // Buffered new heads / queued blocks
+	BackfillBatchSize int           `yaml:"backfill_batch_size"` // Blocks per FilterLogs call when backfilling
+	MaxClockSkew      time.Duration `yaml:"max_clock_skew"`      // Alert when host clock and block time disagree by more
+
+	// On-chain signals are only acted on once their block has this many
+	// confirmations, counting itself; fills reorged out meanwhile are dropped
+	ConfirmationBlocks int `yaml:"confirmation_blocks"`
+
+	// Fills worth less than this many USDC aren't stored as signals, 0 keeps all
+	MinSignalNotionalUSDC float64 `yaml:"min_signal_notional_usdc"`
+
+	// Log 1 in N fills from untracked traders (0 = none); tracked traders'
+	// fills are always logged, in "full" or "summary" detail
+	SignalLogSampling  int    `yaml:"signal_log_sampling"`
+	SignalLogVerbosity string `yaml:"signal_log_verbosity"`
+
+	// Keep fills of one order seen on both the CTF and NegRisk exchanges
+	KeepCrossExchangeDuplicates bool `yaml:"keep_cross_exchange_duplicates"`
 
 	// // Proxy Settings (NEW)
 	// ProxyEnabled    bool   `yaml:"proxy_enabled"`
 	// ProxyURL        string `yaml:"proxy_url"`
 	// ProxyType       string `yaml:"proxy_type"` // "socks5", "http", "https"
 
+	// Logging
+	LogLevel  string `yaml:"log_level"`  // "debug", "info", "warn" or "error"
+	LogFormat string `yaml:"log_format"` // "text" or "json"
+
+	// Debugging: raw leaderboard responses are written here when set
+	DebugDumpDir      string `yaml:"debug_dump_dir"`
+	DebugDumpMaxFiles int    `yaml:"debug_dump_max_files"`
+
 	// Feature Flags
-	DryRun          bool   `yaml:"dry_run"`
+	DryRun bool `yaml:"dry_run"`
+
+	// Copy tracked traders' signals. Off, the bot only tracks the
+	// leaderboard and detects signals.
+	ExecutorEnabled bool `yaml:"executor_enabled"`
+}
+
+// StrategyConfig overrides top-level settings for one strategy. Zero values
+// inherit the top-level setting.
+type StrategyConfig struct {
+	ID                  string  `yaml:"id"` // Used in /strategies/{id}/... routes
+	PrivateKey          string  `yaml:"private_key"`
+	KeystorePath        string  `yaml:"keystore_path"`
+	WalletAddress       string  `yaml:"wallet_address"`
+	ClobAPIKey          string  `yaml:"clob_api_key"`
+	ClobAPISecret       string  `yaml:"clob_api_secret"`
+	ClobAPIPassphrase   string  `yaml:"clob_api_passphrase"`
+	ClobSignatureType   int     `yaml:"clob_signature_type"`
+	TopTradersCount     int     `yaml:"top_traders_count"`
+	MinProfitThreshold  float64 `yaml:"min_profit_threshold"`
+	CopyTradeMultiplier float64 `yaml:"copy_trade_multiplier"`
+	SizingMode          string  `yaml:"sizing_mode"`
+	FixedCopyAmount     float64 `yaml:"fixed_copy_amount"`
+	MaxTradeNotional    float64 `yaml:"max_trade_notional"`
+	ReserveBalance      float64 `yaml:"reserve_balance"`
+}
+
+// TradingSchedule restricts trading to daily windows, minus blackouts.
+// Times are "HH:MM" in Timezone; a window whose end is before its start
+// wraps past midnight.
+type TradingSchedule struct {
+	Timezone        string       `yaml:"timezone"`         // IANA name, default UTC
+	AllowedWindows  []TimeWindow `yaml:"allowed_windows"`  // Empty allows all day
+	BlackoutWindows []TimeWindow `yaml:"blackout_windows"` // Recurring daily
+	BlackoutDates   []string     `yaml:"blackout_dates"`   // One-off whole days, "2006-01-02"
+}
+
+type TimeWindow struct {
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
 }
 
 func Load(path string) (*Config, error) {
@@ -41,15 +240,58 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	var cfg Config
+	// Settings where 0 is meaningful take their defaults before parsing, so
+	// only a missing key falls back to them
+	cfg := Config{
+		MaxVaultFraction:   0.2,
+		MinTradeNotional:   1.0,
+		SignalLogSampling:  1000,
+		ConfirmationBlocks: 5,
+	}
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
+	// Environment variables take precedence, so secrets can stay out of the file
+	if err := applyEnv(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid environment override: %w", err)
+	}
+	cfg.KeystorePassphrase = os.Getenv(EnvName("keystore_passphrase"))
+
 	// Set defaults
 	if cfg.DatabasePath == "" {
 		cfg.DatabasePath = "./data/lazytrader.db"
 	}
+	if cfg.HTTPPort == "" {
+		cfg.HTTPPort = "4000"
+	}
+	if cfg.IdempotencyKeyTTL == 0 {
+		cfg.IdempotencyKeyTTL = 24 * time.Hour
+	}
+	if cfg.DBOptimizeInterval == 0 {
+		cfg.DBOptimizeInterval = time.Hour
+	}
+	if cfg.DBVacuumInterval == 0 {
+		cfg.DBVacuumInterval = 7 * 24 * time.Hour
+	}
+	if cfg.HTTPReadTimeout == 0 {
+		cfg.HTTPReadTimeout = 15 * time.Second
+	}
+	if cfg.HTTPReadHeaderTimeout == 0 {
+		cfg.HTTPReadHeaderTimeout = 5 * time.Second
+	}
+	if cfg.HTTPWriteTimeout == 0 {
+		cfg.HTTPWriteTimeout = 30 * time.Second
+	}
+	if cfg.HTTPIdleTimeout == 0 {
+		cfg.HTTPIdleTimeout = 60 * time.Second
+	}
+	if cfg.HTTPRateLimit == 0 {
+		cfg.HTTPRateLimit = 120
+	}
+	if cfg.ShutdownTimeout == 0 {
+		cfg.ShutdownTimeout = 30 * time.Second
+	}
 	if cfg.TopTradersCount == 0 {
 		cfg.TopTradersCount = 10
 	}
@@ -59,25 +301,301 @@ func Load(path string) (*Config, error) {
 	if cfg.CopyTradeMultiplier == 0 {
 		cfg.CopyTradeMultiplier = 0.1
 	}
-	if cfg.PolygonRPCURL == "" {
-		cfg.PolygonRPCURL = "https://polygon-rpc.com"
+	if cfg.ScoreWeightPnL == 0 && cfg.ScoreWeightWinRate == 0 && cfg.ScoreWeightConsistency == 0 {
+		cfg.ScoreWeightPnL = 1.0 // Rank purely by PnL
+	}
+	if cfg.SizingMode == "" {
+		cfg.SizingMode = "proportional"
+	}
+	if cfg.LeaderboardPollInterval == 0 {
+		cfg.LeaderboardPollInterval = 10 * time.Minute
+	}
+	if cfg.LeaderboardTimePeriod == "" {
+		cfg.LeaderboardTimePeriod = "week"
+	}
+	if cfg.LeaderboardOrderBy == "" {
+		cfg.LeaderboardOrderBy = "PNL"
+	}
+	if cfg.LeaderboardLimit == 0 {
+		cfg.LeaderboardLimit = 20
+	}
+	if cfg.LeaderboardRetryAttempts == 0 {
+		cfg.LeaderboardRetryAttempts = 3
+	}
+	if cfg.LeaderboardRetryBackoff == 0 {
+		cfg.LeaderboardRetryBackoff = 5 * time.Second
+	}
+	if cfg.LeaderboardDegradedAfter == 0 {
+		cfg.LeaderboardDegradedAfter = 3
+	}
+	if cfg.MaxConcurrentTrades == 0 {
+		cfg.MaxConcurrentTrades = 4
+	}
+	if cfg.SignalMaxAttempts == 0 {
+		cfg.SignalMaxAttempts = 3
+	}
+	if cfg.TelegramMaxMessages == 0 {
+		cfg.TelegramMaxMessages = 5
+	}
+	if cfg.TelegramDigestWindow == 0 {
+		cfg.TelegramDigestWindow = time.Minute
 	}
+	if cfg.PriceRefreshInterval == 0 {
+		cfg.PriceRefreshInterval = time.Minute
+	}
+	if cfg.MaxFeeFraction == 0 {
+		cfg.MaxFeeFraction = 0.05
+	}
+	if cfg.GasTokenPriceUSD == 0 {
+		cfg.GasTokenPriceUSD = 0.5
+	}
+	if cfg.APIRetryAttempts == 0 {
+		cfg.APIRetryAttempts = 3
+	}
+	if cfg.APIRetryBackoff == 0 {
+		cfg.APIRetryBackoff = time.Second
+	}
+	if cfg.TraderStaleAfter == 0 {
+		cfg.TraderStaleAfter = 24 * time.Hour
+	}
+	if cfg.WinRateCacheTTL == 0 {
+		cfg.WinRateCacheTTL = 6 * time.Hour
+	}
+	if cfg.BackfillBatchSize == 0 {
+		cfg.BackfillBatchSize = 500
+	}
+	if cfg.HeaderBufferSize == 0 {
+		cfg.HeaderBufferSize = 64
+	}
+	if cfg.SignalSource == "" {
+		cfg.SignalSource = "onchain"
+	}
+	if cfg.DataAPIPollInterval == 0 {
+		cfg.DataAPIPollInterval = 15 * time.Second
+	}
+	if cfg.SignalLogVerbosity == "" {
+		cfg.SignalLogVerbosity = "full"
+	}
+	if cfg.MaxClockSkew == 0 {
+		cfg.MaxClockSkew = 30 * time.Second
+	}
+	if cfg.LogLevel == "" {
+		cfg.LogLevel = "info"
+	}
+	if cfg.LogFormat == "" {
+		cfg.LogFormat = "text"
+	}
+	if cfg.DebugDumpMaxFiles == 0 {
+		cfg.DebugDumpMaxFiles = 50
+	}
+	if cfg.PolygonRPCURL != "" && !slices.Contains(cfg.PolygonRPCURLs, cfg.PolygonRPCURL) {
+		cfg.PolygonRPCURLs = append([]string{cfg.PolygonRPCURL}, cfg.PolygonRPCURLs...)
+	}
+	if len(cfg.PolygonRPCURLs) == 0 {
+		cfg.PolygonRPCURLs = []string{"https://polygon-rpc.com"}
+	}
+	cfg.PolygonRPCURL = cfg.PolygonRPCURLs[0]
 
+	if len(cfg.Strategies) == 0 {
+		cfg.StrategyID = "default"
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
 	return &cfg, nil
 }
 
+// ForStrategies returns one resolved config per strategy, in declaration
+// order. Without configured strategies it's just the top-level config.
+func (c *Config) ForStrategies() []*Config {
+	if len(c.Strategies) == 0 {
+		return []*Config{c}
+	}
+
+	configs := make([]*Config, 0, len(c.Strategies))
+	for _, sc := range c.Strategies {
+		cfg := *c
+		cfg.Strategies = nil
+		cfg.StrategyID = sc.ID
+		if sc.PrivateKey != "" || sc.KeystorePath != "" {
+			cfg.PrivateKey = sc.PrivateKey
+			cfg.KeystorePath = sc.KeystorePath
+		}
+		if sc.WalletAddress != "" {
+			cfg.WalletAddress = sc.WalletAddress
+		}
+		if sc.ClobAPIKey != "" {
+			cfg.ClobAPIKey = sc.ClobAPIKey
+			cfg.ClobAPISecret = sc.ClobAPISecret
+			cfg.ClobAPIPassphrase = sc.ClobAPIPassphrase
+		}
+		if sc.ClobSignatureType != 0 {
+			cfg.ClobSignatureType = sc.ClobSignatureType
+		}
+		if sc.TopTradersCount != 0 {
+			cfg.TopTradersCount = sc.TopTradersCount
+		}
+		if sc.MinProfitThreshold != 0 {
+			cfg.MinProfitThreshold = sc.MinProfitThreshold
+		}
+		if sc.CopyTradeMultiplier != 0 {
+			cfg.CopyTradeMultiplier = sc.CopyTradeMultiplier
+		}
+		if sc.SizingMode != "" {
+			cfg.SizingMode = sc.SizingMode
+		}
+		if sc.FixedCopyAmount != 0 {
+			cfg.FixedCopyAmount = sc.FixedCopyAmount
+		}
+		if sc.MaxTradeNotional != 0 {
+			cfg.MaxTradeNotional = sc.MaxTradeNotional
+		}
+		if sc.ReserveBalance != 0 {
+			cfg.ReserveBalance = sc.ReserveBalance
+		}
+		configs = append(configs, &cfg)
+	}
+	return configs
+}
+
+// Shortest leaderboard refresh interval accepted, to stay clear of API rate limits
+const minLeaderboardPollInterval = 30 * time.Second
+
+// Validate checks the settings ingestion, the listener and the API need,
+// then, when executor_enabled is set, those copy trading needs, for every
+// strategy. Errors name the YAML key at fault and why it matters.
 func (c *Config) Validate() error {
-	if c.TelegramBotToken == "" {
-		return fmt.Errorf("telegram_bot_token is required")
+	if err := c.validateStrategies(); err != nil {
+		return err
+	}
+	for _, sc := range c.ForStrategies() {
+		err := sc.validateIngestion()
+		if err == nil && c.ExecutorEnabled {
+			err = sc.validateExecutor()
+		}
+		if err != nil && len(c.Strategies) > 0 {
+			return fmt.Errorf("strategy %q: %w", sc.StrategyID, err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateIngestion covers what every mode uses, including read-only
+// leaderboard tracking
+func (c *Config) validateIngestion() error {
+	if port, err := strconv.Atoi(c.HTTPPort); err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("http_port must be a port number for the API server, got %q", c.HTTPPort)
+	}
+	switch c.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("log_level must be 'debug', 'info', 'warn' or 'error', got %q", c.LogLevel)
+	}
+	if c.LogFormat != "text" && c.LogFormat != "json" {
+		return fmt.Errorf("log_format must be 'text' or 'json', got %q", c.LogFormat)
+	}
+	if c.MinProfitThreshold < 0 {
+		return fmt.Errorf("min_profit_threshold must be 0 or more, it's the PnL a trader needs to be tracked")
+	}
+	if c.CopyTradeMultiplier < 0 || c.CopyTradeMultiplier > 1 {
+		return fmt.Errorf("copy_trade_multiplier must be between 0 and 1, it's the fraction of each copied trade's size")
 	}
-	if c.TelegramChatID == 0 {
-		return fmt.Errorf("telegram_chat_id is required")
+	if c.ShutdownTimeout < 0 {
+		return fmt.Errorf("shutdown_timeout must be positive")
 	}
-	if c.PrivateKey == "" {
-		return fmt.Errorf("private_key is required")
+	if c.HTTPRateLimit < 0 {
+		return fmt.Errorf("http_rate_limit must be positive, it's requests per minute per client IP")
 	}
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		// Browsers send the Origin header as scheme://host[:port], nothing more
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" || u.RawQuery != "" {
+			return fmt.Errorf("allowed_origins entries must be \"*\" or scheme://host[:port], got %q", origin)
+		}
+	}
+	if c.LeaderboardPollInterval < minLeaderboardPollInterval {
+		return fmt.Errorf("leaderboard_poll_interval must be at least %s to stay within API rate limits", minLeaderboardPollInterval)
+	}
+	switch c.LeaderboardTimePeriod {
+	case "day", "week", "month":
+	default:
+		return fmt.Errorf("leaderboard_time_period must be 'day', 'week' or 'month', got %q", c.LeaderboardTimePeriod)
+	}
+	if c.LeaderboardOrderBy != "PNL" && c.LeaderboardOrderBy != "VOL" {
+		return fmt.Errorf("leaderboard_order_by must be 'PNL' or 'VOL', got %q", c.LeaderboardOrderBy)
+	}
+	if c.LeaderboardLimit < 0 {
+		return fmt.Errorf("leaderboard_limit must be positive")
+	}
+	switch c.SignalSource {
+	case "onchain", "dataapi", "both":
+	default:
+		return fmt.Errorf("signal_source must be 'onchain', 'dataapi' or 'both', got %q", c.SignalSource)
+	}
+	if c.BackfillBatchSize < 0 {
+		return fmt.Errorf("backfill_batch_size must be positive, it's the block range of each log query")
+	}
+	if c.ConfirmationBlocks < 0 {
+		return fmt.Errorf("confirmation_blocks must be 0 or more, 0 or 1 acts on signals as soon as their block is seen")
+	}
+	if c.MinSignalNotionalUSDC < 0 {
+		return fmt.Errorf("min_signal_notional_usdc must be 0 or more")
+	}
+	if c.SignalLogSampling < 0 {
+		return fmt.Errorf("signal_log_sampling must be 0 or more")
+	}
+	if c.SignalLogVerbosity != "full" && c.SignalLogVerbosity != "summary" {
+		return fmt.Errorf("signal_log_verbosity must be 'full' or 'summary', got %q", c.SignalLogVerbosity)
+	}
+	if c.TelegramMaxMessages < 0 || c.TelegramDigestWindow < 0 {
+		return fmt.Errorf("telegram_max_messages and telegram_digest_window must be positive")
+	}
+	if c.PriceRefreshInterval < 0 {
+		return fmt.Errorf("price_refresh_interval must be positive")
+	}
+	return nil
+}
+
+// validateExecutor covers what signing and sizing copied orders needs
+func (c *Config) validateExecutor() error {
 	if c.WalletAddress == "" {
-		return fmt.Errorf("wallet_address is required")
+		return fmt.Errorf("wallet_address is required to copy trade, it holds the USDC and places the orders")
+	}
+	if c.KeystorePath == "" && c.PrivateKey == "" && !c.DryRun {
+		return fmt.Errorf("keystore_path (or private_key) is required to sign orders (or set dry_run: true to copy trade without one)")
+	}
+	if c.KeystorePath != "" && c.KeystorePassphrase == "" {
+		return fmt.Errorf("keystore_path is set but %s isn't, it's needed to decrypt the keystore", EnvName("keystore_passphrase"))
+	}
+	if !c.DryRun && (c.ClobAPIKey == "" || c.ClobAPISecret == "" || c.ClobAPIPassphrase == "") {
+		return fmt.Errorf("clob_api_key, clob_api_secret and clob_api_passphrase are required to post orders to the CLOB (or set dry_run: true)")
+	}
+	if c.ClobSignatureType < 0 || c.ClobSignatureType > 2 {
+		return fmt.Errorf("clob_signature_type must be 0 (EOA), 1 (proxy) or 2 (Gnosis Safe), matching how wallet_address signs")
+	}
+	if c.SizingMode != "proportional" && c.SizingMode != "fixed" {
+		return fmt.Errorf("sizing_mode must be 'proportional' or 'fixed', got %q", c.SizingMode)
+	}
+	if c.SizingMode == "fixed" && c.FixedCopyAmount <= 0 {
+		return fmt.Errorf("fixed_copy_amount is required when sizing_mode is 'fixed', it's the USDC spent per copied buy")
+	}
+	if c.MaxVaultFraction < 0 || c.MaxVaultFraction > 1 {
+		return fmt.Errorf("max_vault_fraction must be between 0 and 1")
+	}
+	for market, bps := range c.MarketMaxPriceImpactBps {
+		if bps < 0 {
+			return fmt.Errorf("market_max_price_impact_bps[%s] must be 0 or more", market)
+		}
+	}
+	if err := c.TradingSchedule.validate(); err != nil {
+		return err
 	}
 
 	// // Validate proxy settings if enabled
@@ -91,4 +609,46 @@ func (c *Config) Validate() error {
 	// }
 
 	return nil
-}
\ No newline at end of file
+}
+
+func (s TradingSchedule) validate() error {
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("trading_schedule.timezone: %w", err)
+	}
+	for _, w := range append(append([]TimeWindow{}, s.AllowedWindows...), s.BlackoutWindows...) {
+		if _, err := time.Parse("15:04", w.Start); err != nil {
+			return fmt.Errorf("trading_schedule window start %q must be HH:MM", w.Start)
+		}
+		if _, err := time.Parse("15:04", w.End); err != nil {
+			return fmt.Errorf("trading_schedule window end %q must be HH:MM", w.End)
+		}
+	}
+	for _, d := range s.BlackoutDates {
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return fmt.Errorf("trading_schedule blackout date %q must be YYYY-MM-DD", d)
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateStrategies() error {
+	seen := make(map[string]bool)
+	for i, sc := range c.Strategies {
+		if sc.ID == "" {
+			return fmt.Errorf("strategies[%d]: id is required", i)
+		}
+		for _, r := range sc.ID {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+				return fmt.Errorf("strategies[%d]: id %q may only contain a-z, 0-9, '-' and '_'", i, sc.ID)
+			}
+		}
+		if seen[sc.ID] {
+			return fmt.Errorf("strategies[%d]: duplicate id %q", i, sc.ID)
+		}
+		seen[sc.ID] = true
+		if sc.SizingMode != "" && sc.SizingMode != "proportional" && sc.SizingMode != "fixed" {
+			return fmt.Errorf("strategies[%d]: sizing_mode must be 'proportional' or 'fixed'", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+// internal/config/config_test.go
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfig writes yaml to a config file in a temporary directory
+func writeConfig(t *testing.T, yaml string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadSizingLimits(t *testing.T) {
+	tests := []struct {
+		name            string
+		yaml            string
+		env             string
+		wantFraction    float64
+		wantMinNotional float64
+	}{
+		{"absent keys take defaults", "", "", 0.2, 1.0},
+		{"explicit values", "max_vault_fraction: 0.5\nmin_trade_notional: 2", "", 0.5, 2},
+		{"explicit zero is honored", "max_vault_fraction: 0\nmin_trade_notional: 0", "", 0, 0},
+		{"zero from the environment", "", "0", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv(EnvName("max_vault_fraction"), tt.env)
+				t.Setenv(EnvName("min_trade_notional"), tt.env)
+			}
+			cfg, err := Load(writeConfig(t, tt.yaml))
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.MaxVaultFraction != tt.wantFraction || cfg.MinTradeNotional != tt.wantMinNotional {
+				t.Errorf("max_vault_fraction %v min_trade_notional %v, want %v and %v",
+					cfg.MaxVaultFraction, cfg.MinTradeNotional, tt.wantFraction, tt.wantMinNotional)
+			}
+		})
+	}
+}
+
+func TestLoadConfirmationBlocks(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    int
+		wantErr bool
+	}{
+		{"absent key takes the default", "", 5, false},
+		{"explicit depth", "confirmation_blocks: 12", 12, false},
+		{"explicit zero acts right away", "confirmation_blocks: 0", 0, false},
+		{"negative", "confirmation_blocks: -1", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(writeConfig(t, tt.yaml))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Load accepted %q", tt.yaml)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.ConfirmationBlocks != tt.want {
+				t.Errorf("ConfirmationBlocks = %d, want %d", cfg.ConfirmationBlocks, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadSignalLogSampling(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    int
+		wantErr bool
+	}{
+		{"absent key takes the default", "", 1000, false},
+		{"explicit rate", "signal_log_sampling: 50", 50, false},
+		{"explicit zero turns it off", "signal_log_sampling: 0", 0, false},
+		{"negative", "signal_log_sampling: -1", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(writeConfig(t, tt.yaml))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Load accepted %q", tt.yaml)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.SignalLogSampling != tt.want {
+				t.Errorf("SignalLogSampling = %d, want %d", cfg.SignalLogSampling, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := Load(writeConfig(t, "polygon_rpc_url: https://rpc.example\n"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if cfg.HTTPPort != "4000" || cfg.CopyTradeMultiplier != 0.1 || cfg.LeaderboardPollInterval != 10*time.Minute {
+		t.Errorf("defaults not applied: port %q multiplier %v poll %v", cfg.HTTPPort, cfg.CopyTradeMultiplier, cfg.LeaderboardPollInterval)
+	}
+	if cfg.StrategyID != "default" {
+		t.Errorf("StrategyID = %q, want default", cfg.StrategyID)
+	}
+	// The single RPC URL leads the failover list
+	if len(cfg.PolygonRPCURLs) != 1 || cfg.PolygonRPCURLs[0] != "https://rpc.example" {
+		t.Errorf("PolygonRPCURLs = %v", cfg.PolygonRPCURLs)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{"bad port", "http_port: \"0\"", "http_port"},
+		{"bad log level", "log_level: loud", "log_level"},
+		{"multiplier over 1", "copy_trade_multiplier: 2", "copy_trade_multiplier"},
+		{"negative profit threshold", "min_profit_threshold: -1", "min_profit_threshold"},
+		{"origin with path", "allowed_origins: [\"https://a.example/app\"]", "allowed_origins"},
+		{"poll too often", "leaderboard_poll_interval: 1s", "leaderboard_poll_interval"},
+		{"bad signal source", "signal_source: carrier-pigeon", "signal_source"},
+		{"executor without wallet", "executor_enabled: true\ndry_run: true", "wallet_address"},
+		{
+			"negative market impact",
+			"executor_enabled: true\ndry_run: true\nwallet_address: \"0xabc\"\nmarket_max_price_impact_bps:\n  \"0xdef\": -1",
+			"market_max_price_impact_bps[0xdef]",
+		},
+		{
+			"fixed sizing without amount",
+			"executor_enabled: true\ndry_run: true\nwallet_address: \"0xabc\"\nsizing_mode: fixed",
+			"fixed_copy_amount",
+		},
+		{"duplicate strategy", "strategies:\n  - id: a\n  - id: a", "duplicate id"},
+		{"strategy id with spaces", "strategies:\n  - id: \"a b\"", "may only contain"},
+		{"valid executor", "executor_enabled: true\ndry_run: true\nwallet_address: \"0xabc\"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.yaml))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Load: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	for key, want := range map[string]string{
+		"private_key":        "LAZYTRADER_PRIVATE_KEY",
+		"telegram_bot_token": "LAZYTRADER_TELEGRAM_BOT_TOKEN",
+		"polygon_rpc_url":    "LAZYTRADER_POLYGON_RPC_URL",
+	} {
+		if got := EnvName(key); got != want {
+			t.Errorf("EnvName(%s) = %s, want %s", key, got, want)
+		}
+	}
+}
+
+func TestLoadEnvOverrides(t *testing.T) {
+	t.Setenv(EnvName("http_port"), "8080")
+	t.Setenv(EnvName("dry_run"), "true")
+	t.Setenv(EnvName("max_price_impact_bps"), "250")
+	t.Setenv(EnvName("leaderboard_poll_interval"), "15m")
+	t.Setenv(EnvName("allowed_origins"), "https://a.example, https://b.example,")
+
+	cfg, err := Load(writeConfig(t, "http_port: \"9000\"\n"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if cfg.HTTPPort != "8080" {
+		t.Errorf("HTTPPort = %q, the environment should win over the YAML", cfg.HTTPPort)
+	}
+	if !cfg.DryRun || cfg.MaxPriceImpactBps != 250 || cfg.LeaderboardPollInterval != 15*time.Minute {
+		t.Errorf("overrides not applied: dry_run %v impact %v poll %v", cfg.DryRun, cfg.MaxPriceImpactBps, cfg.LeaderboardPollInterval)
+	}
+	if strings.Join(cfg.AllowedOrigins, " ") != "https://a.example https://b.example" {
+		t.Errorf("AllowedOrigins = %q", cfg.AllowedOrigins)
+	}
+}
+
+func TestLoadEnvErrors(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+	}{
+		{"dry_run", "maybe"},
+		{"leaderboard_poll_interval", "ten minutes"},
+		{"market_max_price_impact_bps", "0xabc=300"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			t.Setenv(EnvName(tt.key), tt.value)
+			_, err := Load(writeConfig(t, ""))
+			if err == nil || !strings.Contains(err.Error(), EnvName(tt.key)) {
+				t.Errorf("err = %v, want one naming %s", err, EnvName(tt.key))
+			}
+		})
+	}
+}
@@ -2,36 +2,63 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// ErrInvalidAddress is returned when an address is not a 20-byte hex string
+var ErrInvalidAddress = errors.New("invalid ethereum address")
+
+// DefaultStrategy scopes a single-strategy deployment
+const DefaultStrategy = "default"
+
+// DB is scoped to one strategy: users, positions, trades, tracked traders,
+// signals, leaderboard decisions and the listener checkpoint are all read
+// and written under its strategy_id. Handles from ForStrategy share the
+// underlying connection.
 type DB struct {
-	conn *sql.DB
+	conn       *sql.DB
+	strategyID string
+
+	// Held shared by WithTx transactions and exclusively by Vacuum.
+	// Standalone writes don't take it.
+	writers *sync.RWMutex
 }
 
 type User struct {
-	ID            int64
-	Address       string
-	DepositAmount float64
-	Shares        float64
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
+	ID            int64     `json:"id"`
+	Address       string    `json:"address"`
+	DepositAmount float64   `json:"deposit_amount"`
+	Shares        float64   `json:"shares"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 type Position struct {
-	ID            int64
-	MarketID      string
-	TokenID       string
-	Outcome       string
-	Amount        float64
-	AvgPrice      float64
-	CurrentPrice  float64
-	Status        string // "open", "closed"
-	CreatedAt     time.Time
-	ClosedAt      *time.Time
+	ID           int64
+	MarketID     string
+	TokenID      string
+	Outcome      string
+	Question     string // Market question, empty when it couldn't be resolved
+	Amount       float64
+	AvgPrice     float64
+	CurrentPrice float64
+	RealizedPnL  float64 // Locked in by sells against this position
+	SourceTrader string  // Tracked trader whose fills this position copies
+	SourceTxHash string  // Their fill that opened it
+	Status       string  // "open", "closed"
+	CreatedAt    time.Time
+	ClosedAt     *time.Time
 }
 
 type Trade struct {
@@ -42,12 +69,83 @@ type Trade struct {
 	Amount        float64
 	Price         float64
 	TxHash        string
-	Status        string // "pending", "confirmed", "failed"
+	Status        string // "pending", "confirmed", "failed", "dry_run"
+	MarketID      string // Market of the linked position, set by GetTrades
+	Question      string // Its market question, set by GetTrades
+	Outcome       string // Its outcome, set by GetTrades
 	CreatedAt     time.Time
 }
 
+// TradeFilter selects trades for GetTrades. Empty fields match everything.
+type TradeFilter struct {
+	TraderAddress string
+	Status        string
+	Side          string
+	Limit         int
+	Offset        int
+}
+
+// Signal is a detected top trader fill waiting to be copied. Amount and price
+// are raw on-chain integers (6 decimals) kept as strings to avoid overflow;
+// Price is empty when it could not be derived from the fill.
+type Signal struct {
+	ID          int64
+	Trader      string
+	Side        string // "BUY" or "SELL"
+	MarketID    string
+	TokenID     string
+	Amount      string
+	Price       string
+	TxHash      string
+	Exchange    string  // Exchange contract that emitted the fill
+	OrderHash   string  // Order the fill belongs to, empty when unknown
+	Fee         float64 // USDC, negative when paid by the trader
+	BlockNumber uint64
+	LogIndex    uint
+	Status      string // "unconfirmed", "pending", "processed", "skipped", "failed"
+	Reason      string // Why a signal was skipped
+	Attempts    int
+	DetectedAt  time.Time
+	ProcessedAt *time.Time
+}
+
+// TopTrader is a tracked trader with their latest leaderboard stats
+type TopTrader struct {
+	Address     string    `json:"address"`
+	UserName    string    `json:"user_name"`
+	Rank        int       `json:"rank"` // Leaderboard position when last seen, 0 if unknown
+	PnL         float64   `json:"pnl"`
+	Volume      float64   `json:"volume"`
+	WinRate     float64   `json:"win_rate"`
+	SeenCount   int       `json:"seen_count"` // Refreshes the trader has appeared in
+	Retained    bool      `json:"retained"`   // Off the leaderboard, kept while we hold their positions
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+// LeaderboardDecision records why a leaderboard entry was or wasn't tracked
+type LeaderboardDecision struct {
+	ID        int64
+	Address   string
+	PnL       float64
+	Volume    float64
+	Decision  string // "accepted" or "rejected"
+	Reason    string
+	CreatedAt time.Time
+}
+
+// connectionParams apply to every pooled connection. WAL lets readers run
+// alongside a writer, busy_timeout waits up to 5s for a held lock instead of
+// failing with "database is locked", and immediate transactions take the
+// write lock when they begin, so they wait on it rather than failing when a
+// read lock can't be upgraded.
+const connectionParams = "_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=on&_txlock=immediate"
+
 func New(dbPath string) (*DB, error) {
-	conn, err := sql.Open("sqlite3", dbPath)
+	sep := "?"
+	if strings.Contains(dbPath, "?") {
+		sep = "&"
+	}
+	conn, err := sql.Open("sqlite3", dbPath+sep+connectionParams)
 	if err != nil {
 		return nil, err
 	}
@@ -56,7 +154,7 @@ func New(dbPath string) (*DB, error) {
 		return nil, err
 	}
 
-	db := &DB{conn: conn}
+	db := &DB{conn: conn, strategyID: DefaultStrategy, writers: &sync.RWMutex{}}
 	if err := db.migrate(); err != nil {
 		return nil, err
 	}
@@ -64,72 +162,46 @@ func New(dbPath string) (*DB, error) {
 	return db, nil
 }
 
-func (db *DB) migrate() error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS users (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		address TEXT UNIQUE NOT NULL,
-		deposit_amount REAL NOT NULL DEFAULT 0,
-		shares REAL NOT NULL DEFAULT 0,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	CREATE TABLE IF NOT EXISTS positions (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		market_id TEXT NOT NULL,
-		token_id TEXT NOT NULL,
-		outcome TEXT NOT NULL,
-		amount REAL NOT NULL,
-		avg_price REAL NOT NULL,
-		current_price REAL NOT NULL,
-		status TEXT NOT NULL DEFAULT 'open',
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		closed_at DATETIME
-	);
-
-	CREATE TABLE IF NOT EXISTS trades (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		position_id INTEGER,
-		trader_address TEXT NOT NULL,
-		side TEXT NOT NULL,
-		amount REAL NOT NULL,
-		price REAL NOT NULL,
-		tx_hash TEXT,
-		status TEXT NOT NULL DEFAULT 'pending',
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (position_id) REFERENCES positions(id)
-	);
-
-	CREATE TABLE IF NOT EXISTS top_traders (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		address TEXT UNIQUE NOT NULL,
-		total_pnl REAL NOT NULL,
-		win_rate REAL NOT NULL,
-		last_updated DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_positions_status ON positions(status);
-	CREATE INDEX IF NOT EXISTS idx_trades_status ON trades(status);
-	CREATE INDEX IF NOT EXISTS idx_users_address ON users(address);
-	`
-
-	_, err := db.conn.Exec(schema)
-	return err
+// ForStrategy returns a handle on the same database scoped to strategyID
+func (db *DB) ForStrategy(strategyID string) *DB {
+	return &DB{conn: db.conn, strategyID: strategyID, writers: db.writers}
+}
+
+// StrategyID is the strategy this handle reads and writes
+func (db *DB) StrategyID() string {
+	return db.strategyID
 }
 
 func (db *DB) Close() error {
 	return db.conn.Close()
 }
 
+// normalizeAddress validates a hex address and returns it lowercased, so the
+// same wallet always maps to the same row regardless of checksum casing
+func normalizeAddress(address string) (string, error) {
+	address = strings.TrimSpace(address)
+	if !common.IsHexAddress(address) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidAddress, address)
+	}
+	return strings.ToLower(common.HexToAddress(address).Hex()), nil
+}
+
 // User operations
-func (db *DB) CreateUser(address string, depositAmount float64) (*User, error) {
-	// Simple share calculation: 1:1 for now
-	shares := depositAmount
-	
-	result, err := db.conn.Exec(
-		"INSERT INTO users (address, deposit_amount, shares) VALUES (?, ?, ?)",
-		address, depositAmount, shares,
+func (db *DB) CreateUser(ctx context.Context, address string, depositAmount float64) (*User, error) {
+	return createUser(ctx, db.conn, db.strategyID, address, depositAmount)
+}
+
+func createUser(ctx context.Context, q querier, strategyID, address string, depositAmount float64) (*User, error) {
+	address, err := normalizeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := sharesForDeposit(depositAmount)
+
+	result, err := q.ExecContext(ctx,
+		"INSERT INTO users (strategy_id, address, deposit_amount, shares) VALUES (?, ?, ?, ?)",
+		strategyID, address, depositAmount, shares,
 	)
 	if err != nil {
 		return nil, err
@@ -145,24 +217,109 @@ func (db *DB) CreateUser(address string, depositAmount float64) (*User, error) {
 	}, nil
 }
 
-func (db *DB) GetUser(address string) (*User, error) {
+func (db *DB) GetUser(ctx context.Context, address string) (*User, error) {
+	address, err := normalizeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
 	user := &User{}
-	err := db.conn.QueryRow(
-		"SELECT id, address, deposit_amount, shares, created_at, updated_at FROM users WHERE address = ?",
-		address,
+	err = db.conn.QueryRowContext(ctx,
+		"SELECT id, address, deposit_amount, shares, created_at, updated_at FROM users WHERE strategy_id = ? AND address = ?",
+		db.strategyID, address,
 	).Scan(&user.ID, &user.Address, &user.DepositAmount, &user.Shares, &user.CreatedAt, &user.UpdatedAt)
-	
+
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	return user, err
 }
 
+// GetAllUsers returns every user, newest first
+func (db *DB) GetAllUsers(ctx context.Context) ([]User, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		"SELECT id, address, deposit_amount, shares, created_at, updated_at FROM users WHERE strategy_id = ? ORDER BY created_at DESC, id DESC",
+		db.strategyID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		var u User
+		if err := rows.Scan(&u.ID, &u.Address, &u.DepositAmount, &u.Shares, &u.CreatedAt, &u.UpdatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// sharesForDeposit is how many vault shares a deposit mints. Simple share
+// calculation: 1:1 for now
+func sharesForDeposit(amount float64) float64 {
+	return amount
+}
+
+// Deposit credits a deposit to a user, creating them on their first one.
+// Repeat deposits add to deposit_amount and mint shares on top of those held.
+func (db *DB) Deposit(ctx context.Context, address string, amount float64) (*User, error) {
+	address, err := normalizeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *User
+	err = db.WithTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			`UPDATE users SET deposit_amount = deposit_amount + ?, shares = shares + ?, updated_at = CURRENT_TIMESTAMP
+			WHERE strategy_id = ? AND address = ?`,
+			amount, sharesForDeposit(amount), db.strategyID, address,
+		)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			if _, err := createUser(ctx, tx, db.strategyID, address, amount); err != nil {
+				return err
+			}
+		}
+
+		user = &User{}
+		return tx.QueryRowContext(ctx,
+			"SELECT id, address, deposit_amount, shares, created_at, updated_at FROM users WHERE strategy_id = ? AND address = ?",
+			db.strategyID, address,
+		).Scan(&user.ID, &user.Address, &user.DepositAmount, &user.Shares, &user.CreatedAt, &user.UpdatedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetTotalDeposited sums every user's deposits
+func (db *DB) GetTotalDeposited(ctx context.Context) (float64, error) {
+	var total float64
+	err := db.conn.QueryRowContext(ctx,
+		"SELECT COALESCE(SUM(deposit_amount), 0) FROM users WHERE strategy_id = ?",
+		db.strategyID,
+	).Scan(&total)
+	return total, err
+}
+
 // Position operations
-func (db *DB) CreatePosition(marketID, tokenID, outcome string, amount, price float64) (*Position, error) {
-	result, err := db.conn.Exec(
-		"INSERT INTO positions (market_id, token_id, outcome, amount, avg_price, current_price) VALUES (?, ?, ?, ?, ?, ?)",
-		marketID, tokenID, outcome, amount, price, price,
+func (db *DB) CreatePosition(ctx context.Context, marketID, tokenID, outcome string, amount, price float64) (*Position, error) {
+	return createPosition(ctx, db.conn, db.strategyID, Fill{
+		MarketID: marketID, TokenID: tokenID, Outcome: outcome, Side: "buy", Amount: amount, Price: price,
+	})
+}
+
+func createPosition(ctx context.Context, q querier, strategyID string, f Fill) (*Position, error) {
+	result, err := q.ExecContext(ctx,
+		"INSERT INTO positions (strategy_id, market_id, token_id, outcome, question, amount, avg_price, current_price, source_trader, source_tx_hash) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		strategyID, f.MarketID, f.TokenID, f.Outcome, f.Question, f.Amount, f.Price, f.Price, f.SourceTrader, f.SourceTxHash,
 	)
 	if err != nil {
 		return nil, err
@@ -171,21 +328,39 @@ func (db *DB) CreatePosition(marketID, tokenID, outcome string, amount, price fl
 	id, _ := result.LastInsertId()
 	return &Position{
 		ID:           id,
-		MarketID:     marketID,
-		TokenID:      tokenID,
-		Outcome:      outcome,
-		Amount:       amount,
-		AvgPrice:     price,
-		CurrentPrice: price,
+		MarketID:     f.MarketID,
+		TokenID:      f.TokenID,
+		Outcome:      f.Outcome,
+		Question:     f.Question,
+		Amount:       f.Amount,
+		AvgPrice:     f.Price,
+		CurrentPrice: f.Price,
+		SourceTrader: f.SourceTrader,
+		SourceTxHash: f.SourceTxHash,
 		Status:       "open",
 		CreatedAt:    time.Now(),
 	}, nil
 }
 
-func (db *DB) GetOpenPositions() ([]Position, error) {
-	rows, err := db.conn.Query(
-		"SELECT id, market_id, token_id, outcome, amount, avg_price, current_price, status, created_at FROM positions WHERE status = 'open'",
-	)
+const positionColumns = `id, market_id, token_id, outcome, question, amount, avg_price, current_price, realized_pnl,
+	source_trader, source_tx_hash, status, created_at, closed_at`
+
+func scanPosition(row interface{ Scan(...interface{}) error }) (*Position, error) {
+	var p Position
+	var closedAt sql.NullTime
+	err := row.Scan(&p.ID, &p.MarketID, &p.TokenID, &p.Outcome, &p.Question, &p.Amount, &p.AvgPrice, &p.CurrentPrice, &p.RealizedPnL,
+		&p.SourceTrader, &p.SourceTxHash, &p.Status, &p.CreatedAt, &closedAt)
+	if err != nil {
+		return nil, err
+	}
+	if closedAt.Valid {
+		p.ClosedAt = &closedAt.Time
+	}
+	return &p, nil
+}
+
+func (db *DB) queryPositions(ctx context.Context, query string, args ...interface{}) ([]Position, error) {
+	rows, err := db.conn.QueryContext(ctx, "SELECT "+positionColumns+" FROM positions "+query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -193,20 +368,270 @@ func (db *DB) GetOpenPositions() ([]Position, error) {
 
 	var positions []Position
 	for rows.Next() {
-		var p Position
-		if err := rows.Scan(&p.ID, &p.MarketID, &p.TokenID, &p.Outcome, &p.Amount, &p.AvgPrice, &p.CurrentPrice, &p.Status, &p.CreatedAt); err != nil {
+		p, err := scanPosition(rows)
+		if err != nil {
+			return nil, err
+		}
+		positions = append(positions, *p)
+	}
+	return positions, rows.Err()
+}
+
+// Fill is one executed copy trade applied to our book. SourceTrader and
+// SourceTxHash identify the tracked trader's fill it copied.
+type Fill struct {
+	MarketID     string
+	TokenID      string
+	Outcome      string
+	Question     string // Market question, recorded on the position it opens
+	Side         string // "buy" or "sell"
+	Amount       float64
+	Price        float64
+	SourceTrader string
+	SourceTxHash string
+}
+
+// ErrNoPosition is returned when selling a token with no open position
+var ErrNoPosition = errors.New("no open position")
+
+// dustAmount is the remaining size below which a position counts as closed
+const dustAmount = 1e-9
+
+// AddToPosition applies a fill to the open position copied from the same
+// source trader in the same token, so each position is attributable to one
+// trader. Buys average in at the fill price (VWAP). Sells reduce the amount
+// and realize (price - avg_price) * sold, leaving avg_price unchanged; a sell
+// larger than the position is capped at what's held and closes it. It also
+// returns the amount applied, the capped size for such a sell.
+func (db *DB) AddToPosition(ctx context.Context, f Fill) (*Position, float64, error) {
+	var position *Position
+	var applied float64
+	err := db.WithTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		position, applied, err = addToPosition(ctx, tx, db.strategyID, f)
+		return err
+	})
+	return position, applied, err
+}
+
+func addToPosition(ctx context.Context, q querier, strategyID string, f Fill) (*Position, float64, error) {
+	if f.SourceTrader != "" {
+		trader, err := normalizeAddress(f.SourceTrader)
+		if err != nil {
+			return nil, 0, err
+		}
+		f.SourceTrader = trader
+	}
+
+	p, err := openPosition(ctx, q, strategyID, f.TokenID, f.SourceTrader)
+	if err == sql.ErrNoRows {
+		if !strings.EqualFold(f.Side, "buy") {
+			return nil, 0, fmt.Errorf("%w in token %s", ErrNoPosition, f.TokenID)
+		}
+		p, err := createPosition(ctx, q, strategyID, f)
+		return p, f.Amount, err
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	applied := f.Amount
+	if strings.EqualFold(f.Side, "buy") {
+		total := p.Amount + f.Amount
+		p.AvgPrice = (p.AvgPrice*p.Amount + f.Price*f.Amount) / total
+		p.Amount = total
+	} else {
+		applied = math.Min(f.Amount, p.Amount)
+		p.RealizedPnL += (f.Price - p.AvgPrice) * applied
+		p.Amount -= applied
+	}
+	p.CurrentPrice = f.Price
+
+	if p.Amount < dustAmount {
+		p.Amount = 0
+		p.Status = "closed"
+		now := time.Now()
+		p.ClosedAt = &now
+		_, err = q.ExecContext(ctx,
+			"UPDATE positions SET amount = 0, current_price = ?, realized_pnl = ?, status = 'closed', closed_at = CURRENT_TIMESTAMP WHERE id = ?",
+			p.CurrentPrice, p.RealizedPnL, p.ID,
+		)
+	} else {
+		_, err = q.ExecContext(ctx,
+			"UPDATE positions SET amount = ?, avg_price = ?, current_price = ?, realized_pnl = ? WHERE id = ?",
+			p.Amount, p.AvgPrice, p.CurrentPrice, p.RealizedPnL, p.ID,
+		)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return p, applied, nil
+}
+
+// openPosition is the open position copied from trader in a token
+func openPosition(ctx context.Context, q querier, strategyID, tokenID, trader string) (*Position, error) {
+	return scanPosition(q.QueryRowContext(ctx,
+		"SELECT "+positionColumns+" FROM positions WHERE strategy_id = ? AND token_id = ? AND source_trader = ? AND status = 'open' ORDER BY id LIMIT 1",
+		strategyID, tokenID, trader,
+	))
+}
+
+// GetOpenPosition returns the open position copied from trader in a token,
+// the one AddToPosition would apply a fill to, or nil when there is none
+func (db *DB) GetOpenPosition(ctx context.Context, tokenID, trader string) (*Position, error) {
+	if trader != "" {
+		normalized, err := normalizeAddress(trader)
+		if err != nil {
 			return nil, err
 		}
-		positions = append(positions, p)
+		trader = normalized
+	}
+	p, err := openPosition(ctx, db.conn, db.strategyID, tokenID, trader)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return p, err
+}
+
+func (db *DB) GetOpenPositions(ctx context.Context) ([]Position, error) {
+	return db.queryPositions(ctx, "WHERE strategy_id = ? AND status = 'open'", db.strategyID)
+}
+
+// GetOpenPositionByToken returns the oldest open position in a token, from
+// any source trader, or nil when there is none
+func (db *DB) GetOpenPositionByToken(ctx context.Context, tokenID string) (*Position, error) {
+	p, err := scanPosition(db.conn.QueryRowContext(ctx,
+		"SELECT "+positionColumns+" FROM positions WHERE strategy_id = ? AND token_id = ? AND status = 'open' ORDER BY id LIMIT 1",
+		db.strategyID, tokenID,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return p, err
+}
+
+// ClosePosition exits whatever is left of an open position at exitPrice,
+// realizing (exitPrice - avg_price) on the remaining amount. Trades for the
+// exit are recorded by the caller.
+func (db *DB) ClosePosition(ctx context.Context, id int64, exitPrice float64) error {
+	result, err := db.conn.ExecContext(ctx, `
+		UPDATE positions SET
+			realized_pnl = realized_pnl + (? - avg_price) * amount,
+			amount = 0,
+			current_price = ?,
+			status = 'closed',
+			closed_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND strategy_id = ? AND status = 'open'
+	`, exitPrice, exitPrice, id, db.strategyID)
+	if err != nil {
+		return fmt.Errorf("failed to close position %d: %w", id, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w with id %d", ErrNoPosition, id)
 	}
-	return positions, nil
+	return nil
+}
+
+// GetClosedPositions returns fully exited positions, most recently closed first
+func (db *DB) GetClosedPositions(ctx context.Context) ([]Position, error) {
+	return db.queryPositions(ctx, "WHERE strategy_id = ? AND status = 'closed' ORDER BY closed_at DESC, id DESC", db.strategyID)
+}
+
+// UpdatePositionPrice marks a position to the latest market price
+func (db *DB) UpdatePositionPrice(ctx context.Context, positionID int64, price float64) error {
+	_, err := db.conn.ExecContext(ctx,
+		"UPDATE positions SET current_price = ? WHERE id = ? AND strategy_id = ?",
+		price, positionID, db.strategyID,
+	)
+	return err
+}
+
+// UpdatePositionAmount sets a position's share count, e.g. after
+// reconciling it against the wallet's on-chain holdings
+func (db *DB) UpdatePositionAmount(ctx context.Context, positionID int64, amount float64) error {
+	_, err := db.conn.ExecContext(ctx,
+		"UPDATE positions SET amount = ? WHERE id = ? AND strategy_id = ?",
+		amount, positionID, db.strategyID,
+	)
+	return err
+}
+
+// GetOpenPositionsInMarket returns open positions in any outcome of a market
+func (db *DB) GetOpenPositionsInMarket(ctx context.Context, marketID string) ([]Position, error) {
+	return db.queryPositions(ctx, "WHERE strategy_id = ? AND market_id = ? AND status = 'open'", db.strategyID, marketID)
+}
+
+// GetPositionsByTrader returns every position, open or closed, copied from
+// one tracked trader, newest first
+func (db *DB) GetPositionsByTrader(ctx context.Context, address string) ([]Position, error) {
+	address, err := normalizeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return db.queryPositions(ctx, "WHERE strategy_id = ? AND source_trader = ? ORDER BY id DESC", db.strategyID, address)
+}
+
+// GetPnLSummary splits PnL into realized, locked in by sells, and
+// unrealized, from open positions marked to current_price
+func (db *DB) GetPnLSummary(ctx context.Context) (realized, unrealized float64, err error) {
+	err = db.conn.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(realized_pnl), 0),
+			COALESCE(SUM(CASE WHEN status = 'open' THEN (current_price - avg_price) * amount END), 0)
+		FROM positions
+		WHERE strategy_id = ?
+	`, db.strategyID).Scan(&realized, &unrealized)
+	return realized, unrealized, err
+}
+
+// GetRealizedPnL sums the PnL locked in by sells, from closed positions and
+// the partial exits of open ones
+func (db *DB) GetRealizedPnL(ctx context.Context) (float64, error) {
+	var pnl float64
+	err := db.conn.QueryRowContext(ctx,
+		"SELECT COALESCE(SUM(realized_pnl), 0) FROM positions WHERE strategy_id = ?",
+		db.strategyID,
+	).Scan(&pnl)
+	return pnl, err
+}
+
+// CountOpenPositions returns how many positions are open
+func (db *DB) CountOpenPositions(ctx context.Context) (int, error) {
+	var count int
+	err := db.conn.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM positions WHERE strategy_id = ? AND status = 'open'",
+		db.strategyID,
+	).Scan(&count)
+	return count, err
+}
+
+// GetPositionsOlderThan returns open positions created more than d ago
+func (db *DB) GetPositionsOlderThan(ctx context.Context, d time.Duration) ([]Position, error) {
+	cutoff := time.Now().Add(-d).UTC().Format("2006-01-02 15:04:05")
+	return db.queryPositions(ctx, "WHERE strategy_id = ? AND status = 'open' AND created_at < ? ORDER BY created_at", db.strategyID, cutoff)
 }
 
 // Trade operations
-func (db *DB) CreateTrade(positionID int64, traderAddr, side string, amount, price float64) (*Trade, error) {
-	result, err := db.conn.Exec(
-		"INSERT INTO trades (position_id, trader_address, side, amount, price, status) VALUES (?, ?, ?, ?, ?, ?)",
-		positionID, traderAddr, side, amount, price, "pending",
+
+// CreateTrade records a trade as pending. positionID is 0 for a trade not
+// applied to a position yet, SettleTradeTx attaches it once it is.
+func (db *DB) CreateTrade(ctx context.Context, positionID int64, traderAddr, side string, amount, price float64) (*Trade, error) {
+	return createTrade(ctx, db.conn, db.strategyID, positionID, traderAddr, side, amount, price)
+}
+
+func createTrade(ctx context.Context, q querier, strategyID string, positionID int64, traderAddr, side string, amount, price float64) (*Trade, error) {
+	// Manual trades copy nobody and have no trader
+	if traderAddr != "" {
+		normalized, err := normalizeAddress(traderAddr)
+		if err != nil {
+			return nil, err
+		}
+		traderAddr = normalized
+	}
+
+	result, err := q.ExecContext(ctx,
+		"INSERT INTO trades (strategy_id, position_id, trader_address, side, amount, price, status) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		strategyID, sql.NullInt64{Int64: positionID, Valid: positionID != 0}, traderAddr, side, amount, price, "pending",
 	)
 	if err != nil {
 		return nil, err
@@ -225,8 +650,60 @@ func (db *DB) CreateTrade(positionID int64, traderAddr, side string, amount, pri
 	}, nil
 }
 
-func (db *DB) UpdateTradeStatus(tradeID int64, status, txHash string) error {
-	_, err := db.conn.Exec(
+func (db *DB) UpdateTradeStatus(ctx context.Context, tradeID int64, status, txHash string) error {
+	return updateTradeStatus(ctx, db.conn, tradeID, status, txHash)
+}
+
+// GetTrades returns trades matching the filter, newest first, with the
+// market of each trade's position
+func (db *DB) GetTrades(ctx context.Context, f TradeFilter) ([]Trade, error) {
+	query := `SELECT t.id, COALESCE(t.position_id, 0), t.trader_address, t.side, t.amount, t.price,
+			COALESCE(t.tx_hash, ''), t.status, COALESCE(p.market_id, ''), COALESCE(p.question, ''),
+			COALESCE(p.outcome, ''), t.created_at
+		FROM trades t
+		LEFT JOIN positions p ON p.id = t.position_id
+		WHERE t.strategy_id = ?`
+	args := []interface{}{db.strategyID}
+
+	if f.TraderAddress != "" {
+		normalized, err := normalizeAddress(f.TraderAddress)
+		if err != nil {
+			return nil, err
+		}
+		query += " AND t.trader_address = ?"
+		args = append(args, normalized)
+	}
+	if f.Status != "" {
+		query += " AND t.status = ?"
+		args = append(args, f.Status)
+	}
+	if f.Side != "" {
+		query += " AND LOWER(t.side) = LOWER(?)"
+		args = append(args, f.Side)
+	}
+	query += " ORDER BY t.created_at DESC, t.id DESC LIMIT ? OFFSET ?"
+	args = append(args, f.Limit, f.Offset)
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trades: %w", err)
+	}
+	defer rows.Close()
+
+	trades := []Trade{}
+	for rows.Next() {
+		var t Trade
+		if err := rows.Scan(&t.ID, &t.PositionID, &t.TraderAddress, &t.Side, &t.Amount, &t.Price,
+			&t.TxHash, &t.Status, &t.MarketID, &t.Question, &t.Outcome, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		trades = append(trades, t)
+	}
+	return trades, rows.Err()
+}
+
+func updateTradeStatus(ctx context.Context, q querier, tradeID int64, status, txHash string) error {
+	_, err := q.ExecContext(ctx,
 		"UPDATE trades SET status = ?, tx_hash = ? WHERE id = ?",
 		status, txHash, tradeID,
 	)
@@ -234,22 +711,176 @@ func (db *DB) UpdateTradeStatus(tradeID int64, status, txHash string) error {
 }
 
 // Top traders
-func (db *DB) UpsertTopTrader(address string, pnl, winRate float64) error {
-	_, err := db.conn.Exec(`
-		INSERT INTO top_traders (address, total_pnl, win_rate, last_updated)
-		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
-		ON CONFLICT(address) DO UPDATE SET
+// KeepWinRate as a TopTrader's WinRate leaves the stored win rate unchanged
+// on upsert (0 for a new trader)
+const KeepWinRate = -1
+
+func (db *DB) UpsertTopTrader(ctx context.Context, t TopTrader) error {
+	address, err := normalizeAddress(t.Address)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.conn.ExecContext(ctx, `
+		INSERT INTO top_traders (strategy_id, address, user_name, rank, total_pnl, volume, win_rate, last_updated)
+		VALUES (?, ?, ?, ?, ?, ?, MAX(?, 0), CURRENT_TIMESTAMP)
+		ON CONFLICT(strategy_id, address) DO UPDATE SET
+			user_name = excluded.user_name,
+			rank = excluded.rank,
 			total_pnl = excluded.total_pnl,
-			win_rate = excluded.win_rate,
+			volume = excluded.volume,
+			win_rate = CASE WHEN ? < 0 THEN win_rate ELSE excluded.win_rate END,
+			seen_count = seen_count + 1,
+			retained = 0,
 			last_updated = CURRENT_TIMESTAMP
-	`, address, pnl, winRate)
+	`, db.strategyID, address, t.UserName, t.Rank, t.PnL, t.Volume, t.WinRate, t.WinRate)
 	return err
 }
 
-func (db *DB) GetTopTraders(limit int) ([]string, error) {
-	rows, err := db.conn.Query(
-		"SELECT address FROM top_traders ORDER BY total_pnl DESC LIMIT ?",
-		limit,
+// UpdateTopTraderWinRate replaces a tracked trader's win rate without
+// counting as a leaderboard appearance
+func (db *DB) UpdateTopTraderWinRate(ctx context.Context, address string, winRate float64) error {
+	address, err := normalizeAddress(address)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.conn.ExecContext(ctx,
+		"UPDATE top_traders SET win_rate = ? WHERE strategy_id = ? AND address = ?",
+		winRate, db.strategyID, address,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("trader %s is not tracked", address)
+	}
+	return nil
+}
+
+// PruneStaleTopTraders stops tracking traders missing from the latest
+// leaderboard fetch (seen) whose row is older than staleAfter. Traders we
+// still hold an open copied position from are kept, flagged retained, so
+// their exits are still copied. It returns the addresses removed and retained.
+func (db *DB) PruneStaleTopTraders(ctx context.Context, seen []string, staleAfter time.Duration) (removed, retained []string, err error) {
+	current := make(map[string]bool, len(seen))
+	for _, address := range seen {
+		if normalized, err := normalizeAddress(address); err == nil {
+			current[normalized] = true
+		}
+	}
+	cutoff := time.Now().UTC().Add(-staleAfter).Format("2006-01-02 15:04:05")
+
+	err = db.WithTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx,
+			"SELECT address FROM top_traders WHERE strategy_id = ? AND last_updated < ?",
+			db.strategyID, cutoff,
+		)
+		if err != nil {
+			return err
+		}
+		var stale []string
+		for rows.Next() {
+			var address string
+			if err := rows.Scan(&address); err != nil {
+				rows.Close()
+				return err
+			}
+			if !current[strings.ToLower(address)] {
+				stale = append(stale, address)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, address := range stale {
+			var open int
+			if err := tx.QueryRowContext(ctx,
+				"SELECT COUNT(*) FROM positions WHERE strategy_id = ? AND source_trader = ? AND status = 'open'",
+				db.strategyID, strings.ToLower(address),
+			).Scan(&open); err != nil {
+				return err
+			}
+
+			if open > 0 {
+				if _, err := tx.ExecContext(ctx,
+					"UPDATE top_traders SET retained = 1 WHERE strategy_id = ? AND address = ?",
+					db.strategyID, address,
+				); err != nil {
+					return err
+				}
+				retained = append(retained, address)
+				continue
+			}
+
+			if _, err := tx.ExecContext(ctx,
+				"DELETE FROM top_traders WHERE strategy_id = ? AND address = ?",
+				db.strategyID, address,
+			); err != nil {
+				return err
+			}
+			removed = append(removed, address)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to prune stale traders: %w", err)
+	}
+	return removed, retained, nil
+}
+
+// GetLeaderboardUpdatedAt returns when the freshest tracked trader was last
+// refreshed, or nil if none are stored
+func (db *DB) GetLeaderboardUpdatedAt(ctx context.Context) (*time.Time, error) {
+	var updated time.Time
+	err := db.conn.QueryRowContext(ctx, "SELECT last_updated FROM top_traders WHERE strategy_id = ? ORDER BY last_updated DESC LIMIT 1", db.strategyID).Scan(&updated)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// GetTopTradersDetailed is GetTopTraders with every stored field
+func (db *DB) GetTopTradersDetailed(ctx context.Context, limit int) ([]TopTrader, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT address, user_name, rank, total_pnl, volume, win_rate, seen_count, retained, last_updated
+		FROM top_traders WHERE strategy_id = ? ORDER BY total_pnl DESC LIMIT ?`,
+		db.strategyID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	traders := []TopTrader{}
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var t TopTrader
+		if err := rows.Scan(&t.Address, &t.UserName, &t.Rank, &t.PnL, &t.Volume, &t.WinRate, &t.SeenCount, &t.Retained, &t.LastUpdated); err != nil {
+			return nil, err
+		}
+		// Rows written before normalization may still be checksummed
+		if normalized, err := normalizeAddress(t.Address); err == nil {
+			t.Address = normalized
+		}
+		if seen[t.Address] {
+			continue
+		}
+		seen[t.Address] = true
+		traders = append(traders, t)
+	}
+	return traders, rows.Err()
+}
+
+func (db *DB) GetTopTraders(ctx context.Context, limit int) ([]string, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		"SELECT address FROM top_traders WHERE strategy_id = ? ORDER BY total_pnl DESC LIMIT ?",
+		db.strategyID, limit,
 	)
 	if err != nil {
 		return nil, err
@@ -257,12 +888,492 @@ func (db *DB) GetTopTraders(limit int) ([]string, error) {
 	defer rows.Close()
 
 	var traders []string
+	seen := make(map[string]bool)
 	for rows.Next() {
 		var addr string
 		if err := rows.Scan(&addr); err != nil {
 			return nil, err
 		}
+		// Rows written before normalization may still be checksummed
+		if normalized, err := normalizeAddress(addr); err == nil {
+			addr = normalized
+		}
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
 		traders = append(traders, addr)
 	}
 	return traders, nil
-}
\ No newline at end of file
+}
+
+// ScoreWeights blend the components of a trader's score. Each component is
+// min-max normalized across the current set before weighting.
+type ScoreWeights struct {
+	PnL         float64
+	WinRate     float64
+	Consistency float64 // How many leaderboard refreshes the trader appeared in
+}
+
+type scoredTrader struct {
+	address string
+	pnl     float64
+	winRate float64
+	seen    float64
+	score   float64
+}
+
+// GetTopTradersByScore ranks traders by w.PnL*pnl + w.WinRate*winRate +
+// w.Consistency*consistency, with each component normalized to [0, 1]
+func (db *DB) GetTopTradersByScore(ctx context.Context, limit int, w ScoreWeights) ([]string, error) {
+	rows, err := db.conn.QueryContext(ctx, "SELECT address, total_pnl, win_rate, seen_count FROM top_traders WHERE strategy_id = ?", db.strategyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var traders []*scoredTrader
+	for rows.Next() {
+		t := &scoredTrader{}
+		if err := rows.Scan(&t.address, &t.pnl, &t.winRate, &t.seen); err != nil {
+			return nil, err
+		}
+		traders = append(traders, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	pnl := normalizer(traders, func(t *scoredTrader) float64 { return t.pnl })
+	winRate := normalizer(traders, func(t *scoredTrader) float64 { return t.winRate })
+	seen := normalizer(traders, func(t *scoredTrader) float64 { return t.seen })
+	for _, t := range traders {
+		t.score = w.PnL*pnl(t) + w.WinRate*winRate(t) + w.Consistency*seen(t)
+	}
+
+	sort.Slice(traders, func(i, j int) bool {
+		if traders[i].score != traders[j].score {
+			return traders[i].score > traders[j].score
+		}
+		if traders[i].pnl != traders[j].pnl {
+			return traders[i].pnl > traders[j].pnl
+		}
+		return traders[i].address < traders[j].address
+	})
+
+	var addresses []string
+	for _, t := range traders {
+		if len(addresses) == limit {
+			break
+		}
+		addresses = append(addresses, t.address)
+	}
+	return addresses, nil
+}
+
+// normalizer returns a func mapping a component onto [0, 1] across traders.
+// A component that's equal for everyone contributes nothing.
+func normalizer(traders []*scoredTrader, value func(*scoredTrader) float64) func(*scoredTrader) float64 {
+	if len(traders) == 0 {
+		return value
+	}
+	min, max := value(traders[0]), value(traders[0])
+	for _, t := range traders[1:] {
+		if v := value(t); v < min {
+			min = v
+		} else if v > max {
+			max = v
+		}
+	}
+	return func(t *scoredTrader) float64 {
+		if max == min {
+			return 0
+		}
+		return (value(t) - min) / (max - min)
+	}
+}
+
+// RecordLeaderboardDecision stores the outcome of filtering one leaderboard entry
+func (db *DB) RecordLeaderboardDecision(ctx context.Context, address string, pnl, volume float64, decision, reason string) error {
+	// Keep rejected invalid addresses as they came, they're what needs auditing
+	if normalized, err := normalizeAddress(address); err == nil {
+		address = normalized
+	}
+
+	_, err := db.conn.ExecContext(ctx,
+		"INSERT INTO leaderboard_decisions (strategy_id, address, pnl, volume, decision, reason) VALUES (?, ?, ?, ?, ?, ?)",
+		db.strategyID, address, pnl, volume, decision, reason,
+	)
+	return err
+}
+
+// GetLeaderboardDecisions returns the most recent decisions, newest first,
+// optionally only for one address
+func (db *DB) GetLeaderboardDecisions(ctx context.Context, address string, limit int) ([]LeaderboardDecision, error) {
+	query := "SELECT id, address, pnl, volume, decision, reason, created_at FROM leaderboard_decisions WHERE strategy_id = ?"
+	args := []interface{}{db.strategyID}
+	if address != "" {
+		normalized, err := normalizeAddress(address)
+		if err != nil {
+			return nil, err
+		}
+		query += " AND address = ?"
+		args = append(args, normalized)
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	decisions := []LeaderboardDecision{}
+	for rows.Next() {
+		var d LeaderboardDecision
+		if err := rows.Scan(&d.ID, &d.Address, &d.PnL, &d.Volume, &d.Decision, &d.Reason, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		decisions = append(decisions, d)
+	}
+	return decisions, rows.Err()
+}
+
+// Signals
+
+// CreateSignal stores a detected signal and reports whether it was newly
+// inserted. A signal for an already seen (tx_hash, log_index) is not inserted
+// again; the existing row is returned with inserted false, so callers can
+// fire side effects only once per fill.
+func (db *DB) CreateSignal(ctx context.Context, sig *Signal) (*Signal, bool, error) {
+	return createSignal(ctx, db.conn, db.strategyID, sig)
+}
+
+func createSignal(ctx context.Context, q querier, strategyID string, sig *Signal) (*Signal, bool, error) {
+	trader, err := normalizeAddress(sig.Trader)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Signals are pending unless stored as unconfirmed, awaiting ConfirmSignal
+	status := "pending"
+	if sig.Status == "unconfirmed" {
+		status = sig.Status
+	}
+
+	result, err := q.ExecContext(ctx, `
+		INSERT INTO signals (strategy_id, trader, side, market_id, token_id, amount, price, tx_hash, exchange, order_hash, fee, block_number, log_index, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(strategy_id, tx_hash, log_index) DO NOTHING
+	`, strategyID, trader, sig.Side, sig.MarketID, sig.TokenID, sig.Amount, sig.Price, sig.TxHash, sig.Exchange, sig.OrderHash, sig.Fee, sig.BlockNumber, sig.LogIndex, status)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		existing, err := getSignalByLog(ctx, q, strategyID, sig.TxHash, sig.LogIndex)
+		return existing, false, err
+	}
+
+	id, _ := result.LastInsertId()
+	created := *sig
+	created.ID = id
+	created.Trader = trader
+	created.Status = status
+	created.DetectedAt = time.Now()
+	return &created, true, nil
+}
+
+// CreateSignalOnce stores sig unless a signal for the same tx, trader,
+// token and side already exists, whatever its log index. Sources that can't
+// agree on log indexes (on-chain vs Data API) dedup against each other
+// through it.
+func (db *DB) CreateSignalOnce(ctx context.Context, sig *Signal) (*Signal, bool, error) {
+	var stored *Signal
+	var inserted bool
+	err := db.WithTx(ctx, func(tx *sql.Tx) error {
+		trader, err := normalizeAddress(sig.Trader)
+		if err != nil {
+			return err
+		}
+
+		existing, err := scanSignal(tx.QueryRowContext(ctx,
+			"SELECT "+signalColumns+" FROM signals WHERE strategy_id = ? AND tx_hash = ? AND trader = ? AND token_id = ? AND side = ? LIMIT 1",
+			db.strategyID, sig.TxHash, trader, sig.TokenID, sig.Side,
+		))
+		if err == nil {
+			stored = existing
+			return nil
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		stored, inserted, err = createSignal(ctx, tx, db.strategyID, sig)
+		return err
+	})
+	return stored, inserted, err
+}
+
+const signalColumns = `id, trader, side, market_id, token_id, amount, price, tx_hash, exchange, order_hash, fee, block_number, log_index,
+	status, reason, attempts, detected_at, processed_at`
+
+func scanSignal(row interface{ Scan(...interface{}) error }) (*Signal, error) {
+	var s Signal
+	var processedAt sql.NullTime
+	err := row.Scan(&s.ID, &s.Trader, &s.Side, &s.MarketID, &s.TokenID, &s.Amount, &s.Price, &s.TxHash, &s.Exchange,
+		&s.OrderHash, &s.Fee, &s.BlockNumber, &s.LogIndex, &s.Status, &s.Reason, &s.Attempts, &s.DetectedAt, &processedAt)
+	if err != nil {
+		return nil, err
+	}
+	if processedAt.Valid {
+		s.ProcessedAt = &processedAt.Time
+	}
+	return &s, nil
+}
+
+func getSignalByLog(ctx context.Context, q querier, strategyID, txHash string, logIndex uint) (*Signal, error) {
+	return scanSignal(q.QueryRowContext(ctx,
+		"SELECT "+signalColumns+" FROM signals WHERE strategy_id = ? AND tx_hash = ? AND log_index = ?",
+		strategyID, txHash, logIndex,
+	))
+}
+
+// GetUnprocessedSignals returns pending signals in chain order
+func (db *DB) GetUnprocessedSignals(ctx context.Context, limit int) ([]Signal, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		"SELECT "+signalColumns+" FROM signals WHERE strategy_id = ? AND status = 'pending' ORDER BY block_number, log_index LIMIT ?",
+		db.strategyID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var signals []Signal
+	for rows.Next() {
+		s, err := scanSignal(rows)
+		if err != nil {
+			return nil, err
+		}
+		signals = append(signals, *s)
+	}
+	return signals, rows.Err()
+}
+
+// GetUnconfirmedSignals returns signals awaiting confirmation from blocks up
+// to maxBlock, in chain order
+func (db *DB) GetUnconfirmedSignals(ctx context.Context, maxBlock uint64, limit int) ([]Signal, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		"SELECT "+signalColumns+" FROM signals WHERE strategy_id = ? AND status = 'unconfirmed' AND block_number <= ? ORDER BY block_number, log_index LIMIT ?",
+		db.strategyID, maxBlock, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var signals []Signal
+	for rows.Next() {
+		s, err := scanSignal(rows)
+		if err != nil {
+			return nil, err
+		}
+		signals = append(signals, *s)
+	}
+	return signals, rows.Err()
+}
+
+// ConfirmSignal makes an unconfirmed signal pending, for the executor to act on
+func (db *DB) ConfirmSignal(ctx context.Context, id int64) error {
+	result, err := db.conn.ExecContext(ctx, "UPDATE signals SET status = 'pending' WHERE id = ? AND status = 'unconfirmed'", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("signal %d not found or already confirmed", id)
+	}
+	return nil
+}
+
+// DiscardSignal skips an unconfirmed signal, such as one whose fill was
+// reorged out of the chain
+func (db *DB) DiscardSignal(ctx context.Context, id int64, reason string) error {
+	result, err := db.conn.ExecContext(ctx, `
+		UPDATE signals SET status = 'skipped', reason = ?, processed_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = 'unconfirmed'
+	`, reason, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("signal %d not found or already confirmed", id)
+	}
+	return nil
+}
+
+// GetSignalHistory returns the most recent signals of any status, oldest first
+func (db *DB) GetSignalHistory(ctx context.Context, limit int) ([]Signal, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		"SELECT "+signalColumns+" FROM (SELECT * FROM signals WHERE strategy_id = ? ORDER BY block_number DESC, log_index DESC LIMIT ?) ORDER BY block_number, log_index",
+		db.strategyID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var signals []Signal
+	for rows.Next() {
+		s, err := scanSignal(rows)
+		if err != nil {
+			return nil, err
+		}
+		signals = append(signals, *s)
+	}
+	return signals, rows.Err()
+}
+
+// HasBuySignalAfter reports whether trader has a buy signal in tokenID
+// stored after signal afterID and detected no later than until
+func (db *DB) HasBuySignalAfter(ctx context.Context, trader, tokenID string, afterID int64, until time.Time) (bool, error) {
+	trader, err := normalizeAddress(trader)
+	if err != nil {
+		return false, err
+	}
+
+	var exists bool
+	err = db.conn.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM signals
+			WHERE strategy_id = ? AND trader = ? AND token_id = ? AND side = 'BUY' AND id > ? AND detected_at <= ?
+		)
+	`, db.strategyID, trader, tokenID, afterID, until.UTC().Format("2006-01-02 15:04:05")).Scan(&exists)
+	return exists, err
+}
+
+func (db *DB) MarkSignalProcessed(ctx context.Context, id int64) error {
+	return finishSignal(ctx, db.conn, id, "processed", "")
+}
+
+func (db *DB) MarkSignalSkipped(ctx context.Context, id int64, reason string) error {
+	return finishSignal(ctx, db.conn, id, "skipped", reason)
+}
+
+// MarkSignalFailed dead-letters a signal that can't be executed
+func (db *DB) MarkSignalFailed(ctx context.Context, id int64, reason string) error {
+	return finishSignal(ctx, db.conn, id, "failed", reason)
+}
+
+// IncrementSignalAttempts records a failed attempt on a still pending signal
+// and returns the new attempt count
+func (db *DB) IncrementSignalAttempts(ctx context.Context, id int64) (int, error) {
+	var attempts int
+	err := db.conn.QueryRowContext(ctx,
+		"UPDATE signals SET attempts = attempts + 1 WHERE id = ? AND status = 'pending' RETURNING attempts",
+		id,
+	).Scan(&attempts)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("signal %d not found or already finished", id)
+	}
+	return attempts, err
+}
+
+func finishSignal(ctx context.Context, q querier, id int64, status, reason string) error {
+	result, err := q.ExecContext(ctx, `
+		UPDATE signals SET status = ?, reason = ?, attempts = attempts + 1, processed_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = 'pending'
+	`, status, reason, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("signal %d not found or already finished", id)
+	}
+	return nil
+}
+
+// Listener checkpoint
+
+// GetLastProcessedBlock returns the listener checkpoint, or 0 if none is stored
+func (db *DB) GetLastProcessedBlock(ctx context.Context) (uint64, error) {
+	var block uint64
+	err := db.conn.QueryRowContext(ctx, "SELECT last_processed_block FROM listener_state WHERE strategy_id = ?", db.strategyID).Scan(&block)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return block, err
+}
+
+// ListenerCheckpoint is the listener's stored progress
+type ListenerCheckpoint struct {
+	LastProcessedBlock uint64
+	UpdatedAt          time.Time
+}
+
+// GetListenerCheckpoint returns the checkpoint with the time it last moved,
+// or nil if the listener hasn't processed a block yet
+func (db *DB) GetListenerCheckpoint(ctx context.Context) (*ListenerCheckpoint, error) {
+	var cp ListenerCheckpoint
+	err := db.conn.QueryRowContext(ctx, "SELECT last_processed_block, updated_at FROM listener_state WHERE strategy_id = ?", db.strategyID).
+		Scan(&cp.LastProcessedBlock, &cp.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+// AddMissedBlocks widens the stored range of blocks the listener skipped and
+// must backfill. It's kept apart from the checkpoint, which moves past them,
+// so they survive a restart.
+func (db *DB) AddMissedBlocks(ctx context.Context, from, to uint64) error {
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO listener_state (strategy_id, last_processed_block, missed_from, missed_to)
+		VALUES (?, 0, ?, ?)
+		ON CONFLICT(strategy_id) DO UPDATE SET
+			missed_from = CASE WHEN missed_from = 0 THEN excluded.missed_from ELSE MIN(missed_from, excluded.missed_from) END,
+			missed_to = MAX(missed_to, excluded.missed_to)
+	`, db.strategyID, from, to)
+	return err
+}
+
+// GetMissedBlocks returns the stored range of blocks awaiting backfill, ok
+// false when there is none
+func (db *DB) GetMissedBlocks(ctx context.Context) (from, to uint64, ok bool, err error) {
+	err = db.conn.QueryRowContext(ctx, "SELECT missed_from, missed_to FROM listener_state WHERE strategy_id = ?", db.strategyID).
+		Scan(&from, &to)
+	if err == sql.ErrNoRows {
+		return 0, 0, false, nil
+	}
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return from, to, from != 0, nil
+}
+
+// ClearMissedBlocks forgets the stored missed range once from..to has been
+// backfilled. A range widened meanwhile is kept, its new blocks aren't done.
+func (db *DB) ClearMissedBlocks(ctx context.Context, from, to uint64) error {
+	_, err := db.conn.ExecContext(ctx,
+		"UPDATE listener_state SET missed_from = 0, missed_to = 0 WHERE strategy_id = ? AND missed_from >= ? AND missed_to <= ?",
+		db.strategyID, from, to,
+	)
+	return err
+}
+
+// SetLastProcessedBlock advances the listener checkpoint. It never moves
+// backwards, so a late backfill of an older block can't rewind it.
+func (db *DB) SetLastProcessedBlock(ctx context.Context, block uint64) error {
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO listener_state (strategy_id, last_processed_block, updated_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(strategy_id) DO UPDATE SET
+			last_processed_block = MAX(last_processed_block, excluded.last_processed_block),
+			updated_at = CURRENT_TIMESTAMP
+	`, db.strategyID, block)
+	return err
+}
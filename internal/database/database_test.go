@@ -0,0 +1,567 @@
+// internal/database/database_test.go
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testTrader   = "0x00000000000000000000000000000000000000Aa"
+	testTraderLC = "0x00000000000000000000000000000000000000aa"
+)
+
+// newTestDB opens a migrated database in a temporary directory
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		want    string
+		wantErr bool
+	}{
+		{"lowercase", testTraderLC, testTraderLC, false},
+		{"checksum casing", testTrader, testTraderLC, false},
+		{"uppercase hex", "0x00000000000000000000000000000000000000AA", testTraderLC, false},
+		{"surrounding whitespace", "  " + testTrader + "\n", testTraderLC, false},
+		{"no prefix", "00000000000000000000000000000000000000aa", testTraderLC, false},
+		{"too short", "0xaa", "", true},
+		{"not hex", "0x00000000000000000000000000000000000000zz", "", true},
+		{"empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeAddress(tt.address)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAddress) {
+					t.Errorf("normalizeAddress(%q) err = %v, want ErrInvalidAddress", tt.address, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("normalizeAddress(%q) = %q, %v, want %q", tt.address, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddressesNormalizedOnWrite(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	user, err := db.CreateUser(ctx, testTrader, 100)
+	if err != nil || user.Address != testTraderLC {
+		t.Fatalf("CreateUser = %+v, %v, want %s", user, err, testTraderLC)
+	}
+	if _, err := db.CreateUser(ctx, "0xnope", 1); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("CreateUser with a bad address err = %v, want ErrInvalidAddress", err)
+	}
+
+	trade, err := db.CreateTrade(ctx, 0, testTrader, "buy", 10, 0.5)
+	if err != nil || trade.TraderAddress != testTraderLC {
+		t.Fatalf("CreateTrade = %+v, %v, want %s", trade, err, testTraderLC)
+	}
+	if _, err := db.CreateTrade(ctx, 0, "", "buy", 1, 0.5); err != nil {
+		t.Errorf("CreateTrade without a trader: %v", err)
+	}
+	if _, err := db.CreateTrade(ctx, 0, "0xnope", "buy", 1, 0.5); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("CreateTrade with a bad address err = %v, want ErrInvalidAddress", err)
+	}
+
+	// Filtering matches whatever casing the caller uses
+	trades, err := db.GetTrades(ctx, TradeFilter{TraderAddress: "0x00000000000000000000000000000000000000AA", Limit: 10})
+	if err != nil || len(trades) != 1 || trades[0].ID != trade.ID {
+		t.Errorf("GetTrades by trader = %+v, %v, want trade %d", trades, err, trade.ID)
+	}
+	if _, err := db.GetTrades(ctx, TradeFilter{TraderAddress: "0xnope", Limit: 10}); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("GetTrades with a bad address err = %v, want ErrInvalidAddress", err)
+	}
+}
+
+// testSignal is a pending BUY from testTrader at the given log
+func testSignal(txHash string, block uint64, logIndex uint) *Signal {
+	return &Signal{
+		Trader: testTrader, Side: "BUY", MarketID: "m", TokenID: "t", Amount: "10000000", Price: "500000",
+		TxHash: txHash, BlockNumber: block, LogIndex: logIndex,
+	}
+}
+
+func TestCreateSignal(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	first, inserted, err := db.CreateSignal(ctx, testSignal("0xtx1", 10, 3))
+	if err != nil || !inserted {
+		t.Fatalf("CreateSignal = %v, inserted %v", err, inserted)
+	}
+	if first.Trader != testTraderLC || first.Status != "pending" {
+		t.Errorf("stored signal trader %s status %s, want %s pending", first.Trader, first.Status, testTraderLC)
+	}
+
+	// The same log again is not inserted, the stored row comes back
+	again, inserted, err := db.CreateSignal(ctx, testSignal("0xtx1", 10, 3))
+	if err != nil || inserted || again.ID != first.ID {
+		t.Errorf("duplicate CreateSignal = id %v, inserted %v, err %v, want id %d not inserted", again, inserted, err, first.ID)
+	}
+	// Another log in the same tx is a separate fill
+	if _, inserted, err := db.CreateSignal(ctx, testSignal("0xtx1", 10, 4)); err != nil || !inserted {
+		t.Errorf("CreateSignal at another log index = inserted %v, %v", inserted, err)
+	}
+	// Other strategies keep their own signals
+	if _, inserted, err := db.ForStrategy("other").CreateSignal(ctx, testSignal("0xtx1", 10, 3)); err != nil || !inserted {
+		t.Errorf("CreateSignal in another strategy = inserted %v, %v", inserted, err)
+	}
+	if _, _, err := db.CreateSignal(ctx, &Signal{Trader: "nope", TxHash: "0xtx2"}); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("CreateSignal with a bad trader err = %v, want ErrInvalidAddress", err)
+	}
+
+	pending, err := db.GetUnprocessedSignals(ctx, 10)
+	if err != nil || len(pending) != 2 {
+		t.Fatalf("GetUnprocessedSignals = %d signals, %v, want 2", len(pending), err)
+	}
+}
+
+func TestSignalTransitions(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	// Stored out of chain order, returned in it
+	var ids []int64
+	for _, log := range []struct {
+		block uint64
+		index uint
+	}{{12, 0}, {11, 5}, {11, 2}} {
+		sig, _, err := db.CreateSignal(ctx, testSignal(fmt.Sprintf("0x%d-%d", log.block, log.index), log.block, log.index))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, sig.ID)
+	}
+	pending, _ := db.GetUnprocessedSignals(ctx, 10)
+	if len(pending) != 3 || pending[0].ID != ids[2] || pending[1].ID != ids[1] || pending[2].ID != ids[0] {
+		t.Fatalf("pending signals out of chain order: %+v", pending)
+	}
+	if limited, _ := db.GetUnprocessedSignals(ctx, 1); len(limited) != 1 {
+		t.Errorf("GetUnprocessedSignals(1) returned %d", len(limited))
+	}
+
+	if err := db.MarkSignalProcessed(ctx, ids[0]); err != nil {
+		t.Fatalf("MarkSignalProcessed: %v", err)
+	}
+	if err := db.MarkSignalSkipped(ctx, ids[1], "skipped_paused"); err != nil {
+		t.Fatalf("MarkSignalSkipped: %v", err)
+	}
+	// Finished signals can't transition again
+	if err := db.MarkSignalSkipped(ctx, ids[0], "skipped_paused"); err == nil {
+		t.Error("MarkSignalSkipped on a processed signal succeeded")
+	}
+	if err := db.MarkSignalProcessed(ctx, ids[1]); err == nil {
+		t.Error("MarkSignalProcessed on a skipped signal succeeded")
+	}
+
+	pending, _ = db.GetUnprocessedSignals(ctx, 10)
+	if len(pending) != 1 || pending[0].ID != ids[2] {
+		t.Errorf("pending after transitions = %+v, want only %d", pending, ids[2])
+	}
+
+	history, err := db.GetSignalHistory(ctx, 10)
+	if err != nil || len(history) != 3 {
+		t.Fatalf("GetSignalHistory = %d, %v", len(history), err)
+	}
+	byID := make(map[int64]Signal)
+	for _, s := range history {
+		byID[s.ID] = s
+	}
+	if s := byID[ids[0]]; s.Status != "processed" || s.ProcessedAt == nil || s.Attempts != 1 {
+		t.Errorf("processed signal = %+v", s)
+	}
+	if s := byID[ids[1]]; s.Status != "skipped" || s.Reason != "skipped_paused" || s.ProcessedAt == nil {
+		t.Errorf("skipped signal = %+v", s)
+	}
+}
+
+func TestMissedBlocks(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	if _, _, ok, err := db.GetMissedBlocks(ctx); err != nil || ok {
+		t.Fatalf("GetMissedBlocks on empty db = ok %v, err %v", ok, err)
+	}
+
+	if err := db.AddMissedBlocks(ctx, 100, 110); err != nil {
+		t.Fatalf("AddMissedBlocks: %v", err)
+	}
+	if err := db.AddMissedBlocks(ctx, 90, 105); err != nil {
+		t.Fatalf("AddMissedBlocks: %v", err)
+	}
+	from, to, ok, err := db.GetMissedBlocks(ctx)
+	if err != nil || !ok || from != 90 || to != 110 {
+		t.Fatalf("GetMissedBlocks = %d-%d ok %v err %v, want 90-110", from, to, ok, err)
+	}
+
+	// A range widened after the backfill started is kept
+	if err := db.AddMissedBlocks(ctx, 111, 120); err != nil {
+		t.Fatalf("AddMissedBlocks: %v", err)
+	}
+	if err := db.ClearMissedBlocks(ctx, 90, 110); err != nil {
+		t.Fatalf("ClearMissedBlocks: %v", err)
+	}
+	if _, to, ok, _ := db.GetMissedBlocks(ctx); !ok || to != 120 {
+		t.Errorf("range after partial clear = ok %v to %d, want kept up to 120", ok, to)
+	}
+
+	if err := db.ClearMissedBlocks(ctx, 90, 120); err != nil {
+		t.Fatalf("ClearMissedBlocks: %v", err)
+	}
+	if _, _, ok, _ := db.GetMissedBlocks(ctx); ok {
+		t.Error("range still stored after clearing it")
+	}
+
+	// The checkpoint survives alongside the missed range
+	if err := db.SetLastProcessedBlock(ctx, 200); err != nil {
+		t.Fatalf("SetLastProcessedBlock: %v", err)
+	}
+	if block, err := db.GetLastProcessedBlock(ctx); err != nil || block != 200 {
+		t.Errorf("GetLastProcessedBlock = %d, %v, want 200", block, err)
+	}
+}
+
+func TestGetPositionsOlderThan(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	// Opened days ago, opened just now, and an old one already closed
+	ages := map[string]time.Duration{"old": 10 * 24 * time.Hour, "recent": 0, "closed": 10 * 24 * time.Hour}
+	ids := make(map[string]int64)
+	for _, token := range []string{"old", "recent", "closed"} {
+		p, err := db.CreatePosition(ctx, "m", token, "Yes", 10, 0.5)
+		if err != nil {
+			t.Fatalf("CreatePosition: %v", err)
+		}
+		ids[token] = p.ID
+		createdAt := time.Now().Add(-ages[token]).UTC().Format("2006-01-02 15:04:05")
+		if _, err := db.conn.ExecContext(ctx, "UPDATE positions SET created_at = ? WHERE id = ?", createdAt, p.ID); err != nil {
+			t.Fatalf("backdate position: %v", err)
+		}
+	}
+	if err := db.ClosePosition(ctx, ids["closed"], 0.6); err != nil {
+		t.Fatalf("ClosePosition: %v", err)
+	}
+	if err := db.UpdatePositionPrice(ctx, ids["old"], 0.7); err != nil {
+		t.Fatalf("UpdatePositionPrice: %v", err)
+	}
+
+	stale, err := db.GetPositionsOlderThan(ctx, 7*24*time.Hour)
+	if err != nil {
+		t.Fatalf("GetPositionsOlderThan: %v", err)
+	}
+	if len(stale) != 1 || stale[0].ID != ids["old"] {
+		t.Fatalf("stale positions = %+v, want only %d", stale, ids["old"])
+	}
+	if stale[0].CurrentPrice != 0.7 {
+		t.Errorf("current price = %v, want 0.7", stale[0].CurrentPrice)
+	}
+
+	// Everything open counts once the cutoff is short enough
+	if all, err := db.GetPositionsOlderThan(ctx, -time.Minute); err != nil || len(all) != 2 {
+		t.Errorf("GetPositionsOlderThan(-1m) = %d positions, %v, want 2", len(all), err)
+	}
+}
+
+func TestGetTopTradersByScore(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	// a bets big with a poor hit rate, c wins small but often
+	traders := []TopTrader{
+		{Address: "0x00000000000000000000000000000000000000a1", PnL: 90000, WinRate: 0.40},
+		{Address: "0x00000000000000000000000000000000000000a2", PnL: 40000, WinRate: 0.60},
+		{Address: "0x00000000000000000000000000000000000000a3", PnL: 10000, WinRate: 0.85},
+	}
+	for _, trader := range traders {
+		if err := db.UpsertTopTrader(ctx, trader); err != nil {
+			t.Fatalf("UpsertTopTrader: %v", err)
+		}
+	}
+	// a3 shows up on two more refreshes
+	for i := 0; i < 2; i++ {
+		if err := db.UpsertTopTrader(ctx, traders[2]); err != nil {
+			t.Fatalf("UpsertTopTrader: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		weights ScoreWeights
+		limit   int
+		want    []string
+	}{
+		{"pnl only", ScoreWeights{PnL: 1}, 3, []string{"a1", "a2", "a3"}},
+		{"pnl heavy", ScoreWeights{PnL: 0.8, WinRate: 0.2}, 3, []string{"a1", "a2", "a3"}},
+		{"win rate heavy", ScoreWeights{PnL: 0.2, WinRate: 0.8}, 3, []string{"a3", "a2", "a1"}},
+		{"consistency", ScoreWeights{Consistency: 1}, 1, []string{"a3"}},
+		{"limit", ScoreWeights{PnL: 1}, 2, []string{"a1", "a2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := db.GetTopTradersByScore(ctx, tt.limit, tt.weights)
+			if err != nil {
+				t.Fatalf("GetTopTradersByScore: %v", err)
+			}
+			want := make([]string, len(tt.want))
+			for i, suffix := range tt.want {
+				want[i] = "0x00000000000000000000000000000000000000" + suffix
+			}
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("ranking = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestGetPnLSummary(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	fill := func(token, side string, amount, price float64) {
+		t.Helper()
+		f := Fill{MarketID: "m", TokenID: token, Outcome: "Yes", Side: side, Amount: amount, Price: price, SourceTrader: testTrader}
+		if _, _, err := db.AddToPosition(ctx, f); err != nil {
+			t.Fatalf("AddToPosition: %v", err)
+		}
+	}
+
+	if realized, unrealized, err := db.GetPnLSummary(ctx); err != nil || realized != 0 || unrealized != 0 {
+		t.Fatalf("GetPnLSummary on empty db = %v, %v, %v", realized, unrealized, err)
+	}
+
+	// Open at 0.40 and marked to 0.60: 20 unrealized
+	fill("a", "buy", 100, 0.40)
+	a, _ := db.GetOpenPosition(ctx, "a", testTrader)
+	if err := db.UpdatePositionPrice(ctx, a.ID, 0.60); err != nil {
+		t.Fatalf("UpdatePositionPrice: %v", err)
+	}
+
+	// Partly sold at 0.60 for 4 realized, the rest marked to 0.45: -3 unrealized
+	fill("b", "buy", 100, 0.50)
+	fill("b", "sell", 40, 0.60)
+	b, _ := db.GetOpenPosition(ctx, "b", testTrader)
+	if err := db.UpdatePositionPrice(ctx, b.ID, 0.45); err != nil {
+		t.Fatalf("UpdatePositionPrice: %v", err)
+	}
+
+	// Closed at 0.20 for -3 realized, no longer marked
+	c, err := db.CreatePosition(ctx, "m", "c", "Yes", 10, 0.50)
+	if err != nil {
+		t.Fatalf("CreatePosition: %v", err)
+	}
+	if err := db.ClosePosition(ctx, c.ID, 0.20); err != nil {
+		t.Fatalf("ClosePosition: %v", err)
+	}
+
+	realized, unrealized, err := db.GetPnLSummary(ctx)
+	if err != nil {
+		t.Fatalf("GetPnLSummary: %v", err)
+	}
+	if math.Abs(realized-1) > 1e-9 {
+		t.Errorf("realized = %v, want 1", realized)
+	}
+	if math.Abs(unrealized-17) > 1e-9 {
+		t.Errorf("unrealized = %v, want 17", unrealized)
+	}
+
+	// The partial exit of b counts as much as the closed c
+	if pnl, err := db.GetRealizedPnL(ctx); err != nil || math.Abs(pnl-1) > 1e-9 {
+		t.Errorf("GetRealizedPnL = %v, %v, want 1", pnl, err)
+	}
+}
+
+func TestUsersScopedByStrategy(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	if _, err := db.CreateUser(ctx, testTrader, 100); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if _, err := db.CreateUser(ctx, testTraderLC, 50); err == nil {
+		t.Error("CreateUser accepted the same address in another casing")
+	}
+	if _, err := db.ForStrategy("other").CreateUser(ctx, testTrader, 50); err != nil {
+		t.Errorf("CreateUser in another strategy: %v", err)
+	}
+
+	user, err := db.GetUser(ctx, testTraderLC)
+	if err != nil || user == nil {
+		t.Fatalf("GetUser = %v, %v", user, err)
+	}
+	if user.Address != testTraderLC || user.DepositAmount != 100 {
+		t.Errorf("user = %+v, want %s with 100 deposited", user, testTraderLC)
+	}
+}
+
+func TestPositionsIsolatedByStrategy(t *testing.T) {
+	ctx := context.Background()
+	aggressive := newTestDB(t)
+	conservative := aggressive.ForStrategy("conservative")
+
+	// Both strategies copy the same trader into the same token
+	for _, db := range []*DB{aggressive, conservative} {
+		f := Fill{MarketID: "m", TokenID: "t", Outcome: "Yes", Side: "buy", Amount: 100, Price: 0.5, SourceTrader: testTrader}
+		p, _, err := db.AddToPosition(ctx, f)
+		if err != nil {
+			t.Fatalf("AddToPosition: %v", err)
+		}
+		if _, err := db.CreateTrade(ctx, p.ID, testTrader, "buy", 100, 0.5); err != nil {
+			t.Fatalf("CreateTrade: %v", err)
+		}
+	}
+	if err := aggressive.UpsertTopTrader(ctx, TopTrader{Address: testTrader, Rank: 1}); err != nil {
+		t.Fatalf("UpsertTopTrader: %v", err)
+	}
+
+	// A sell in one strategy leaves the other's position alone
+	sell := Fill{MarketID: "m", TokenID: "t", Outcome: "Yes", Side: "sell", Amount: 100, Price: 0.7, SourceTrader: testTrader}
+	if _, _, err := aggressive.AddToPosition(ctx, sell); err != nil {
+		t.Fatalf("AddToPosition sell: %v", err)
+	}
+
+	if open, err := aggressive.GetOpenPositions(ctx); err != nil || len(open) != 0 {
+		t.Errorf("aggressive open positions = %d, %v, want 0", len(open), err)
+	}
+	open, err := conservative.GetOpenPositions(ctx)
+	if err != nil || len(open) != 1 || open[0].Amount != 100 {
+		t.Fatalf("conservative open positions = %+v, %v, want one of 100", open, err)
+	}
+	if realized, _, _ := conservative.GetPnLSummary(ctx); realized != 0 {
+		t.Errorf("conservative realized = %v, want 0", realized)
+	}
+	if closed, _ := aggressive.GetClosedPositions(ctx); len(closed) != 1 {
+		t.Errorf("aggressive closed positions = %d, want 1", len(closed))
+	}
+
+	if trades, err := conservative.GetTrades(ctx, TradeFilter{Limit: 10}); err != nil || len(trades) != 1 {
+		t.Errorf("conservative trades = %d, %v, want 1", len(trades), err)
+	}
+	if traders, err := conservative.GetTopTraders(ctx, 10); err != nil || len(traders) != 0 {
+		t.Errorf("conservative tracked traders = %v, %v, want none", traders, err)
+	}
+	if traders, err := aggressive.GetTopTraders(ctx, 10); err != nil || len(traders) != 1 {
+		t.Errorf("aggressive tracked traders = %v, %v, want one", traders, err)
+	}
+}
+
+func TestAddToPosition(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	fill := func(side string, amount, price float64) Fill {
+		return Fill{MarketID: "m", TokenID: "t", Outcome: "Yes", Side: side, Amount: amount, Price: price, SourceTrader: testTrader}
+	}
+
+	if _, _, err := db.AddToPosition(ctx, fill("sell", 10, 0.5)); !errors.Is(err, ErrNoPosition) {
+		t.Fatalf("sell without a position err = %v, want ErrNoPosition", err)
+	}
+
+	if _, _, err := db.AddToPosition(ctx, fill("buy", 100, 0.40)); err != nil {
+		t.Fatalf("first buy: %v", err)
+	}
+	p, _, err := db.AddToPosition(ctx, fill("buy", 100, 0.60))
+	if err != nil {
+		t.Fatalf("second buy: %v", err)
+	}
+	if p.Amount != 200 || math.Abs(p.AvgPrice-0.5) > 1e-9 {
+		t.Errorf("after buys amount, avg = %v, %v, want 200, 0.5", p.Amount, p.AvgPrice)
+	}
+
+	p, applied, err := db.AddToPosition(ctx, fill("sell", 50, 0.70))
+	if err != nil {
+		t.Fatalf("sell: %v", err)
+	}
+	if applied != 50 || p.Amount != 150 || math.Abs(p.RealizedPnL-10) > 1e-9 {
+		t.Errorf("after sell applied, amount, pnl = %v, %v, %v, want 50, 150, 10", applied, p.Amount, p.RealizedPnL)
+	}
+
+	// Selling more than is held is capped and closes the position
+	p, applied, err = db.AddToPosition(ctx, fill("sell", 500, 0.50))
+	if err != nil {
+		t.Fatalf("oversized sell: %v", err)
+	}
+	if applied != 150 || p.Amount != 0 || p.Status != "closed" {
+		t.Errorf("after oversized sell applied, amount, status = %v, %v, %s, want 150, 0, closed", applied, p.Amount, p.Status)
+	}
+
+	open, err := db.GetOpenPosition(ctx, "t", testTraderLC)
+	if err != nil || open != nil {
+		t.Errorf("GetOpenPosition after close = %v, %v, want nil", open, err)
+	}
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	var mode string
+	if err := db.conn.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&mode); err != nil || mode != "wal" {
+		t.Fatalf("journal_mode = %q, %v, want wal", mode, err)
+	}
+
+	// Standalone writes, transactions and reads all at once, as the
+	// listener, executor and HTTP handlers do
+	const workers, writes = 8, 25
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*writes*3)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < writes; i++ {
+				sig := &Signal{Trader: testTrader, Side: "BUY", MarketID: "m", TokenID: "t",
+					Amount: "1000000", Price: "500000", TxHash: fmt.Sprintf("0x%02x%02x", w, i)}
+				if _, _, err := db.CreateSignal(ctx, sig); err != nil {
+					errs <- fmt.Errorf("CreateSignal: %w", err)
+				}
+				fill := Fill{MarketID: "m", TokenID: fmt.Sprint(w), Outcome: "Yes", Side: "buy", Amount: 1, Price: 0.5, SourceTrader: testTrader}
+				if _, _, err := db.AddToPosition(ctx, fill); err != nil {
+					errs <- fmt.Errorf("AddToPosition: %w", err)
+				}
+				if _, err := db.GetSignalHistory(ctx, 10); err != nil {
+					errs <- fmt.Errorf("GetSignalHistory: %w", err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	signals, err := db.GetSignalHistory(ctx, workers*writes+1)
+	if err != nil {
+		t.Fatalf("GetSignalHistory: %v", err)
+	}
+	if len(signals) != workers*writes {
+		t.Errorf("%d signals stored, want %d", len(signals), workers*writes)
+	}
+	for w := 0; w < workers; w++ {
+		position, err := db.GetOpenPosition(ctx, fmt.Sprint(w), testTrader)
+		if err != nil || position == nil || position.Amount != writes {
+			t.Errorf("position %d = %+v, %v, want %d shares", w, position, err, writes)
+		}
+	}
+}
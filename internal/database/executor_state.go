@@ -0,0 +1,32 @@
+// internal/database/executor_state.go
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// IsPaused reports whether copy trading was left paused
+func (db *DB) IsPaused(ctx context.Context) (bool, error) {
+	var paused bool
+	err := db.conn.QueryRowContext(ctx,
+		"SELECT paused FROM executor_state WHERE strategy_id = ?",
+		db.strategyID,
+	).Scan(&paused)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return paused, err
+}
+
+// SetPaused stores whether copy trading is paused, so it survives restarts
+func (db *DB) SetPaused(ctx context.Context, paused bool) error {
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO executor_state (strategy_id, paused, updated_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(strategy_id) DO UPDATE SET
+			paused = excluded.paused,
+			updated_at = CURRENT_TIMESTAMP
+	`, db.strategyID, paused)
+	return err
+}
@@ -0,0 +1,68 @@
+// internal/database/idempotency.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// IdempotentResponse is the stored outcome of a request made with an
+// Idempotency-Key. StatusCode is 0 while the original request is in flight.
+type IdempotentResponse struct {
+	Key         string
+	RequestHash string // Hash of the request body the key was first used with
+	StatusCode  int
+	Body        []byte
+	CreatedAt   time.Time
+}
+
+// ClaimIdempotencyKey reserves key for a new request. If the key was already
+// used within ttl it returns the stored response and false; an expired key
+// is reclaimed. Exactly one concurrent caller wins the claim.
+func (db *DB) ClaimIdempotencyKey(ctx context.Context, key, requestHash string, ttl time.Duration) (*IdempotentResponse, bool, error) {
+	var prior *IdempotentResponse
+	var claimed bool
+	err := db.WithTx(ctx, func(tx *sql.Tx) error {
+		cutoff := time.Now().UTC().Add(-ttl).Format("2006-01-02 15:04:05")
+		if _, err := tx.ExecContext(ctx,
+			"DELETE FROM idempotency_keys WHERE strategy_id = ? AND key = ? AND created_at < ?",
+			db.strategyID, key, cutoff,
+		); err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx,
+			"INSERT INTO idempotency_keys (strategy_id, key, request_hash) VALUES (?, ?, ?) ON CONFLICT DO NOTHING",
+			db.strategyID, key, requestHash,
+		)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 1 {
+			claimed = true
+			return nil
+		}
+
+		prior = &IdempotentResponse{Key: key}
+		return tx.QueryRowContext(ctx,
+			"SELECT request_hash, status_code, response, created_at FROM idempotency_keys WHERE strategy_id = ? AND key = ?",
+			db.strategyID, key,
+		).Scan(&prior.RequestHash, &prior.StatusCode, &prior.Body, &prior.CreatedAt)
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	return prior, claimed, nil
+}
+
+// CompleteIdempotencyKey stores the response for a claimed key so retries
+// replay it
+func (db *DB) CompleteIdempotencyKey(ctx context.Context, key string, statusCode int, body []byte) error {
+	_, err := db.conn.ExecContext(ctx,
+		"UPDATE idempotency_keys SET status_code = ?, response = ? WHERE strategy_id = ? AND key = ?",
+		statusCode, body, db.strategyID, key,
+	)
+	return err
+}
@@ -0,0 +1,88 @@
+// internal/database/maintenance.go
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ErrWritersActive is returned by Vacuum while a WithTx transaction is in
+// progress
+var ErrWritersActive = errors.New("database has active writers")
+
+// Optimize lets SQLite refresh query planner statistics where they're stale.
+// It's cheap enough to run often.
+func (db *DB) Optimize(ctx context.Context) error {
+	if _, err := db.conn.ExecContext(ctx, "PRAGMA optimize"); err != nil {
+		return fmt.Errorf("failed to optimize database: %w", err)
+	}
+	return nil
+}
+
+// Vacuum rebuilds the database file to reclaim space left by deletes. It
+// rewrites the whole file and can't run while a WithTx transaction is open,
+// so it refuses with ErrWritersActive rather than waiting on one, and
+// transactions started meanwhile wait for it to finish. Standalone writes
+// outside WithTx don't take the lock; SQLite serializes those against the
+// vacuum itself, holding them up to busy_timeout.
+func (db *DB) Vacuum(ctx context.Context) error {
+	if !db.writers.TryLock() {
+		return ErrWritersActive
+	}
+	defer db.writers.Unlock()
+
+	if _, err := db.conn.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	return nil
+}
+
+// SizeBytes is the size of the database file in bytes
+func (db *DB) SizeBytes(ctx context.Context) (int64, error) {
+	var pages, pageSize int64
+	if err := db.conn.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pages); err != nil {
+		return 0, err
+	}
+	if err := db.conn.QueryRowContext(ctx, "PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, err
+	}
+	return pages * pageSize, nil
+}
+
+// RunMaintenance optimizes every optimizeInterval and vacuums every
+// vacuumInterval until ctx is cancelled. A vacuum blocked by writers is
+// retried at the next optimize tick.
+func (db *DB) RunMaintenance(ctx context.Context, optimizeInterval, vacuumInterval time.Duration) {
+	ticker := time.NewTicker(optimizeInterval)
+	defer ticker.Stop()
+
+	lastVacuum := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := db.Optimize(ctx); err != nil {
+			slog.Error("database optimize failed", "err", err)
+		}
+
+		if time.Since(lastVacuum) < vacuumInterval {
+			continue
+		}
+		before, _ := db.SizeBytes(ctx)
+		start := time.Now()
+		if err := db.Vacuum(ctx); err != nil {
+			slog.Error("database vacuum failed", "err", err)
+			continue
+		}
+		lastVacuum = time.Now()
+		after, _ := db.SizeBytes(ctx)
+		slog.Info("vacuumed database", "duration", time.Since(start).Round(time.Millisecond),
+			"size_before", before, "size_after", after)
+	}
+}
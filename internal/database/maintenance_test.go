@@ -0,0 +1,66 @@
+// internal/database/maintenance_test.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestOptimizeAndVacuum(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	for i := 0; i < 50; i++ {
+		sig := &Signal{Trader: testTrader, Side: "BUY", MarketID: "m", TokenID: "t",
+			Amount: "1000000", Price: "500000", TxHash: fmt.Sprintf("0x%02x", i), BlockNumber: uint64(i)}
+		if _, _, err := db.CreateSignal(ctx, sig); err != nil {
+			t.Fatalf("CreateSignal: %v", err)
+		}
+	}
+
+	if err := db.Optimize(ctx); err != nil {
+		t.Fatalf("Optimize: %v", err)
+	}
+	if err := db.Vacuum(ctx); err != nil {
+		t.Fatalf("Vacuum: %v", err)
+	}
+	signals, err := db.GetSignalHistory(ctx, 100)
+	if err != nil {
+		t.Fatalf("GetSignalHistory: %v", err)
+	}
+	if len(signals) != 50 {
+		t.Errorf("%d signals after vacuum, want 50", len(signals))
+	}
+}
+
+func TestVacuumRefusesDuringTransaction(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	inTx := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- db.WithTx(ctx, func(tx *sql.Tx) error {
+			close(inTx)
+			<-release
+			return nil
+		})
+	}()
+	<-inTx
+
+	if err := db.Vacuum(ctx); !errors.Is(err, ErrWritersActive) {
+		t.Errorf("Vacuum during a transaction = %v, want ErrWritersActive", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+	if err := db.Vacuum(ctx); err != nil {
+		t.Errorf("Vacuum after the transaction = %v", err)
+	}
+}
@@ -0,0 +1,392 @@
+// internal/database/migrations.go
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// A migration is one versioned schema change, applied once in its own
+// transaction. Steps are either plain statements or, where the change
+// depends on what an older database already has, a function.
+type migration struct {
+	version     int
+	description string
+	statements  []string
+	apply       func(tx *sql.Tx) error
+}
+
+// migrations are applied in order on startup, skipping versions recorded in
+// schema_migrations. Never edit a released step; append a new one.
+var migrations = []migration{
+	{version: 1, description: "create tables", statements: tablesV1},
+	{version: 2, description: "add columns missing from databases created by earlier releases", apply: addMissingColumns},
+	{version: 3, description: "scope unique keys by strategy", apply: scopeUniqueKeys},
+	{version: 4, description: "create indexes", statements: append([]string{
+		// Both were first created without strategy_id
+		`DROP INDEX IF EXISTS idx_positions_status`,
+		`DROP INDEX IF EXISTS idx_signals_status`,
+	}, indexes...)},
+	{version: 5, description: "add positions.question", apply: addColumn("positions", "question", "TEXT NOT NULL DEFAULT ''")},
+	{version: 6, description: "add listener_state missed block range", apply: func(tx *sql.Tx) error {
+		if err := addColumn("listener_state", "missed_from", "INTEGER NOT NULL DEFAULT 0")(tx); err != nil {
+			return err
+		}
+		return addColumn("listener_state", "missed_to", "INTEGER NOT NULL DEFAULT 0")(tx)
+	}},
+	{version: 7, description: "lowercase trades.trader_address", statements: []string{
+		// Written as given before trade addresses were normalized
+		`UPDATE trades SET trader_address = LOWER(trader_address) WHERE trader_address != LOWER(trader_address)`,
+	}},
+}
+
+// tablesV1 is every table as first created by migration 1. Frozen like the
+// step itself: columns and tables added since live in their own steps.
+var tablesV1 = []string{
+	`CREATE TABLE IF NOT EXISTS users (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		strategy_id TEXT NOT NULL DEFAULT 'default',
+		address TEXT NOT NULL,
+		deposit_amount REAL NOT NULL DEFAULT 0,
+		shares REAL NOT NULL DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (strategy_id, address)
+	)`,
+	`CREATE TABLE IF NOT EXISTS positions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		strategy_id TEXT NOT NULL DEFAULT 'default',
+		market_id TEXT NOT NULL,
+		token_id TEXT NOT NULL,
+		outcome TEXT NOT NULL,
+		amount REAL NOT NULL,
+		avg_price REAL NOT NULL,
+		current_price REAL NOT NULL,
+		realized_pnl REAL NOT NULL DEFAULT 0,
+		source_trader TEXT NOT NULL DEFAULT '',
+		source_tx_hash TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'open',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		closed_at DATETIME
+	)`,
+	`CREATE TABLE IF NOT EXISTS trades (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		strategy_id TEXT NOT NULL DEFAULT 'default',
+		position_id INTEGER,
+		trader_address TEXT NOT NULL,
+		side TEXT NOT NULL,
+		amount REAL NOT NULL,
+		price REAL NOT NULL,
+		tx_hash TEXT,
+		status TEXT NOT NULL DEFAULT 'pending',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (position_id) REFERENCES positions(id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS top_traders (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		strategy_id TEXT NOT NULL DEFAULT 'default',
+		address TEXT NOT NULL,
+		user_name TEXT NOT NULL DEFAULT '',
+		rank INTEGER NOT NULL DEFAULT 0,
+		total_pnl REAL NOT NULL,
+		volume REAL NOT NULL DEFAULT 0,
+		win_rate REAL NOT NULL,
+		seen_count INTEGER NOT NULL DEFAULT 1,
+		retained INTEGER NOT NULL DEFAULT 0,
+		last_updated DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (strategy_id, address)
+	)`,
+	`CREATE TABLE IF NOT EXISTS signals (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		strategy_id TEXT NOT NULL DEFAULT 'default',
+		trader TEXT NOT NULL,
+		side TEXT NOT NULL,
+		market_id TEXT NOT NULL DEFAULT '',
+		token_id TEXT NOT NULL,
+		amount TEXT NOT NULL,
+		price TEXT NOT NULL DEFAULT '',
+		tx_hash TEXT NOT NULL,
+		exchange TEXT NOT NULL DEFAULT '',
+		order_hash TEXT NOT NULL DEFAULT '',
+		fee REAL NOT NULL DEFAULT 0,
+		block_number INTEGER NOT NULL,
+		log_index INTEGER NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		reason TEXT NOT NULL DEFAULT '',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		detected_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		processed_at DATETIME,
+		UNIQUE (strategy_id, tx_hash, log_index)
+	)`,
+	`CREATE TABLE IF NOT EXISTS leaderboard_decisions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		strategy_id TEXT NOT NULL DEFAULT 'default',
+		address TEXT NOT NULL,
+		pnl REAL NOT NULL,
+		volume REAL NOT NULL,
+		decision TEXT NOT NULL,
+		reason TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`CREATE TABLE IF NOT EXISTS listener_state (
+		strategy_id TEXT PRIMARY KEY,
+		last_processed_block INTEGER NOT NULL,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`CREATE TABLE IF NOT EXISTS executor_state (
+		strategy_id TEXT PRIMARY KEY,
+		paused INTEGER NOT NULL DEFAULT 0,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`CREATE TABLE IF NOT EXISTS trader_settings (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		strategy_id TEXT NOT NULL DEFAULT 'default',
+		address TEXT NOT NULL,
+		multiplier REAL NOT NULL,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (strategy_id, address)
+	)`,
+	`CREATE TABLE IF NOT EXISTS processed_logs (
+		strategy_id TEXT NOT NULL DEFAULT 'default',
+		tx_hash TEXT NOT NULL,
+		log_index INTEGER NOT NULL,
+		block_number INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (strategy_id, tx_hash, log_index)
+	)`,
+	`CREATE TABLE IF NOT EXISTS idempotency_keys (
+		strategy_id TEXT NOT NULL DEFAULT 'default',
+		key TEXT NOT NULL,
+		request_hash TEXT NOT NULL,
+		status_code INTEGER NOT NULL DEFAULT 0,
+		response BLOB,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (strategy_id, key)
+	)`,
+}
+
+var indexes = []string{
+	`CREATE INDEX IF NOT EXISTS idx_positions_status ON positions(strategy_id, status)`,
+	`CREATE INDEX IF NOT EXISTS idx_positions_source_trader ON positions(strategy_id, source_trader)`,
+	`CREATE INDEX IF NOT EXISTS idx_trades_status ON trades(status)`,
+	`CREATE INDEX IF NOT EXISTS idx_users_address ON users(address)`,
+	`CREATE INDEX IF NOT EXISTS idx_signals_status ON signals(strategy_id, status, block_number, log_index)`,
+	`CREATE INDEX IF NOT EXISTS idx_signals_trader ON signals(trader)`,
+	`CREATE INDEX IF NOT EXISTS idx_signals_token ON signals(token_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_leaderboard_decisions_address ON leaderboard_decisions(address)`,
+}
+
+// addedColumns were introduced after their table was first released. Every
+// definition has a default so existing rows can take it.
+var addedColumns = []struct{ table, column, definition string }{
+	{"users", "strategy_id", "TEXT NOT NULL DEFAULT 'default'"},
+	{"positions", "strategy_id", "TEXT NOT NULL DEFAULT 'default'"},
+	{"positions", "realized_pnl", "REAL NOT NULL DEFAULT 0"},
+	{"positions", "source_trader", "TEXT NOT NULL DEFAULT ''"},
+	{"positions", "source_tx_hash", "TEXT NOT NULL DEFAULT ''"},
+	{"trades", "strategy_id", "TEXT NOT NULL DEFAULT 'default'"},
+	{"top_traders", "strategy_id", "TEXT NOT NULL DEFAULT 'default'"},
+	{"top_traders", "user_name", "TEXT NOT NULL DEFAULT ''"},
+	{"top_traders", "rank", "INTEGER NOT NULL DEFAULT 0"},
+	{"top_traders", "volume", "REAL NOT NULL DEFAULT 0"},
+	{"top_traders", "seen_count", "INTEGER NOT NULL DEFAULT 1"},
+	{"top_traders", "retained", "INTEGER NOT NULL DEFAULT 0"},
+	{"signals", "strategy_id", "TEXT NOT NULL DEFAULT 'default'"},
+	{"signals", "exchange", "TEXT NOT NULL DEFAULT ''"},
+	{"signals", "order_hash", "TEXT NOT NULL DEFAULT ''"},
+	{"signals", "fee", "REAL NOT NULL DEFAULT 0"},
+	{"leaderboard_decisions", "strategy_id", "TEXT NOT NULL DEFAULT 'default'"},
+	{"listener_state", "strategy_id", "TEXT NOT NULL DEFAULT 'default'"},
+}
+
+func addMissingColumns(tx *sql.Tx) error {
+	for _, c := range addedColumns {
+		columns, err := tableColumns(tx, c.table)
+		if err != nil {
+			return err
+		}
+		if columns[c.column] {
+			continue
+		}
+		if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.column, c.definition)); err != nil {
+			return fmt.Errorf("failed to add %s.%s: %w", c.table, c.column, err)
+		}
+	}
+	return nil
+}
+
+// addColumn adds a column unless the table already has it
+func addColumn(table, column, definition string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		columns, err := tableColumns(tx, table)
+		if err != nil {
+			return err
+		}
+		if columns[column] {
+			return nil
+		}
+		if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+			return fmt.Errorf("failed to add %s.%s: %w", table, column, err)
+		}
+		return nil
+	}
+}
+
+// scopedKeys are the per-strategy keys that replaced global ones. SQLite
+// can't alter a constraint, so tables still on the old key are rebuilt.
+var scopedKeys = map[string]string{
+	"users":          "UNIQUE (strategy_id, address)",
+	"top_traders":    "UNIQUE (strategy_id, address)",
+	"signals":        "UNIQUE (strategy_id, tx_hash, log_index)",
+	"listener_state": "strategy_id TEXT PRIMARY KEY",
+}
+
+func scopeUniqueKeys(tx *sql.Tx) error {
+	for _, table := range []string{"users", "top_traders", "signals", "listener_state"} {
+		var current string
+		if err := tx.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&current); err != nil {
+			return fmt.Errorf("failed to read %s definition: %w", table, err)
+		}
+		if strings.Contains(current, scopedKeys[table]) {
+			continue
+		}
+		if err := rebuildTable(tx, table); err != nil {
+			return fmt.Errorf("failed to rebuild %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// rebuildTable recreates table from its migration 1 definition, the schema
+// migration 3 expects, and copies over the columns both versions share
+func rebuildTable(tx *sql.Tx, table string) error {
+	create := ""
+	prefix := "CREATE TABLE IF NOT EXISTS " + table + " ("
+	for _, stmt := range tablesV1 {
+		if strings.HasPrefix(stmt, prefix) {
+			create = strings.Replace(stmt, prefix, "CREATE TABLE "+table+"_new (", 1)
+		}
+	}
+	if create == "" {
+		return fmt.Errorf("no definition for table %s", table)
+	}
+
+	old, err := tableColumns(tx, table)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(create); err != nil {
+		return err
+	}
+	columns, err := tableColumns(tx, table+"_new")
+	if err != nil {
+		return err
+	}
+	var shared []string
+	for column := range columns {
+		if old[column] {
+			shared = append(shared, column)
+		}
+	}
+
+	list := strings.Join(shared, ", ")
+	for _, stmt := range []string{
+		fmt.Sprintf("INSERT INTO %s_new (%s) SELECT %s FROM %s", table, list, list, table),
+		fmt.Sprintf("DROP TABLE %s", table),
+		fmt.Sprintf("ALTER TABLE %s_new RENAME TO %s", table, table),
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func tableColumns(tx *sql.Tx, table string) (map[string]bool, error) {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, colType    string
+			dflt             sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pk); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
+
+// migrate applies every migration newer than the database's recorded
+// version, each in its own transaction
+func (db *DB) migrate() error {
+	if _, err := db.conn.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	var current int
+	if err := db.conn.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := db.applyMigration(m); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.description, err)
+		}
+	}
+	return nil
+}
+
+// applyMigration runs one migration and records it in a single transaction,
+// so a failing step rolls everything back instead of leaving a half
+// migrated database
+func (db *DB) applyMigration(m migration) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin migration: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i, stmt := range m.statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("statement %d (%s): %w", i+1, summarizeStatement(stmt), err)
+		}
+	}
+	if m.apply != nil {
+		if err := m.apply(tx); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(
+		"INSERT INTO schema_migrations (version, description) VALUES (?, ?)",
+		m.version, m.description,
+	); err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+	return tx.Commit()
+}
+
+// summarizeStatement returns the first line of a statement for error messages
+func summarizeStatement(stmt string) string {
+	stmt = strings.TrimSpace(stmt)
+	if i := strings.IndexByte(stmt, '\n'); i >= 0 {
+		stmt = stmt[:i]
+	}
+	return strings.TrimSuffix(strings.TrimSpace(stmt), "(")
+}
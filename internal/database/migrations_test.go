@@ -0,0 +1,208 @@
+// internal/database/migrations_test.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrationFailureRollsBack(t *testing.T) {
+	db := newTestDB(t)
+
+	// Appended after the real migrations, as a future release would
+	released := migrations
+	t.Cleanup(func() { migrations = released })
+	migrations = append(released[:len(released):len(released)], migration{
+		version:     len(released) + 1,
+		description: "broken step",
+		statements: []string{
+			`CREATE TABLE half_done (id INTEGER)`,
+			`INSERT INTO missing_table (id) VALUES (1)`,
+		},
+	})
+
+	err := db.migrate()
+	if err == nil {
+		t.Fatal("migrate succeeded with a bad statement")
+	}
+	for _, want := range []string{"broken step", "statement 2", "INSERT INTO missing_table", "no such table"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q doesn't mention %q", err, want)
+		}
+	}
+
+	// Nothing from the failed migration survives
+	var tables int
+	if err := db.conn.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'half_done'").Scan(&tables); err != nil {
+		t.Fatal(err)
+	}
+	if tables != 0 {
+		t.Error("table created by the failed migration was kept")
+	}
+	var version int
+	if err := db.conn.QueryRow("SELECT MAX(version) FROM schema_migrations").Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != len(released) {
+		t.Errorf("schema version = %d after the failed migration, want %d", version, len(released))
+	}
+
+	// Fixed, it applies on the next start
+	migrations[len(released)].statements = []string{`CREATE TABLE half_done (id INTEGER)`}
+	if err := db.migrate(); err != nil {
+		t.Fatalf("migrate after the fix: %v", err)
+	}
+}
+
+func schemaVersion(t *testing.T, db *DB) int {
+	t.Helper()
+	var version int
+	if err := db.conn.QueryRow("SELECT MAX(version) FROM schema_migrations").Scan(&version); err != nil {
+		t.Fatalf("read schema version: %v", err)
+	}
+	return version
+}
+
+func columns(t *testing.T, db *DB, table string) map[string]bool {
+	t.Helper()
+	tx, err := db.conn.Begin()
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer tx.Rollback()
+	cols, err := tableColumns(tx, table)
+	if err != nil {
+		t.Fatalf("table_info(%s): %v", table, err)
+	}
+	return cols
+}
+
+func TestMigrateFresh(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fresh.db")
+	db, err := New(path)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	latest := migrations[len(migrations)-1].version
+	if got := schemaVersion(t, db); got != latest {
+		t.Errorf("schema version = %d, want %d", got, latest)
+	}
+	// Columns added after migration 1 come from their own steps
+	for _, col := range []struct{ table, column string }{
+		{"listener_state", "missed_from"},
+		{"listener_state", "missed_to"},
+		{"listener_state", "strategy_id"},
+		{"positions", "question"},
+	} {
+		if !columns(t, db, col.table)[col.column] {
+			t.Errorf("%s missing %s", col.table, col.column)
+		}
+	}
+	db.Close()
+
+	// Reopening applies nothing new
+	db, err = New(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer db.Close()
+	var applied int
+	if err := db.conn.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&applied); err != nil {
+		t.Fatal(err)
+	}
+	if applied != len(migrations) {
+		t.Errorf("%d migrations recorded after reopen, want %d", applied, len(migrations))
+	}
+}
+
+// legacySchema is the schema of releases before versioned migrations
+const legacySchema = `
+	CREATE TABLE users (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		address TEXT UNIQUE NOT NULL,
+		deposit_amount REAL NOT NULL DEFAULT 0,
+		shares REAL NOT NULL DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE positions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		market_id TEXT NOT NULL,
+		token_id TEXT NOT NULL,
+		outcome TEXT NOT NULL,
+		amount REAL NOT NULL,
+		avg_price REAL NOT NULL,
+		current_price REAL NOT NULL,
+		status TEXT NOT NULL DEFAULT 'open',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		closed_at DATETIME
+	);
+	CREATE TABLE trades (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		position_id INTEGER,
+		trader_address TEXT NOT NULL,
+		side TEXT NOT NULL,
+		amount REAL NOT NULL,
+		price REAL NOT NULL,
+		tx_hash TEXT,
+		status TEXT NOT NULL DEFAULT 'pending',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (position_id) REFERENCES positions(id)
+	);
+	CREATE TABLE top_traders (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		address TEXT UNIQUE NOT NULL,
+		total_pnl REAL NOT NULL,
+		win_rate REAL NOT NULL,
+		last_updated DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX idx_positions_status ON positions(status);
+	CREATE INDEX idx_trades_status ON trades(status);
+	CREATE INDEX idx_users_address ON users(address);
+
+	INSERT INTO users (address, deposit_amount, shares) VALUES ('` + testTraderLC + `', 100, 100);
+	INSERT INTO positions (market_id, token_id, outcome, amount, avg_price, current_price) VALUES ('m', 't', 'Yes', 10, 0.5, 0.5);
+`
+
+func TestMigrateLegacy(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "legacy.db")
+
+	legacy, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := legacy.Exec(legacySchema); err != nil {
+		t.Fatalf("create legacy schema: %v", err)
+	}
+	legacy.Close()
+
+	db, err := New(path)
+	if err != nil {
+		t.Fatalf("New on legacy database: %v", err)
+	}
+	defer db.Close()
+
+	if got, want := schemaVersion(t, db), migrations[len(migrations)-1].version; got != want {
+		t.Errorf("schema version = %d, want %d", got, want)
+	}
+
+	// Existing rows land in the default strategy
+	user, err := db.GetUser(ctx, testTrader)
+	if err != nil || user == nil || user.DepositAmount != 100 {
+		t.Fatalf("legacy user = %+v, %v", user, err)
+	}
+	positions, err := db.GetOpenPositions(ctx)
+	if err != nil || len(positions) != 1 || positions[0].Question != "" {
+		t.Fatalf("legacy positions = %+v, %v", positions, err)
+	}
+
+	// The global unique address was rebuilt as a per-strategy key
+	if _, err := db.ForStrategy("other").CreateUser(ctx, testTrader, 1); err != nil {
+		t.Errorf("same address in another strategy: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+// internal/database/processed_logs.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// LogRef identifies an event log on chain
+type LogRef struct {
+	TxHash      string
+	LogIndex    uint
+	BlockNumber uint64
+}
+
+// IsLogProcessed reports whether a log already produced a signal
+func (db *DB) IsLogProcessed(ctx context.Context, txHash string, logIndex uint) (bool, error) {
+	var one int
+	err := db.conn.QueryRowContext(ctx,
+		"SELECT 1 FROM processed_logs WHERE strategy_id = ? AND tx_hash = ? AND log_index = ?",
+		db.strategyID, txHash, logIndex,
+	).Scan(&one)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// MarkLogsProcessed records logs whose signals are stored, all or none
+func (db *DB) MarkLogsProcessed(ctx context.Context, logs []LogRef) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	err := db.WithTx(ctx, func(tx *sql.Tx) error {
+		for _, ref := range logs {
+			if _, err := tx.ExecContext(ctx,
+				"INSERT INTO processed_logs (strategy_id, tx_hash, log_index, block_number) VALUES (?, ?, ?, ?) ON CONFLICT DO NOTHING",
+				db.strategyID, ref.TxHash, ref.LogIndex, ref.BlockNumber,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark logs processed: %w", err)
+	}
+	return nil
+}
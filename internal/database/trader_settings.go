@@ -0,0 +1,55 @@
+// internal/database/trader_settings.go
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// GetTraderMultiplier returns the copy multiplier set for a trader, or
+// fallback (the strategy's copy_trade_multiplier) when there's no override
+func (db *DB) GetTraderMultiplier(ctx context.Context, address string, fallback float64) (float64, error) {
+	address, err := normalizeAddress(address)
+	if err != nil {
+		return 0, err
+	}
+
+	var multiplier float64
+	err = db.conn.QueryRowContext(ctx,
+		"SELECT multiplier FROM trader_settings WHERE strategy_id = ? AND address = ?",
+		db.strategyID, address,
+	).Scan(&multiplier)
+	if err == sql.ErrNoRows {
+		return fallback, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return multiplier, nil
+}
+
+// SetTraderMultiplier overrides the copy multiplier for a trader. A
+// multiplier of 0 removes the override.
+func (db *DB) SetTraderMultiplier(ctx context.Context, address string, multiplier float64) error {
+	address, err := normalizeAddress(address)
+	if err != nil {
+		return err
+	}
+
+	if multiplier == 0 {
+		_, err = db.conn.ExecContext(ctx,
+			"DELETE FROM trader_settings WHERE strategy_id = ? AND address = ?",
+			db.strategyID, address,
+		)
+		return err
+	}
+
+	_, err = db.conn.ExecContext(ctx, `
+		INSERT INTO trader_settings (strategy_id, address, multiplier, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(strategy_id, address) DO UPDATE SET
+			multiplier = excluded.multiplier,
+			updated_at = CURRENT_TIMESTAMP
+	`, db.strategyID, address, multiplier)
+	return err
+}
@@ -0,0 +1,87 @@
+// internal/database/tx.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// querier is satisfied by both *sql.DB and *sql.Tx, so write methods can run
+// standalone or as part of a caller's transaction
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// WithTx runs fn in a transaction, committing if it returns nil. Any error
+// or panic from fn rolls back every write made through tx.
+func (db *DB) WithTx(ctx context.Context, fn func(tx *sql.Tx) error) (err error) {
+	db.writers.RLock()
+	defer db.writers.RUnlock()
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Transaction-scoped variants of the core write methods
+
+func (db *DB) CreateUserTx(ctx context.Context, tx *sql.Tx, address string, depositAmount float64) (*User, error) {
+	return createUser(ctx, tx, db.strategyID, address, depositAmount)
+}
+
+func (db *DB) CreatePositionTx(ctx context.Context, tx *sql.Tx, marketID, tokenID, outcome string, amount, price float64) (*Position, error) {
+	return createPosition(ctx, tx, db.strategyID, Fill{
+		MarketID: marketID, TokenID: tokenID, Outcome: outcome, Side: "buy", Amount: amount, Price: price,
+	})
+}
+
+func (db *DB) AddToPositionTx(ctx context.Context, tx *sql.Tx, f Fill) (*Position, float64, error) {
+	return addToPosition(ctx, tx, db.strategyID, f)
+}
+
+func (db *DB) CreateTradeTx(ctx context.Context, tx *sql.Tx, positionID int64, traderAddr, side string, amount, price float64) (*Trade, error) {
+	return createTrade(ctx, tx, db.strategyID, positionID, traderAddr, side, amount, price)
+}
+
+func (db *DB) UpdateTradeStatusTx(ctx context.Context, tx *sql.Tx, tradeID int64, status, txHash string) error {
+	return updateTradeStatus(ctx, tx, tradeID, status, txHash)
+}
+
+// SettleTradeTx attaches a trade to the position it was applied to and sets
+// its final status
+func (db *DB) SettleTradeTx(ctx context.Context, tx *sql.Tx, tradeID, positionID int64, status, txHash string) error {
+	_, err := tx.ExecContext(ctx,
+		"UPDATE trades SET position_id = ?, status = ?, tx_hash = ? WHERE id = ?",
+		positionID, status, txHash, tradeID,
+	)
+	return err
+}
+
+func (db *DB) CreateSignalTx(ctx context.Context, tx *sql.Tx, sig *Signal) (*Signal, bool, error) {
+	return createSignal(ctx, tx, db.strategyID, sig)
+}
+
+func (db *DB) MarkSignalProcessedTx(ctx context.Context, tx *sql.Tx, id int64) error {
+	return finishSignal(ctx, tx, id, "processed", "")
+}
+
+func (db *DB) MarkSignalSkippedTx(ctx context.Context, tx *sql.Tx, id int64, reason string) error {
+	return finishSignal(ctx, tx, id, "skipped", reason)
+}
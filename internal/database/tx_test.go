@@ -0,0 +1,112 @@
+// internal/database/tx_test.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+// writeAll creates a user, a position and a trade through tx
+func writeAll(ctx context.Context, db *DB, tx *sql.Tx) error {
+	if _, err := db.CreateUserTx(ctx, tx, testTrader, 100); err != nil {
+		return err
+	}
+	position, err := db.CreatePositionTx(ctx, tx, "m", "t", "Yes", 10, 0.5)
+	if err != nil {
+		return err
+	}
+	_, err = db.CreateTradeTx(ctx, tx, position.ID, testTrader, "buy", 10, 0.5)
+	return err
+}
+
+func TestWithTx(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name    string
+		fn      func(ctx context.Context, db *DB, tx *sql.Tx) error
+		wantErr error
+		panics  bool
+		kept    bool
+	}{
+		{
+			name: "commits",
+			fn:   writeAll,
+			kept: true,
+		},
+		{
+			name: "error rolls back",
+			fn: func(ctx context.Context, db *DB, tx *sql.Tx) error {
+				if err := writeAll(ctx, db, tx); err != nil {
+					return err
+				}
+				return errBoom
+			},
+			wantErr: errBoom,
+		},
+		{
+			name: "failing write rolls back the earlier ones",
+			fn: func(ctx context.Context, db *DB, tx *sql.Tx) error {
+				if err := writeAll(ctx, db, tx); err != nil {
+					return err
+				}
+				_, err := db.CreateTradeTx(ctx, tx, 0, "not-an-address", "buy", 1, 0.5)
+				return err
+			},
+			wantErr: ErrInvalidAddress,
+		},
+		{
+			name: "panic rolls back",
+			fn: func(ctx context.Context, db *DB, tx *sql.Tx) error {
+				if err := writeAll(ctx, db, tx); err != nil {
+					return err
+				}
+				panic("boom")
+			},
+			panics: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := newTestDB(t)
+
+			var err error
+			func() {
+				defer func() {
+					if p := recover(); (p != nil) != tt.panics {
+						t.Errorf("recovered %v, want panic %v", p, tt.panics)
+					}
+				}()
+				err = db.WithTx(ctx, func(tx *sql.Tx) error { return tt.fn(ctx, db, tx) })
+			}()
+			if !tt.panics && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WithTx = %v, want %v", err, tt.wantErr)
+			}
+
+			users, err := db.GetAllUsers(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			positions, err := db.GetOpenPositions(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			trades, err := db.GetTrades(ctx, TradeFilter{Limit: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := 0
+			if tt.kept {
+				want = 1
+			}
+			if len(users) != want || len(positions) != want || len(trades) != want {
+				t.Errorf("users, positions, trades = %d, %d, %d, want %d of each", len(users), len(positions), len(trades), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// internal/events/events.go
+package events
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Topic identifies a kind of event
+type Topic string
+
+const (
+	SignalDetected Topic = "signal.detected" // Payload: database.Signal
+	TradeExecuted  Topic = "trade.executed"  // Payload: TradeResult
+	TradeFailed    Topic = "trade.failed"    // Payload: TradeResult
+	PositionClosed Topic = "position.closed" // Payload: database.Position
+)
+
+// Event is a published message
+type Event struct {
+	Topic   Topic
+	Payload interface{}
+	Time    time.Time
+}
+
+// TradeResult describes an executed or failed copy trade
+type TradeResult struct {
+	MarketID string
+	TokenID  string
+	Question string // Market question, empty when the token wasn't resolved
+	Outcome  string
+	Side     string
+	Amount   float64
+	Price    float64
+	TxHash   string
+	Error    string
+	DryRun   bool // Recorded only, TxHash is simulated
+}
+
+type subscriber struct {
+	ch      chan Event
+	dropped atomic.Int64
+}
+
+// Bus is an in-process pub/sub bus. Publishing never blocks: a subscriber
+// whose buffer is full misses the event rather than stalling the publisher.
+// A nil *Bus is valid and discards everything.
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[Topic][]*subscriber
+}
+
+func New() *Bus {
+	return &Bus{subs: make(map[Topic][]*subscriber)}
+}
+
+// Subscribe returns a channel receiving events on topic, buffered to hold
+// buffer undelivered events, and a func that unsubscribes and closes it
+func (b *Bus) Subscribe(topic Topic, buffer int) (<-chan Event, func()) {
+	sub := &subscriber{ch: make(chan Event, buffer)}
+
+	b.mu.Lock()
+	b.subs[topic] = append(b.subs[topic], sub)
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			subs := b.subs[topic]
+			for i, s := range subs {
+				if s == sub {
+					b.subs[topic] = append(subs[:i:i], subs[i+1:]...)
+					break
+				}
+			}
+			close(sub.ch)
+		})
+	}
+	return sub.ch, unsubscribe
+}
+
+// Publish delivers payload to every subscriber of topic that has room
+func (b *Bus) Publish(topic Topic, payload interface{}) {
+	if b == nil {
+		return
+	}
+
+	event := Event{Topic: topic, Payload: payload, Time: time.Now()}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, sub := range b.subs[topic] {
+		select {
+		case sub.ch <- event:
+		default:
+			if dropped := sub.dropped.Add(1); dropped == 1 || dropped%100 == 0 {
+				slog.Warn("event bus subscriber too slow, dropping events", "topic", topic, "dropped", dropped)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// internal/events/events_test.go
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPublishReachesEverySubscriber(t *testing.T) {
+	b := New()
+	first, unsubFirst := b.Subscribe(SignalDetected, 1)
+	defer unsubFirst()
+	second, unsubSecond := b.Subscribe(SignalDetected, 1)
+	defer unsubSecond()
+	other, unsubOther := b.Subscribe(TradeExecuted, 1)
+	defer unsubOther()
+
+	b.Publish(SignalDetected, "payload")
+
+	for i, ch := range []<-chan Event{first, second} {
+		select {
+		case e := <-ch:
+			if e.Topic != SignalDetected || e.Payload != "payload" {
+				t.Errorf("subscriber %d got %+v", i+1, e)
+			}
+		default:
+			t.Errorf("subscriber %d got nothing", i+1)
+		}
+	}
+	select {
+	case e := <-other:
+		t.Errorf("subscriber to another topic got %+v", e)
+	default:
+	}
+}
+
+func TestSlowSubscriberDoesNotBlock(t *testing.T) {
+	b := New()
+	slow, unsubSlow := b.Subscribe(TradeExecuted, 1) // Never read
+	defer unsubSlow()
+	fast, unsubFast := b.Subscribe(TradeExecuted, 100)
+	defer unsubFast()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 50; i++ {
+			b.Publish(TradeExecuted, i)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a full subscriber")
+	}
+
+	if n := len(fast); n != 50 {
+		t.Errorf("fast subscriber got %d events, want 50", n)
+	}
+	if n := len(slow); n != 1 {
+		t.Errorf("slow subscriber buffered %d events, want 1", n)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	b := New()
+	ch, unsubscribe := b.Subscribe(PositionClosed, 1)
+	unsubscribe()
+	unsubscribe() // Safe to call twice
+
+	if _, ok := <-ch; ok {
+		t.Error("channel still open after unsubscribing")
+	}
+	b.Publish(PositionClosed, nil) // Must not send on the closed channel
+}
+
+func TestNilBus(t *testing.T) {
+	var b *Bus
+	b.Publish(SignalDetected, nil)
+}
@@ -0,0 +1,108 @@
+// internal/executor/balance.go
+package executor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/askwhyharsh/lazytrader/internal/listener"
+	"github.com/askwhyharsh/lazytrader/internal/units"
+)
+
+// ERC-20 balanceOf(address) selector
+var balanceOfSelector = crypto.Keccak256([]byte("balanceOf(address)"))[:4]
+
+// GetVaultBalance returns the wallet's raw 6-decimal USDC balance, read
+// with balanceOf on the Polygon USDC contract
+func (e *Executor) GetVaultBalance(ctx context.Context) (*big.Int, error) {
+	if e.client == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+
+	usdc := common.HexToAddress(listener.USDC_ADDR)
+	data := append(append([]byte{}, balanceOfSelector...), common.LeftPadBytes(common.HexToAddress(e.cfg.WalletAddress).Bytes(), 32)...)
+
+	result, err := e.client.CallContract(ctx, ethereum.CallMsg{To: &usdc, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read USDC balance: %w", err)
+	}
+	if len(result) != 32 {
+		return nil, fmt.Errorf("unexpected balanceOf result of %d bytes", len(result))
+	}
+
+	return new(big.Int).SetBytes(result), nil
+}
+
+// usdcBalance returns the wallet's USDC balance in human units
+func (e *Executor) usdcBalance(ctx context.Context) (float64, error) {
+	balance, err := e.GetVaultBalance(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return units.ToFloat(balance, units.Decimals), nil
+}
+
+// sizeToBalance fits a buy to the wallet's USDC balance: first the per-trade
+// vault cap, then the reserve. Sells spend no USDC and pass through.
+func (e *Executor) sizeToBalance(ctx context.Context, req TradeRequest) (TradeRequest, error) {
+	if req.Side != "buy" {
+		return req, nil
+	}
+
+	balance, err := e.usdcBalance(ctx)
+	if err != nil {
+		return req, fmt.Errorf("%w: %w", ErrTransient, err)
+	}
+
+	req, err = e.applyVaultCap(req, balance)
+	if err != nil {
+		return req, err
+	}
+	return e.applyReserve(req, balance)
+}
+
+// applyVaultCap keeps a single buy within MaxVaultFraction of the balance,
+// skipping it when the capped size is below the minimum order
+func (e *Executor) applyVaultCap(req TradeRequest, balance float64) (TradeRequest, error) {
+	limit := balance * e.cfg.MaxVaultFraction
+	notional := req.Amount * req.Price
+	if notional <= limit {
+		return req, nil
+	}
+	if limit < e.cfg.MinTradeNotional || limit <= 0 {
+		slog.Info("vault cap is below the minimum order, skipping buy", "token_id", req.TokenID,
+			"max_vault_fraction", e.cfg.MaxVaultFraction, "balance", balance, "min_notional", e.cfg.MinTradeNotional)
+		return req, &ErrSkip{Reason: "skipped_below_min_size"}
+	}
+
+	slog.Info("capped buy to vault fraction", "token_id", req.TokenID, "notional", notional,
+		"capped", limit, "max_vault_fraction", e.cfg.MaxVaultFraction, "balance", balance)
+	req.Amount = limit / req.Price
+	return req, nil
+}
+
+// applyReserve keeps ReserveBalance USDC untouched: a buy is trimmed to the
+// capital above the reserve, or skipped when too little is left
+func (e *Executor) applyReserve(req TradeRequest, balance float64) (TradeRequest, error) {
+	available := balance - e.cfg.ReserveBalance
+	notional := req.Amount * req.Price
+	if notional <= available {
+		return req, nil
+	}
+	if available < e.cfg.MinTradeNotional || available <= 0 {
+		slog.Info("balance too close to reserve, skipping buy", "token_id", req.TokenID,
+			"available", available, "reserve", e.cfg.ReserveBalance)
+		return req, &ErrSkip{Reason: "skipped_reserve"}
+	}
+
+	slog.Info("trimmed buy to keep reserve", "token_id", req.TokenID, "notional", notional,
+		"trimmed", available, "reserve", e.cfg.ReserveBalance)
+	req.Amount = available / req.Price
+	return req, nil
+}
@@ -0,0 +1,57 @@
+// internal/executor/balance_test.go
+package executor
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSizeToBalance(t *testing.T) {
+	// The RPC stub holds 1000 USDC; every request is at 0.5
+	tests := []struct {
+		name          string
+		side          string
+		amount        float64
+		reserve       float64
+		vaultFraction float64
+		minNotional   float64
+		wantAmount    float64
+		wantSkip      string
+	}{
+		{"fits above the reserve", "buy", 100, 20, 1, 1, 100, ""},
+		{"trimmed to the reserve", "buy", 200, 960, 1, 1, 80, ""}, // 40 USDC above the reserve
+		{"only the reserve remains", "buy", 200, 1000, 1, 1, 0, "skipped_reserve"},
+		{"too little above the reserve", "buy", 200, 999.5, 1, 1, 0, "skipped_reserve"},
+		{"no minimum trims to the last cent", "buy", 200, 999.5, 1, 0, 1, ""},
+		{"capped to the vault fraction", "buy", 200, 0, 0.02, 1, 40, ""}, // 20 USDC of 1000
+		{"zero vault fraction skips buys", "buy", 200, 0, 0, 0, 0, "skipped_below_min_size"},
+		{"sells ignore the reserve", "sell", 200, 1000, 1, 1, 200, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testExecutorConfig()
+			cfg.ReserveBalance = tt.reserve
+			cfg.MaxVaultFraction = tt.vaultFraction
+			cfg.MinTradeNotional = tt.minNotional
+			e, _ := newTestExecutor(t, cfg, &clobStub{})
+
+			req, err := e.sizeToBalance(context.Background(), TradeRequest{TokenID: "123", Side: tt.side, Amount: tt.amount, Price: 0.5})
+			if tt.wantSkip != "" {
+				var skip *ErrSkip
+				if !errors.As(err, &skip) || skip.Reason != tt.wantSkip {
+					t.Fatalf("sizeToBalance = %v, want %s", err, tt.wantSkip)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("sizeToBalance = %v, want nil", err)
+			}
+			if math.Abs(req.Amount-tt.wantAmount) > 1e-9 {
+				t.Errorf("amount = %v, want %v", req.Amount, tt.wantAmount)
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+// internal/executor/clob.go
+package executor
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"math/big"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	gethmath "github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/askwhyharsh/lazytrader/internal/units"
+)
+
+// CLOB order sides as signed in the EIP-712 struct
+const (
+	clobSideBuy  = 0
+	clobSideSell = 1
+)
+
+// EIP-712 types of a CTF exchange order. The NegRisk exchange uses the same
+// domain name and struct, only the verifying contract differs.
+var clobOrderTypes = apitypes.Types{
+	"EIP712Domain": {
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+	},
+	"Order": {
+		{Name: "salt", Type: "uint256"},
+		{Name: "maker", Type: "address"},
+		{Name: "signer", Type: "address"},
+		{Name: "taker", Type: "address"},
+		{Name: "tokenId", Type: "uint256"},
+		{Name: "makerAmount", Type: "uint256"},
+		{Name: "takerAmount", Type: "uint256"},
+		{Name: "expiration", Type: "uint256"},
+		{Name: "nonce", Type: "uint256"},
+		{Name: "feeRateBps", Type: "uint256"},
+		{Name: "side", Type: "uint8"},
+		{Name: "signatureType", Type: "uint8"},
+	},
+}
+
+// SignedOrder is an order in the form POST /order expects. Amounts are
+// base-unit integers encoded as strings.
+type SignedOrder struct {
+	Salt          int64  `json:"salt"`
+	Maker         string `json:"maker"`
+	Signer        string `json:"signer"`
+	Taker         string `json:"taker"`
+	TokenID       string `json:"tokenId"`
+	MakerAmount   string `json:"makerAmount"`
+	TakerAmount   string `json:"takerAmount"`
+	Expiration    string `json:"expiration"`
+	Nonce         string `json:"nonce"`
+	FeeRateBps    string `json:"feeRateBps"`
+	Side          string `json:"side"` // "BUY" or "SELL"
+	SignatureType int    `json:"signatureType"`
+	Signature     string `json:"signature"`
+
+	// EIP-712 hash of the order, which the CLOB uses as its order ID
+	Hash string `json:"-"`
+}
+
+type clobOrderRequest struct {
+	Order     SignedOrder `json:"order"`
+	Owner     string      `json:"owner"` // API key
+	OrderType string      `json:"orderType"`
+}
+
+type clobOrderResponse struct {
+	Success            bool     `json:"success"`
+	ErrorMsg           string   `json:"errorMsg"`
+	OrderID            string   `json:"orderID"`
+	TransactionsHashes []string `json:"transactionsHashes"`
+	Status             string   `json:"status"`
+}
+
+// orderAmounts converts a share count and price into the maker and taker
+// amounts: buyers give USDC for shares, sellers give shares for USDC. Sizes
+// are cut to 2 decimals and notionals to 4, the precision the CLOB accepts.
+func orderAmounts(order *Order) (maker, taker *big.Int, side int) {
+	size := math.Floor(order.Amount*100) / 100
+	notional := math.Round(size*order.Price*10000) / 10000
+
+	shares := units.FromFloat(size, units.Decimals)
+	usdc := units.FromFloat(notional, units.Decimals)
+	if order.Side == "sell" {
+		return shares, usdc, clobSideSell
+	}
+	return usdc, shares, clobSideBuy
+}
+
+// signOrder builds the EIP-712 limit order for order and signs it with the
+// executor key. The maker is the configured wallet, which for proxy and Safe
+// wallets differs from the signing key's address.
+func (e *Executor) signOrder(order *Order) (*SignedOrder, error) {
+	if e.privateKey == nil || e.chainID == nil {
+		return nil, fmt.Errorf("%w: signing key not loaded", ErrPermanent)
+	}
+	tokenID, ok := new(big.Int).SetString(order.TokenID, 10)
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid token ID %q", ErrPermanent, order.TokenID)
+	}
+
+	makerAmount, takerAmount, side := orderAmounts(order)
+	if makerAmount.Sign() <= 0 || takerAmount.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: order of %.4f @ %.4f rounds to nothing", ErrPermanent, order.Amount, order.Price)
+	}
+
+	maker := common.HexToAddress(e.cfg.WalletAddress)
+	signer := crypto.PubkeyToAddress(e.privateKey.PublicKey)
+	salt := rand.Int63n(time.Now().UnixMilli())
+	feeRateBps := strconv.FormatUint(uint64(e.cfg.ClobFeeBps), 10)
+
+	typedData := apitypes.TypedData{
+		Types:       clobOrderTypes,
+		PrimaryType: "Order",
+		Domain: apitypes.TypedDataDomain{
+			Name:              "Polymarket CTF Exchange",
+			Version:           "1",
+			ChainId:           (*gethmath.HexOrDecimal256)(e.chainID),
+			VerifyingContract: order.Exchange.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"salt":          strconv.FormatInt(salt, 10),
+			"maker":         maker.Hex(),
+			"signer":        signer.Hex(),
+			"taker":         common.Address{}.Hex(),
+			"tokenId":       tokenID.String(),
+			"makerAmount":   makerAmount.String(),
+			"takerAmount":   takerAmount.String(),
+			"expiration":    "0",
+			"nonce":         "0",
+			"feeRateBps":    feeRateBps,
+			"side":          strconv.Itoa(side),
+			"signatureType": strconv.Itoa(e.cfg.ClobSignatureType),
+		},
+	}
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to hash order: %w", ErrPermanent, err)
+	}
+	sig, err := crypto.Sign(hash, e.privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to sign order: %w", ErrPermanent, err)
+	}
+	sig[64] += 27 // Exchange contracts expect Ethereum-style v
+
+	sideName := "BUY"
+	if side == clobSideSell {
+		sideName = "SELL"
+	}
+	return &SignedOrder{
+		Salt:          salt,
+		Maker:         maker.Hex(),
+		Signer:        signer.Hex(),
+		Taker:         common.Address{}.Hex(),
+		TokenID:       tokenID.String(),
+		MakerAmount:   makerAmount.String(),
+		TakerAmount:   takerAmount.String(),
+		Expiration:    "0",
+		Nonce:         "0",
+		FeeRateBps:    feeRateBps,
+		Side:          sideName,
+		SignatureType: e.cfg.ClobSignatureType,
+		Signature:     "0x" + common.Bytes2Hex(sig),
+		Hash:          "0x" + common.Bytes2Hex(hash),
+	}, nil
+}
+
+// ErrOrderUnknown means an order may or may not have been placed: it was
+// sent, no usable response came back and the CLOB couldn't be asked about
+// it. Retrying could place it twice, so it's permanent and left to an
+// operator to check.
+var ErrOrderUnknown = fmt.Errorf("%w: order outcome unknown", ErrPermanent)
+
+// How often, and how far apart, the CLOB is asked about an order whose
+// response was lost
+const (
+	reconcileAttempts = 3
+	reconcileDelay    = 2 * time.Second
+)
+
+// postOrder submits a signed order as good-till-cancelled and returns the
+// settlement transaction hash when it matched, otherwise the CLOB order ID.
+// Rejections carry the CLOB's error body; 4xx responses are permanent. When
+// the order may have reached the CLOB without an answer coming back, it is
+// looked up by its hash rather than reported as failed.
+func (e *Executor) postOrder(ctx context.Context, order *SignedOrder) (string, error) {
+	body, err := json.Marshal(clobOrderRequest{Order: *order, Owner: e.cfg.ClobAPIKey, OrderType: "GTC"})
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to encode order: %w", ErrPermanent, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", CLOB_API+"/order", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrPermanent, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := e.signClobRequest(req, "/order", body); err != nil {
+		return "", err
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return e.reconcileOrder(ctx, order, fmt.Errorf("failed to post order: %w", err))
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return e.reconcileOrder(ctx, order, fmt.Errorf("failed to read order response: %w", err))
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("CLOB rejected order (status %d): %s", resp.StatusCode, bytes.TrimSpace(respBody))
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return "", fmt.Errorf("%w: %w", ErrPermanent, err)
+		}
+		return "", err
+	}
+
+	var result clobOrderResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return e.reconcileOrder(ctx, order, fmt.Errorf("failed to decode order response: %w", err))
+	}
+	if !result.Success {
+		return "", fmt.Errorf("%w: CLOB rejected order: %s", ErrPermanent, result.ErrorMsg)
+	}
+
+	if len(result.TransactionsHashes) > 0 {
+		return result.TransactionsHashes[0], nil
+	}
+	return result.OrderID, nil
+}
+
+// reconcileOrder finds out whether an order whose POST went unanswered was
+// placed. A placed order returns its ID. One the CLOB doesn't know was never
+// placed, so postErr is returned as is and the trade can be retried.
+// Otherwise it's ErrOrderUnknown.
+func (e *Executor) reconcileOrder(ctx context.Context, order *SignedOrder, postErr error) (string, error) {
+	slog.Warn("no response to order, checking whether it was placed", "order_id", order.Hash, "err", postErr)
+
+	var err error
+	for attempt := 1; attempt <= reconcileAttempts; attempt++ {
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("%w: order %s: %w", ErrOrderUnknown, order.Hash, ctx.Err())
+		case <-time.After(reconcileDelay):
+		}
+
+		var placed bool
+		placed, err = e.orderExists(ctx, order.Hash)
+		if err != nil {
+			slog.Warn("failed to look up order", "order_id", order.Hash, "attempt", attempt, "err", err)
+			continue
+		}
+		if placed {
+			slog.Info("order was placed despite the lost response", "order_id", order.Hash)
+			return order.Hash, nil
+		}
+		return "", postErr
+	}
+	return "", fmt.Errorf("%w: order %s, check the CLOB before retrying: %w (lookup: %w)",
+		ErrOrderUnknown, order.Hash, postErr, err)
+}
+
+// orderExists asks the CLOB whether it has an order with this ID
+func (e *Executor) orderExists(ctx context.Context, orderID string) (bool, error) {
+	path := "/data/order/" + orderID
+	req, err := http.NewRequestWithContext(ctx, "GET", CLOB_API+path, nil)
+	if err != nil {
+		return false, err
+	}
+	if err := e.signClobRequest(req, path, nil); err != nil {
+		return false, err
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("order lookup returned status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	// Unknown orders come back as an empty body or null
+	var found struct {
+		ID string `json:"id"`
+	}
+	if trimmed := bytes.TrimSpace(body); len(trimmed) == 0 || string(trimmed) == "null" {
+		return false, nil
+	}
+	if err := json.Unmarshal(body, &found); err != nil {
+		return false, fmt.Errorf("failed to decode order lookup: %w", err)
+	}
+	return found.ID != "", nil
+}
+
+// signClobRequest adds the CLOB L2 auth headers: an HMAC-SHA256, keyed with
+// the API secret, over timestamp + method + path + body
+func (e *Executor) signClobRequest(req *http.Request, path string, body []byte) error {
+	secret, err := base64.URLEncoding.DecodeString(e.cfg.ClobAPISecret)
+	if err != nil {
+		return fmt.Errorf("%w: invalid clob_api_secret", ErrPermanent)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + req.Method + path + string(body)))
+
+	req.Header.Set("POLY_ADDRESS", crypto.PubkeyToAddress(e.privateKey.PublicKey).Hex())
+	req.Header.Set("POLY_SIGNATURE", base64.URLEncoding.EncodeToString(mac.Sum(nil)))
+	req.Header.Set("POLY_TIMESTAMP", timestamp)
+	req.Header.Set("POLY_API_KEY", e.cfg.ClobAPIKey)
+	req.Header.Set("POLY_PASSPHRASE", e.cfg.ClobAPIPassphrase)
+	return nil
+}
@@ -0,0 +1,46 @@
+// internal/executor/errors.go
+package executor
+
+import "errors"
+
+var (
+	// ErrTransient marks failures worth retrying (RPC hiccups, locked DB)
+	ErrTransient = errors.New("transient error")
+
+	// ErrPermanent marks failures that will never succeed on retry (bad
+	// request, unusable key) and should be dead-lettered
+	ErrPermanent = errors.New("permanent error")
+)
+
+// ErrSkip means a trade was deliberately not executed. Reason is a stable
+// code (e.g. "skipped_unprofitable_fees") recorded on the signal.
+type ErrSkip struct {
+	Reason string
+}
+
+func (e *ErrSkip) Error() string {
+	return "trade skipped: " + e.Reason
+}
+
+// Error categories reported by Classify
+const (
+	CategorySkip      = "skip"
+	CategoryTransient = "transient"
+	CategoryPermanent = "permanent"
+)
+
+// Classify returns the category of an executor error. Errors that carry no
+// category are treated as transient so they get retried within the budget.
+func Classify(err error) string {
+	var skip *ErrSkip
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &skip):
+		return CategorySkip
+	case errors.Is(err, ErrPermanent):
+		return CategoryPermanent
+	default:
+		return CategoryTransient
+	}
+}
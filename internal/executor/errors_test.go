@@ -0,0 +1,32 @@
+// internal/executor/errors_test.go
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"skip", &ErrSkip{Reason: "skipped_paused"}, CategorySkip},
+		{"wrapped skip", fmt.Errorf("copy: %w", &ErrSkip{Reason: "skipped_price_impact"}), CategorySkip},
+		{"permanent", fmt.Errorf("%w: bad order", ErrPermanent), CategoryPermanent},
+		{"order unknown", ErrOrderUnknown, CategoryPermanent},
+		{"transient", fmt.Errorf("%w: rpc timeout", ErrTransient), CategoryTransient},
+		{"uncategorized", errors.New("connection reset"), CategoryTransient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.err); got != tt.want {
+				t.Errorf("Classify(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
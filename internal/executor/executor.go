@@ -4,143 +4,626 @@ package executor
 import (
 	"context"
 	"crypto/ecdsa"
+	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	// "github.com/ethereum/go-ethereum/common"
-	// "github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
-	
 	"github.com/askwhyharsh/lazytrader/internal/config"
 	"github.com/askwhyharsh/lazytrader/internal/database"
+	"github.com/askwhyharsh/lazytrader/internal/events"
+	"github.com/askwhyharsh/lazytrader/internal/listener"
+	"github.com/askwhyharsh/lazytrader/internal/metrics"
+	"github.com/askwhyharsh/lazytrader/internal/polygon"
+	"github.com/askwhyharsh/lazytrader/internal/polymarket"
+	"github.com/askwhyharsh/lazytrader/internal/strategy"
 )
 
 type Executor struct {
-	cfg         *config.Config
-	db          *database.DB
-	client      *ethclient.Client
-	privateKey  *ecdsa.PrivateKey
-	chainID     *big.Int
+	cfg        *config.Config
+	db         *database.DB
+	client     *polygon.Client
+	bus        *events.Bus
+	privateKey *ecdsa.PrivateKey
+	chainID    *big.Int
+	strategy   strategy.Strategy
+	httpClient *http.Client
+	prices     *PriceRefresher
+	tokens     *polymarket.TokenResolver
+	metrics    *metrics.Metrics
+	now        func() time.Time
+
+	// Bounds in-flight submitTrade calls; the rest wait their turn
+	submitSlots chan struct{}
+	queued      atomic.Int32
+
+	// Set while trading is paused on stale leaderboard data
+	leaderboardStale atomic.Bool
+
+	// Set by an operator to stop acting on signals, persisted across restarts
+	paused atomic.Bool
 }
 
+// Max signals picked up per poll
+const signalBatchSize = 50
+
 type TradeRequest struct {
-	MarketID  string
-	TokenID   string
-	Outcome   string
-	Side      string  // "buy" or "sell"
-	Amount    float64
-	Price     float64
+	MarketID string
+	TokenID  string
+	Outcome  string
+	Question string // Market question, resolved from TokenID when empty
+	Side     string // "buy" or "sell"
+	Amount   float64
+	Price    float64
+	Exchange string // Source exchange of the copied fill
+	NegRisk  bool   // Multi-outcome market, set from metadata when known
+
+	// The tracked trader's fill being copied, empty for manual trades
+	SourceTrader string
+	SourceTxHash string
+	SignalID     int64 // Marked processed along with the fill
+}
+
+// IsNegRisk reports whether the trade targets a negRisk (multi-outcome)
+// market, either from market metadata or the exchange the fill came from
+func (r TradeRequest) IsNegRisk() bool {
+	return r.NegRisk || listener.IsNegRiskExchange(r.Exchange)
 }
 
-func New(cfg *config.Config, db *database.DB) *Executor {
-	return &Executor{
-		cfg: cfg,
-		db:  db,
+// Order is a copy order ready to be signed and submitted
+type Order struct {
+	TokenID  string
+	Side     string
+	Amount   float64
+	Price    float64
+	NegRisk  bool
+	Exchange common.Address // Verifying contract the order is signed against
+}
+
+// buildOrder constructs the order for a trade. NegRisk markets settle through
+// the NegRisk exchange (which converts between outcome tokens), so their orders
+// must be signed against it rather than the plain CTF exchange.
+func buildOrder(req TradeRequest) *Order {
+	order := &Order{
+		TokenID:  req.TokenID,
+		Side:     req.Side,
+		Amount:   req.Amount,
+		Price:    req.Price,
+		Exchange: common.HexToAddress(listener.CTF_EXCHANGE_ADDR),
+	}
+	if req.IsNegRisk() {
+		order.NegRisk = true
+		order.Exchange = common.HexToAddress(listener.NEG_RISK_EXCHANGE_ADDR)
+	}
+	return order
+}
+
+func New(cfg *config.Config, db *database.DB, bus *events.Bus, tokens *polymarket.TokenResolver) *Executor {
+	e := &Executor{
+		cfg:         cfg,
+		db:          db,
+		bus:         bus,
+		strategy:    strategy.FromConfig(cfg),
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		prices:      NewPriceRefresher(cfg, db),
+		tokens:      tokens,
+		metrics:     metrics.For(cfg.StrategyID),
+		now:         time.Now,
+		submitSlots: make(chan struct{}, cfg.MaxConcurrentTrades),
+	}
+
+	// A bot paused before a crash or restart stays paused
+	paused, err := db.IsPaused(context.Background())
+	if err != nil {
+		slog.Error("failed to load paused state, assuming not paused", "err", err)
+	}
+	if paused {
+		slog.Warn("copy trading is paused, POST /resume to continue")
 	}
+	e.paused.Store(paused)
+	return e
 }
 
 func (e *Executor) Start(ctx context.Context) error {
-	log.Println("Starting execution engine...")
+	slog.Info("starting execution engine")
 
 	// Connect to Polygon RPC
-	client, err := ethclient.Dial(e.cfg.PolygonRPCURL)
+	client, err := polygon.Dial(ctx, e.cfg.PolygonRPCURLs)
 	if err != nil {
-		return fmt.Errorf("failed to connect to Polygon: %w", err)
+		return err
 	}
 	e.client = client
 
-	// // Load admin private key
-	// privateKey, err := crypto.HexToECDSA(e.cfg.AdminPrivateKey)
-	// if err != nil {
-	// 	return fmt.Errorf("invalid private key: %w", err)
-	// }
-	// e.privateKey = privateKey
-	// e.chainID = big.NewInt(e.cfg.ChainID)
+	if err := e.initSigner(ctx); err != nil {
+		return fmt.Errorf("executor not started: %w", err)
+	}
+
+	// Pick up trade signals stored by the listener
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	// Keep the vault value metric current, it needs an RPC call
+	vaultTicker := time.NewTicker(e.cfg.PriceRefreshInterval)
+	defer vaultTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			e.processSignals(ctx)
+		case <-vaultTicker.C:
+			if _, err := e.CalculateVaultValue(ctx); err != nil && ctx.Err() == nil {
+				slog.Error("failed to calculate vault value", "err", err)
+			}
+		}
+	}
+}
+
+// processSignals executes a batch of pending signals and waits for all of
+// them, so the next poll never picks up a signal still in flight. Signals for
+// one trader and token run one at a time in chain order, so a sell never
+// overtakes the buy it exits and one position is never updated twice at once.
+// Different traders and tokens run concurrently; submissions are bounded by
+// MaxConcurrentTrades.
+func (e *Executor) processSignals(ctx context.Context) {
+	signals, err := e.db.GetUnprocessedSignals(ctx, signalBatchSize)
+	if err != nil {
+		slog.Error("failed to get unprocessed signals", "err", err)
+		return
+	}
+
+	// Signals already started aren't cancelled on shutdown, so an order
+	// that reaches the CLOB is always recorded
+	work := context.WithoutCancel(ctx)
+
+	// Signals come back in chain order, which each lane keeps
+	lanes := make(map[string][]database.Signal)
+	var keys []string
+	for _, sig := range signals {
+		key := strings.ToLower(sig.Trader) + "|" + sig.TokenID
+		if _, ok := lanes[key]; !ok {
+			keys = append(keys, key)
+		}
+		lanes[key] = append(lanes[key], sig)
+	}
+
+	var wg sync.WaitGroup
+	for _, key := range keys {
+		wg.Add(1)
+		go func(lane []database.Signal) {
+			defer wg.Done()
+			for _, sig := range lane {
+				// A signal left pending holds back the ones after it
+				if ctx.Err() != nil || !e.processSignal(work, sig) {
+					return
+				}
+			}
+		}(lanes[key])
+	}
+	wg.Wait()
+}
+
+// processSignal acts on one signal. It returns false when the signal is left
+// pending, to be retried or waited on, rather than settled.
+func (e *Executor) processSignal(ctx context.Context, sig database.Signal) bool {
+	switch decision, err := e.confirmExit(ctx, sig); {
+	case err != nil:
+		slog.Warn("failed to confirm exit, will retry", "signal_id", sig.ID, "err", err)
+		return false
+	case decision == exitWait:
+		return false
+	case decision == exitCancel:
+		e.skipSignal(ctx, sig, "skipped_exit_reentered")
+		return true
+	}
+
+	req := tradeRequestFromSignal(sig)
+	ssig := strategy.FromSignal(sig)
+
+	// Sell fills carry no price and bad amounts give nonsense ones
+	price, ok := e.resolvePrice(ctx, req.TokenID, ssig.Price)
+	if !ok {
+		e.skipSignal(ctx, sig, "skipped_no_price")
+		return true
+	}
+	req.Price, ssig.Price = price, price
+
+	// Followed traders can be copied at their own multiplier
+	multiplier, err := e.db.GetTraderMultiplier(ctx, sig.Trader, e.cfg.CopyTradeMultiplier)
+	if err != nil {
+		slog.Warn("failed to get multiplier, will retry", "trader", sig.Trader, "err", err)
+		return false
+	}
+	ssig.Multiplier = multiplier
+
+	decision := e.strategy.Size(ssig)
+	if decision.Skip != "" {
+		e.skipSignal(ctx, sig, decision.Skip)
+		return true
+	}
+	req.Amount = decision.Amount
+
+	err = e.checkMarketFilter(ctx, req)
+	if err == nil {
+		err = e.ExecuteTrade(ctx, req)
+	}
+	switch Classify(err) {
+	case "":
+		// Already marked processed together with its trade
+	case CategorySkip:
+		var skip *ErrSkip
+		errors.As(err, &skip)
+		e.skipSignal(ctx, sig, skip.Reason)
+	case CategoryTransient:
+		attempts, dbErr := e.db.IncrementSignalAttempts(ctx, sig.ID)
+		if dbErr != nil {
+			slog.Error("failed to record signal attempt", "signal_id", sig.ID, "err", dbErr)
+			return false
+		}
+		if attempts < e.cfg.SignalMaxAttempts {
+			slog.Warn("signal failed, will retry", "signal_id", sig.ID, "attempt", attempts,
+				"max_attempts", e.cfg.SignalMaxAttempts, "err", err)
+			return false
+		}
+		e.deadLetter(ctx, sig, err)
+	case CategoryPermanent:
+		e.deadLetter(ctx, sig, err)
+	}
+	return true
+}
+
+// deadLetter gives up on a signal, keeping the error for inspection
+func (e *Executor) deadLetter(ctx context.Context, sig database.Signal, err error) {
+	slog.Error("dead-lettering signal", "signal_id", sig.ID, "trader", sig.Trader, "token_id", sig.TokenID, "err", err)
+	if err := e.db.MarkSignalFailed(ctx, sig.ID, err.Error()); err != nil {
+		slog.Error("failed to mark signal failed", "signal_id", sig.ID, "err", err)
+	}
+}
+
+func (e *Executor) skipSignal(ctx context.Context, sig database.Signal, reason string) {
+	slog.Info("skipping signal", "signal_id", sig.ID, "trader", sig.Trader, "token_id", sig.TokenID, "reason", reason)
+	if err := e.db.MarkSignalSkipped(ctx, sig.ID, reason); err != nil {
+		slog.Error("failed to mark signal skipped", "signal_id", sig.ID, "err", err)
+	}
+}
+
+// Pause stops copying signals until Resume; detection keeps running and
+// signals arriving meanwhile are skipped
+func (e *Executor) Pause(ctx context.Context) error {
+	return e.setPaused(ctx, true)
+}
 
-	// log.Printf("Executor ready with address: %s", crypto.PubkeyToAddress(privateKey.PublicKey).Hex())
+// Resume undoes Pause
+func (e *Executor) Resume(ctx context.Context) error {
+	return e.setPaused(ctx, false)
+}
 
-	// In production, listen for trade signals from ingestion layer
-	// For now, just keep the executor alive
-	<-ctx.Done()
+func (e *Executor) setPaused(ctx context.Context, paused bool) error {
+	if err := e.db.SetPaused(ctx, paused); err != nil {
+		return fmt.Errorf("failed to store paused state: %w", err)
+	}
+	if e.paused.Swap(paused) != paused {
+		if paused {
+			slog.Warn("copy trading paused")
+		} else {
+			slog.Info("copy trading resumed")
+		}
+	}
 	return nil
 }
 
-func (e *Executor) ExecuteTrade(req TradeRequest) error {
-	log.Printf("Executing trade: %s %s %.2f @ %.4f", req.Side, req.MarketID, req.Amount, req.Price)
+// Paused reports whether copy trading is paused
+func (e *Executor) Paused() bool {
+	return e.paused.Load()
+}
+
+func tradeRequestFromSignal(sig database.Signal) TradeRequest {
+	return TradeRequest{
+		MarketID: sig.MarketID,
+		TokenID:  sig.TokenID,
+		Side:     strings.ToLower(sig.Side),
+		Amount:   strategy.FromBaseUnits(sig.Amount),
+		Price:    strategy.FromBaseUnits(sig.Price),
+		Exchange: sig.Exchange,
+
+		SourceTrader: sig.Trader,
+		SourceTxHash: sig.TxHash,
+		SignalID:     sig.ID,
+	}
+}
+
+func (e *Executor) ExecuteTrade(ctx context.Context, req TradeRequest) error {
+	slog.Info("executing trade", "trader", req.SourceTrader, "side", req.Side, "market_id", req.MarketID,
+		"token_id", req.TokenID, "amount", req.Amount, "price", req.Price)
 
-	// Create position record
-	position, err := e.db.CreatePosition(req.MarketID, req.TokenID, req.Outcome, req.Amount, req.Price)
+	if e.paused.Load() {
+		return &ErrSkip{Reason: "skipped_paused"}
+	}
+
+	if !tradingAllowed(e.cfg.TradingSchedule, e.now()) {
+		return &ErrSkip{Reason: "skipped_outside_hours"}
+	}
+
+	if err := e.checkLeaderboardFreshness(ctx); err != nil {
+		return err
+	}
+
+	// Listener signals only carry the token; the market is needed for the
+	// per-market impact limit and the self-hedge check
+	req = e.resolveToken(ctx, req)
+
+	req, err := e.limitPriceImpact(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	req, err = e.sizeToBalance(ctx, req)
 	if err != nil {
-		return fmt.Errorf("failed to create position: %w", err)
+		return err
+	}
+
+	if err := e.checkSelfHedge(ctx, req); err != nil {
+		return err
+	}
+
+	if err := e.checkFees(ctx, req); err != nil {
+		return err
+	}
+
+	if req.Amount <= 0 || req.Price <= 0 || req.Price >= 1 {
+		return fmt.Errorf("%w: invalid trade amount %.4f or price %.4f", ErrPermanent, req.Amount, req.Price)
 	}
 
-	// Create trade record
-	trade, err := e.db.CreateTrade(position.ID, "", req.Side, req.Amount, req.Price)
+	// A sell needs a position to net against and never sells more than it
+	// holds. Checked before submitting, the position itself only changes
+	// once the order has gone through.
+	if strings.EqualFold(req.Side, "sell") {
+		held, err := e.db.GetOpenPosition(ctx, req.TokenID, req.SourceTrader)
+		if err != nil {
+			return fmt.Errorf("%w: failed to get position: %w", ErrTransient, err)
+		}
+		if held == nil {
+			return &ErrSkip{Reason: "skipped_no_position"}
+		}
+		if req.Amount > held.Amount {
+			slog.Info("capping sell at the position held", "token_id", req.TokenID, "amount", req.Amount, "held", held.Amount)
+			req.Amount = held.Amount
+		}
+	}
+
+	// Recorded as pending, not yet applied to a position, so an order that
+	// fails to submit leaves the book untouched and can safely be retried
+	trade, err := e.db.CreateTrade(ctx, 0, req.SourceTrader, req.Side, req.Amount, req.Price)
 	if err != nil {
-		return fmt.Errorf("failed to create trade: %w", err)
+		return fmt.Errorf("%w: failed to create trade: %w", ErrTransient, err)
+	}
+
+	// Dry runs keep the position and trade records but never submit
+	status := "dry_run"
+	var txHash string
+	if e.cfg.DryRun {
+		txHash = simulatedTxHash(buildOrder(req))
+		slog.Info("dry run, nothing submitted", "side", req.Side, "token_id", req.TokenID, "amount", req.Amount,
+			"price", req.Price, "trade_id", trade.ID, "tx_hash", txHash)
+	} else {
+		status = "confirmed"
+		txHash, err = e.submitTrade(ctx, req)
+		if err != nil {
+			failed := "failed"
+			if errors.Is(err, ErrOrderUnknown) {
+				failed = "unknown"
+			}
+			if dbErr := e.db.UpdateTradeStatus(ctx, trade.ID, failed, ""); dbErr != nil {
+				slog.Error("failed to update trade status", "trade_id", trade.ID, "err", dbErr)
+			}
+			e.metrics.TradesFailed.Inc(1)
+			e.bus.Publish(events.TradeFailed, tradeResult(req, "", err))
+			if Classify(err) == CategoryPermanent {
+				return fmt.Errorf("failed to submit trade: %w", err)
+			}
+			return fmt.Errorf("%w: failed to submit trade: %w", ErrTransient, err)
+		}
 	}
 
-	// Execute on-chain trade
-	txHash, err := e.submitTrade(req)
+	// Buys average into the open position, sells net against it. The
+	// position and its trade record commit together, so a crash can't leave
+	// a position change without the trade behind it.
+	position, err := e.applyFill(ctx, req, trade.ID, status, txHash)
 	if err != nil {
-		e.db.UpdateTradeStatus(trade.ID, "failed", "")
-		return fmt.Errorf("failed to submit trade: %w", err)
+		// The order is placed, retrying would place it again
+		slog.Error("order submitted but not recorded on its position", "trade_id", trade.ID, "token_id", req.TokenID,
+			"side", req.Side, "amount", req.Amount, "tx_hash", txHash, "err", err)
+		return fmt.Errorf("%w: trade %d submitted but not recorded: %w", ErrPermanent, trade.ID, err)
 	}
 
-	// Update trade with tx hash
-	if err := e.db.UpdateTradeStatus(trade.ID, "confirmed", txHash); err != nil {
-		log.Printf("Failed to update trade status: %v", err)
+	if e.cfg.DryRun {
+		result := tradeResult(req, txHash, nil)
+		result.DryRun = true
+		e.metrics.TradesExecuted.Inc(1)
+		e.bus.Publish(events.TradeExecuted, result)
+		e.publishIfClosed(position)
+		return nil
 	}
 
-	log.Printf("Trade executed: %s", txHash)
+	slog.Info("trade executed", "trader", req.SourceTrader, "side", req.Side, "token_id", req.TokenID,
+		"market", req.Question, "outcome", req.Outcome, "tx_hash", txHash)
+	e.metrics.TradesExecuted.Inc(1)
+	e.bus.Publish(events.TradeExecuted, tradeResult(req, txHash, nil))
+	e.publishIfClosed(position)
 	return nil
 }
 
-func (e *Executor) submitTrade(req TradeRequest) (string, error) {
-	// Build transaction to vault contract
-	auth, err := bind.NewKeyedTransactorWithChainID(e.privateKey, e.chainID)
+// applyFill applies an executed trade to its position and settles the trade
+// record and the signal it copies, in one transaction
+func (e *Executor) applyFill(ctx context.Context, req TradeRequest, tradeID int64, status, txHash string) (*database.Position, error) {
+	var position *database.Position
+	err := e.db.WithTx(ctx, func(tx *sql.Tx) error {
+		var applied float64
+		var err error
+		position, applied, err = e.db.AddToPositionTx(ctx, tx, database.Fill{
+			MarketID:     req.MarketID,
+			TokenID:      req.TokenID,
+			Outcome:      req.Outcome,
+			Question:     req.Question,
+			Side:         req.Side,
+			Amount:       req.Amount,
+			Price:        req.Price,
+			SourceTrader: req.SourceTrader,
+			SourceTxHash: req.SourceTxHash,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update position: %w", err)
+		}
+		// Sells are capped before submitting, so this only differs if the
+		// position shrank in between
+		if applied < req.Amount {
+			slog.Warn("sell exceeded the position when applied", "token_id", req.TokenID, "amount", req.Amount,
+				"applied", applied, "trade_id", tradeID)
+		}
+		if err := e.db.SettleTradeTx(ctx, tx, tradeID, position.ID, status, txHash); err != nil {
+			return fmt.Errorf("failed to update trade: %w", err)
+		}
+		// Settled with the fill, so a crash can't leave the signal pending
+		// to be copied a second time
+		if req.SignalID != 0 {
+			if err := e.db.MarkSignalProcessedTx(ctx, tx, req.SignalID); err != nil {
+				return fmt.Errorf("failed to mark signal processed: %w", err)
+			}
+		}
+		return nil
+	})
+	return position, err
+}
+
+// publishIfClosed announces a position that a sell has fully exited
+func (e *Executor) publishIfClosed(position *database.Position) {
+	if position.Status != "closed" {
+		return
+	}
+	slog.Info("closed position", "position_id", position.ID, "token_id", position.TokenID, "realized_pnl", position.RealizedPnL)
+	e.bus.Publish(events.PositionClosed, *position)
+}
+
+// resolveToken fills in the market question and outcome of the trade's
+// token where they're missing. A token that can't be resolved is still
+// traded, recorded by its ID alone.
+func (e *Executor) resolveToken(ctx context.Context, req TradeRequest) TradeRequest {
+	if req.Question != "" && req.Outcome != "" {
+		return req
+	}
+	token, err := e.tokens.Resolve(ctx, req.TokenID)
+	if err != nil {
+		slog.Warn("couldn't resolve token to its market", "token_id", req.TokenID, "err", err)
+		return req
+	}
+	if req.Question == "" {
+		req.Question = token.Question
+	}
+	if req.Outcome == "" {
+		req.Outcome = token.Outcome
+	}
+	if req.MarketID == "" {
+		req.MarketID = token.MarketID
+	}
+	return req
+}
+
+func tradeResult(req TradeRequest, txHash string, err error) events.TradeResult {
+	result := events.TradeResult{
+		MarketID: req.MarketID,
+		TokenID:  req.TokenID,
+		Question: req.Question,
+		Outcome:  req.Outcome,
+		Side:     req.Side,
+		Amount:   req.Amount,
+		Price:    req.Price,
+		TxHash:   txHash,
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func (e *Executor) submitTrade(ctx context.Context, req TradeRequest) (string, error) {
+	// Wait for a free submission slot
+	select {
+	case e.submitSlots <- struct{}{}:
+	default:
+		depth := e.queued.Add(1)
+		slog.Warn("trade submissions backing up", "queued", depth, "in_flight_limit", cap(e.submitSlots))
+		e.submitSlots <- struct{}{}
+		e.queued.Add(-1)
+	}
+	defer func() { <-e.submitSlots }()
+
+	return e.submitOrder(ctx, buildOrder(req))
+}
+
+// submitOrder signs the order against its exchange and places it on the CLOB
+func (e *Executor) submitOrder(ctx context.Context, order *Order) (string, error) {
+	signed, err := e.signOrder(order)
+	if err != nil {
+		return "", err
+	}
+
+	txHash, err := e.postOrder(ctx, signed)
 	if err != nil {
 		return "", err
 	}
 
-	// Gas settings
-	auth.GasLimit = uint64(300000)
-	
-	// In production, call the vault contract's executeTrade function
-	// For now, return a mock tx hash
-	mockTxHash := fmt.Sprintf("0x%064x", 12345)
-	
-	log.Printf("Submitted transaction: %s", mockTxHash)
-	return mockTxHash, nil
-}
-
-// func (e *Executor) GetVaultBalance() (*big.Int, error) {
-// 	if e.client == nil {
-// 		return big.NewInt(0), fmt.Errorf("client not initialized")
-// 	}
-
-// 	// vaultAddr := common.HexToAddress(e.cfg.VaultContractAddr)
-// 	// balance, err := e.client.BalanceAt(context.Background(), vaultAddr, nil)
-// 	// if err != nil {
-// 	// 	return nil, err
-// 	// }
-// 	bal := 1000.(big.Int)
-// 	// return balance, nil
-// 	return &bal, nil
-// }
-
-func (e *Executor) CalculateTotalShares() (float64, error) {
-	// Query all users and sum shares
-	// For now, return mock value
-	return 1000.0, nil
-}
-
-func (e *Executor) CalculateVaultValue() (float64, error) {
-	// Calculate total vault value based on:
-	// - USDC balance
-	// - Open positions (marked to market)
-	// For now, return mock value
-	return 10000.0, nil
-}
\ No newline at end of file
+	slog.Info("submitted order", "side", order.Side, "token_id", order.TokenID, "neg_risk", order.NegRisk,
+		"exchange", order.Exchange.Hex(), "tx_hash", txHash)
+	return txHash, nil
+}
+
+// simulatedTxHash derives a unique placeholder hash for a dry-run order
+func simulatedTxHash(order *Order) string {
+	seed := fmt.Sprintf("%s|%s|%f|%f|%d", order.TokenID, order.Side, order.Amount, order.Price, time.Now().UnixNano())
+	return crypto.Keccak256Hash([]byte(seed)).Hex()
+}
+
+// CalculateTotalShares sums the shares issued to all depositors
+func (e *Executor) CalculateTotalShares(ctx context.Context) (float64, error) {
+	users, err := e.db.GetAllUsers(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	total := 0.0
+	for _, u := range users {
+		total += u.Shares
+	}
+	return total, nil
+}
+
+// CalculateVaultValue is the USDC balance plus every open position marked to
+// market, refreshing position prices first
+func (e *Executor) CalculateVaultValue(ctx context.Context) (float64, error) {
+	balance, err := e.usdcBalance(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	positions, err := e.RefreshPositionPrices(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	value := balance
+	for _, p := range positions {
+		value += p.Amount * p.CurrentPrice
+	}
+	e.metrics.VaultValue.Update(value)
+	return value, nil
+}
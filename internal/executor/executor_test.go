@@ -0,0 +1,460 @@
+// internal/executor/executor_test.go
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	gethmath "github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/askwhyharsh/lazytrader/internal/config"
+	"github.com/askwhyharsh/lazytrader/internal/database"
+	"github.com/askwhyharsh/lazytrader/internal/events"
+	"github.com/askwhyharsh/lazytrader/internal/listener"
+	"github.com/askwhyharsh/lazytrader/internal/polygon"
+)
+
+const testWallet = "0x00000000000000000000000000000000000000f1"
+
+// roundTripFunc serves HTTP requests from a function instead of the network
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// clobStub stands in for the CLOB: it serves book for every order book,
+// accepts every order and records what was posted. With hold set, orders
+// wait for it to be closed before being answered.
+type clobStub struct {
+	t    *testing.T
+	book string
+	hold chan struct{}
+
+	mu          sync.Mutex
+	orders      []clobOrderRequest
+	inFlight    int
+	maxInFlight int
+}
+
+func (c *clobStub) client() *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		w := httptest.NewRecorder()
+		c.ServeHTTP(w, r)
+		return w.Result(), nil
+	})}
+}
+
+func (c *clobStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.URL.Path == "/book":
+		io.WriteString(w, c.book)
+	case r.URL.Path == "/order" && r.Method == http.MethodPost:
+		c.mu.Lock()
+		c.inFlight++
+		c.maxInFlight = max(c.maxInFlight, c.inFlight)
+		c.mu.Unlock()
+		if c.hold != nil {
+			<-c.hold
+		}
+		defer func() {
+			c.mu.Lock()
+			c.inFlight--
+			c.mu.Unlock()
+		}()
+
+		var order clobOrderRequest
+		if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+			c.t.Errorf("undecodable order: %v", err)
+		}
+		c.mu.Lock()
+		c.orders = append(c.orders, order)
+		n := len(c.orders)
+		c.mu.Unlock()
+		fmt.Fprintf(w, `{"success":true,"orderID":"0xorder%d","transactionsHashes":["0xtx%d"],"status":"matched"}`, n, n)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (c *clobStub) posted() []clobOrderRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]clobOrderRequest(nil), c.orders...)
+}
+
+// rpcStub answers the JSON-RPC calls the executor makes: the chain ID, gas
+// price and the wallet's USDC balance (in whole USDC)
+type rpcStub struct {
+	balance float64
+}
+
+func (s *rpcStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+
+	var result string
+	switch req.Method {
+	case "eth_chainId":
+		result = "0x89"
+	case "eth_gasPrice":
+		result = "0x3b9aca00" // 1 gwei
+	case "eth_call":
+		raw := big.NewInt(int64(s.balance * 1e6))
+		result = "0x" + common.Bytes2Hex(common.LeftPadBytes(raw.Bytes(), 32))
+	default:
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32601,"message":"unsupported"}}`, req.ID)
+		return
+	}
+	fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%q}`, req.ID, result)
+}
+
+// testExecutorConfig is a config as Load would leave it for a live executor
+func testExecutorConfig() *config.Config {
+	return &config.Config{
+		StrategyID:          "default",
+		WalletAddress:       testWallet,
+		ClobAPIKey:          "key",
+		ClobAPISecret:       "c2VjcmV0",
+		ClobAPIPassphrase:   "pass",
+		CopyTradeMultiplier: 0.1,
+		SizingMode:          "proportional",
+		MaxConcurrentTrades: 4,
+		SignalMaxAttempts:   3,
+		MaxVaultFraction:    1,
+		MinTradeNotional:    1,
+		GasTokenPriceUSD:    0.5,
+	}
+}
+
+// newTestExecutor returns an executor on a fresh database with a signing
+// key, an RPC stub holding 1000 USDC and the CLOB stub
+func newTestExecutor(t *testing.T, cfg *config.Config, clob *clobStub) (*Executor, *database.DB) {
+	t.Helper()
+	db, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("database.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	rpc := httptest.NewServer(&rpcStub{balance: 1000})
+	t.Cleanup(rpc.Close)
+	client, err := polygon.Dial(context.Background(), []string{rpc.URL})
+	if err != nil {
+		t.Fatalf("polygon.Dial: %v", err)
+	}
+	t.Cleanup(client.Close)
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := New(cfg, db, events.New(), nil)
+	e.client = client
+	e.privateKey = key
+	e.chainID = big.NewInt(137)
+	clob.t = t
+	e.httpClient = clob.client()
+	return e, db
+}
+
+// signedFor reports whether order's signature is by signer over the order
+// as signed against exchange
+func signedFor(t *testing.T, order SignedOrder, exchange string, signer common.Address) bool {
+	t.Helper()
+	side := "0"
+	if order.Side == "SELL" {
+		side = "1"
+	}
+	hash, _, err := apitypes.TypedDataAndHash(apitypes.TypedData{
+		Types:       clobOrderTypes,
+		PrimaryType: "Order",
+		Domain: apitypes.TypedDataDomain{
+			Name:              "Polymarket CTF Exchange",
+			Version:           "1",
+			ChainId:           (*gethmath.HexOrDecimal256)(big.NewInt(137)),
+			VerifyingContract: exchange,
+		},
+		Message: apitypes.TypedDataMessage{
+			"salt":          fmt.Sprint(order.Salt),
+			"maker":         order.Maker,
+			"signer":        order.Signer,
+			"taker":         order.Taker,
+			"tokenId":       order.TokenID,
+			"makerAmount":   order.MakerAmount,
+			"takerAmount":   order.TakerAmount,
+			"expiration":    order.Expiration,
+			"nonce":         order.Nonce,
+			"feeRateBps":    order.FeeRateBps,
+			"side":          side,
+			"signatureType": fmt.Sprint(order.SignatureType),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := common.FromHex(order.Signature)
+	sig[64] -= 27
+	pub, err := crypto.SigToPub(hash, sig)
+	return err == nil && crypto.PubkeyToAddress(*pub) == signer
+}
+
+func TestNegRiskRouting(t *testing.T) {
+	tests := []struct {
+		name     string
+		signal   database.Signal
+		negRisk  bool // From market metadata
+		exchange string
+	}{
+		{"ctf fill", database.Signal{Exchange: listener.CTF_EXCHANGE_ADDR}, false, listener.CTF_EXCHANGE_ADDR},
+		{"negrisk fill", database.Signal{Exchange: listener.NEG_RISK_EXCHANGE_ADDR}, false, listener.NEG_RISK_EXCHANGE_ADDR},
+		{"negrisk fill in lowercase", database.Signal{Exchange: strings.ToLower(listener.NEG_RISK_EXCHANGE_ADDR)}, false, listener.NEG_RISK_EXCHANGE_ADDR},
+		{"negrisk market metadata", database.Signal{}, true, listener.NEG_RISK_EXCHANGE_ADDR},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clob := &clobStub{}
+			e, _ := newTestExecutor(t, testExecutorConfig(), clob)
+
+			sig := tt.signal
+			sig.Trader, sig.Side, sig.TokenID, sig.Amount, sig.Price = testWallet, "BUY", "123", "100000000", "500000"
+			req := tradeRequestFromSignal(sig)
+			req.NegRisk = tt.negRisk
+
+			order := buildOrder(req)
+			if order.NegRisk != (tt.exchange == listener.NEG_RISK_EXCHANGE_ADDR) {
+				t.Errorf("order.NegRisk = %v", order.NegRisk)
+			}
+			if order.Exchange != common.HexToAddress(tt.exchange) {
+				t.Errorf("order exchange = %s, want %s", order.Exchange.Hex(), tt.exchange)
+			}
+
+			if _, err := e.submitTrade(context.Background(), req); err != nil {
+				t.Fatalf("submitTrade: %v", err)
+			}
+			posted := clob.posted()
+			if len(posted) != 1 {
+				t.Fatalf("%d orders posted, want 1", len(posted))
+			}
+			signer := crypto.PubkeyToAddress(e.privateKey.PublicKey)
+			if !signedFor(t, posted[0].Order, tt.exchange, signer) {
+				t.Errorf("order not signed against %s", tt.exchange)
+			}
+			other := listener.CTF_EXCHANGE_ADDR
+			if tt.exchange == other {
+				other = listener.NEG_RISK_EXCHANGE_ADDR
+			}
+			if signedFor(t, posted[0].Order, other, signer) {
+				t.Errorf("order also verifies against %s", other)
+			}
+		})
+	}
+}
+
+func TestSubmitConcurrencyLimit(t *testing.T) {
+	const limit, trades = 2, 7
+
+	cfg := testExecutorConfig()
+	cfg.MaxConcurrentTrades = limit
+	clob := &clobStub{hold: make(chan struct{})}
+	e, _ := newTestExecutor(t, cfg, clob)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, trades)
+	for n := 0; n < trades; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := e.submitTrade(context.Background(), TradeRequest{TokenID: "123", Side: "buy", Amount: 10, Price: 0.5})
+			errs <- err
+		}()
+	}
+
+	// Wait for the slots to fill and the rest to queue behind them
+	deadline := time.Now().Add(5 * time.Second)
+	for e.queued.Load() != trades-limit {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d submissions queued, want %d", e.queued.Load(), trades-limit)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(clob.hold)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("submitTrade: %v", err)
+		}
+	}
+
+	if got := len(clob.posted()); got != trades {
+		t.Errorf("%d orders posted, want %d", got, trades)
+	}
+	if clob.maxInFlight > limit {
+		t.Errorf("%d orders in flight at once, limit is %d", clob.maxInFlight, limit)
+	}
+	if q := e.queued.Load(); q != 0 {
+		t.Errorf("queue depth %d after draining", q)
+	}
+}
+
+func TestPositionAttribution(t *testing.T) {
+	const (
+		alice = "0x00000000000000000000000000000000000000a1"
+		bob   = "0x00000000000000000000000000000000000000b2"
+	)
+	ctx := context.Background()
+	e, db := newTestExecutor(t, testExecutorConfig(), &clobStub{})
+
+	copies := []struct {
+		trader, token, txHash string
+	}{
+		{alice, "111", "0xaa01"},
+		{alice, "111", "0xaa02"}, // adds to the position opened by 0xaa01
+		{alice, "222", "0xaa03"},
+		{bob, "111", "0xbb01"},
+	}
+	for _, c := range copies {
+		req := TradeRequest{TokenID: c.token, MarketID: "m", Question: "q", Outcome: "Yes", Side: "buy", Amount: 10, Price: 0.5,
+			SourceTrader: c.trader, SourceTxHash: c.txHash}
+		if err := e.ExecuteTrade(ctx, req); err != nil {
+			t.Fatalf("ExecuteTrade %s: %v", c.txHash, err)
+		}
+	}
+
+	tests := []struct {
+		trader string
+		want   map[string]string // token -> source tx
+	}{
+		{alice, map[string]string{"111": "0xaa01", "222": "0xaa03"}},
+		{bob, map[string]string{"111": "0xbb01"}},
+		{"0x00000000000000000000000000000000000000c3", map[string]string{}},
+	}
+	for _, tt := range tests {
+		positions, err := db.GetPositionsByTrader(ctx, tt.trader)
+		if err != nil {
+			t.Fatalf("GetPositionsByTrader(%s): %v", tt.trader, err)
+		}
+		got := make(map[string]string)
+		for _, p := range positions {
+			if p.SourceTrader != tt.trader {
+				t.Errorf("position %d from %s in %s's positions", p.ID, p.SourceTrader, tt.trader)
+			}
+			got[p.TokenID] = p.SourceTxHash
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s's positions = %v, want %v", tt.trader, got, tt.want)
+		}
+	}
+}
+
+func TestDryRunNeverSubmits(t *testing.T) {
+	const trader = "0x00000000000000000000000000000000000000a1"
+	ctx := context.Background()
+	cfg := testExecutorConfig()
+	cfg.DryRun = true
+	e, db := newTestExecutor(t, cfg, &clobStub{})
+	// Any request reaching the CLOB is a submission dry runs must not make
+	e.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		t.Errorf("dry run sent %s %s to the CLOB", r.Method, r.URL.Path)
+		return nil, errors.New("CLOB unavailable in dry run")
+	})}
+
+	for _, side := range []string{"buy", "sell"} {
+		req := TradeRequest{TokenID: "111", MarketID: "m", Question: "q", Outcome: "Yes", Side: side, Amount: 10, Price: 0.5,
+			SourceTrader: trader}
+		if err := e.ExecuteTrade(ctx, req); err != nil {
+			t.Fatalf("ExecuteTrade %s: %v", side, err)
+		}
+	}
+
+	trades, err := db.GetTrades(ctx, database.TradeFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("GetTrades: %v", err)
+	}
+	if len(trades) != 2 {
+		t.Fatalf("%d trades recorded, want 2", len(trades))
+	}
+	for _, trade := range trades {
+		if trade.Status != "dry_run" || trade.TxHash == "" {
+			t.Errorf("%s trade recorded as %q with tx hash %q, want dry_run with a simulated hash", trade.Side, trade.Status, trade.TxHash)
+		}
+	}
+	// The buy opened a position and the sell closed it again
+	if position, err := db.GetOpenPosition(ctx, "111", trader); err != nil || position != nil {
+		t.Errorf("GetOpenPosition = %+v, %v, want the position closed", position, err)
+	}
+}
+
+func TestSignalSettledWithItsTrade(t *testing.T) {
+	const trader = "0x00000000000000000000000000000000000000a1"
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		finishedFirst bool // the signal was settled elsewhere meanwhile
+		wantStatus    string
+		wantPosition  bool
+	}{
+		{"processed with its position", false, "processed", true},
+		{"position rolled back with the signal", true, "skipped", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, db := newTestExecutor(t, testExecutorConfig(), &clobStub{})
+			sig, _, err := db.CreateSignal(ctx, &database.Signal{Trader: trader, Side: "BUY", MarketID: "m", TokenID: "111",
+				Amount: "100000000", Price: "500000", TxHash: "0x01", BlockNumber: 10})
+			if err != nil {
+				t.Fatalf("CreateSignal: %v", err)
+			}
+			if tt.finishedFirst {
+				if err := db.MarkSignalSkipped(ctx, sig.ID, "skipped_by_test"); err != nil {
+					t.Fatalf("MarkSignalSkipped: %v", err)
+				}
+			}
+
+			req := tradeRequestFromSignal(*sig)
+			req.Question, req.Outcome = "q", "Yes"
+			err = e.ExecuteTrade(ctx, req)
+			if (err != nil) != tt.finishedFirst {
+				t.Fatalf("ExecuteTrade = %v", err)
+			}
+
+			signals, err := db.GetSignalHistory(ctx, 10)
+			if err != nil || len(signals) != 1 {
+				t.Fatalf("GetSignalHistory = %d signals, %v", len(signals), err)
+			}
+			if signals[0].Status != tt.wantStatus {
+				t.Errorf("signal %s, want %s", signals[0].Status, tt.wantStatus)
+			}
+			position, err := db.GetOpenPosition(ctx, "111", trader)
+			if err != nil {
+				t.Fatalf("GetOpenPosition: %v", err)
+			}
+			if (position != nil) != tt.wantPosition {
+				t.Errorf("position = %+v, want one recorded %v", position, tt.wantPosition)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// internal/executor/exits.go
+package executor
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/askwhyharsh/lazytrader/internal/database"
+)
+
+// exitDecision says what to do with a signal once ExitConfirmationDelay is
+// taken into account
+type exitDecision int
+
+const (
+	exitProceed exitDecision = iota // Not an exit, or confirmed
+	exitWait                        // Still inside the confirmation window
+	exitCancel                      // The trader re-entered, don't close
+)
+
+// confirmExit holds a source trader's sell back for ExitConfirmationDelay,
+// so a brief trim they re-add to doesn't whipsaw our position. The signal
+// stays pending while waiting; after the window the close goes ahead unless
+// the trader bought the same token again within it.
+func (e *Executor) confirmExit(ctx context.Context, sig database.Signal) (exitDecision, error) {
+	delay := e.cfg.ExitConfirmationDelay
+	if delay <= 0 || !strings.EqualFold(sig.Side, "sell") {
+		return exitProceed, nil
+	}
+
+	deadline := sig.DetectedAt.Add(delay)
+	if e.now().Before(deadline) {
+		return exitWait, nil
+	}
+
+	reentered, err := e.db.HasBuySignalAfter(ctx, sig.Trader, sig.TokenID, sig.ID, deadline)
+	if err != nil {
+		return exitWait, err
+	}
+	if reentered {
+		slog.Info("trader re-entered after exiting, cancelling close", "trader", sig.Trader, "token_id", sig.TokenID, "delay", delay)
+		return exitCancel, nil
+	}
+	return exitProceed, nil
+}
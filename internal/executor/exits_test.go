@@ -0,0 +1,99 @@
+// internal/executor/exits_test.go
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/askwhyharsh/lazytrader/internal/database"
+)
+
+func TestExitConfirmationDelay(t *testing.T) {
+	const trader = "0x00000000000000000000000000000000000000a1"
+
+	tests := []struct {
+		name       string
+		reenter    bool
+		wantStatus string
+		wantReason string
+		wantOrders int
+		wantHeld   float64
+	}{
+		{"re-entry cancels the close", true, "skipped", "skipped_exit_reentered", 0, 100},
+		{"close goes ahead without re-entry", false, "processed", "", 1, 95},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cfg := testExecutorConfig()
+			cfg.ExitConfirmationDelay = time.Minute
+			clob := &clobStub{}
+			e, db := newTestExecutor(t, cfg, clob)
+
+			// We copied 100 shares of the trader's position
+			open := database.Fill{MarketID: "m", TokenID: "123", Outcome: "Yes", Side: "buy", Amount: 100, Price: 0.5, SourceTrader: trader}
+			if _, _, err := db.AddToPosition(ctx, open); err != nil {
+				t.Fatalf("AddToPosition: %v", err)
+			}
+
+			// They sell 50, and maybe buy back within the window
+			exit, _, err := db.CreateSignal(ctx, &database.Signal{Trader: trader, Side: "SELL", MarketID: "m", TokenID: "123",
+				Amount: "50000000", Price: "600000", TxHash: "0x01", BlockNumber: 10})
+			if err != nil {
+				t.Fatalf("CreateSignal: %v", err)
+			}
+			if tt.reenter {
+				reentry := &database.Signal{Trader: trader, Side: "BUY", MarketID: "m", TokenID: "123",
+					Amount: "50000000", Price: "550000", TxHash: "0x02", BlockNumber: 11}
+				if _, _, err := db.CreateSignal(ctx, reentry); err != nil {
+					t.Fatalf("CreateSignal: %v", err)
+				}
+			}
+			signals, err := db.GetSignalHistory(ctx, 10)
+			if err != nil {
+				t.Fatalf("GetSignalHistory: %v", err)
+			}
+			for _, sig := range signals {
+				if sig.ID == exit.ID {
+					exit = &sig
+				}
+			}
+
+			// Inside the window the exit waits
+			e.now = func() time.Time { return exit.DetectedAt.Add(30 * time.Second) }
+			if e.processSignal(ctx, *exit) {
+				t.Fatal("exit settled inside the confirmation window")
+			}
+			if n := len(clob.posted()); n != 0 {
+				t.Fatalf("%d orders posted inside the confirmation window", n)
+			}
+
+			e.now = func() time.Time { return exit.DetectedAt.Add(2 * time.Minute) }
+			if !e.processSignal(ctx, *exit) {
+				t.Fatal("exit still pending after the confirmation window")
+			}
+
+			signals, err = db.GetSignalHistory(ctx, 10)
+			if err != nil {
+				t.Fatalf("GetSignalHistory: %v", err)
+			}
+			for _, sig := range signals {
+				if sig.ID == exit.ID && (sig.Status != tt.wantStatus || sig.Reason != tt.wantReason) {
+					t.Errorf("exit signal %s (%s), want %s (%s)", sig.Status, sig.Reason, tt.wantStatus, tt.wantReason)
+				}
+			}
+			if n := len(clob.posted()); n != tt.wantOrders {
+				t.Errorf("%d orders posted, want %d", n, tt.wantOrders)
+			}
+			position, err := db.GetOpenPosition(ctx, "123", trader)
+			if err != nil || position == nil {
+				t.Fatalf("GetOpenPosition = %v, %v", position, err)
+			}
+			if position.Amount != tt.wantHeld {
+				t.Errorf("holding %v shares, want %v", position.Amount, tt.wantHeld)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// internal/executor/fees.go
+package executor
+
+import (
+	"context"
+	"log/slog"
+	"math/big"
+	"time"
+)
+
+const (
+	// Gas limit used for copy trade transactions
+	tradeGasLimit = 300000
+
+	// Used when the RPC can't suggest a gas price (or isn't connected)
+	fallbackGasPriceWei = 50_000_000_000 // 50 gwei
+)
+
+// estimateFees returns the estimated total cost in USDC of copying a trade of
+// the given notional: gas (limit × price, converted from POL) plus the CLOB fee
+func (e *Executor) estimateFees(ctx context.Context, notional float64) float64 {
+	gasPrice := big.NewInt(fallbackGasPriceWei)
+	if e.client != nil {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if suggested, err := e.client.SuggestGasPrice(ctx); err == nil {
+			gasPrice = suggested
+		} else {
+			slog.Warn("failed to get gas price, using fallback", "err", err)
+		}
+	}
+
+	gasWei := new(big.Float).SetInt(new(big.Int).Mul(gasPrice, big.NewInt(tradeGasLimit)))
+	gasPOL, _ := new(big.Float).Quo(gasWei, big.NewFloat(1e18)).Float64()
+
+	gasFee := gasPOL * e.cfg.GasTokenPriceUSD
+	clobFee := notional * e.cfg.ClobFeeBps / 10000
+	return gasFee + clobFee
+}
+
+// checkFees skips trades whose estimated fees would eat more than the
+// configured fraction of the notional
+func (e *Executor) checkFees(ctx context.Context, req TradeRequest) error {
+	if e.cfg.MaxFeeFraction <= 0 {
+		return nil
+	}
+
+	notional := req.Amount * req.Price
+	fees := e.estimateFees(ctx, notional)
+	if fees > notional*e.cfg.MaxFeeFraction {
+		slog.Info("fees exceed limit", "token_id", req.TokenID, "fees", fees,
+			"max_fee_fraction", e.cfg.MaxFeeFraction, "notional", notional)
+		return &ErrSkip{Reason: "skipped_unprofitable_fees"}
+	}
+	return nil
+}
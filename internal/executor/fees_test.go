@@ -0,0 +1,58 @@
+// internal/executor/fees_test.go
+package executor
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCheckFees(t *testing.T) {
+	// The RPC stub suggests 1 gwei, so gas is 300000 gwei = 0.0003 POL; at
+	// 1000 USD per POL that's 0.30 USDC per trade, plus 1% to the CLOB
+	tests := []struct {
+		name           string
+		maxFeeFraction float64
+		amount, price  float64
+		wantSkip       bool
+	}{
+		{"small trade fee dominated", 0.05, 4, 0.5, true}, // 0.32 fees on 2 USDC
+		{"large trade passes", 0.05, 200, 0.5, false},     // 1.30 fees on 100 USDC
+		{"just under the limit", 0.05, 16, 0.5, false},    // 0.38 fees on 8 USDC
+		{"gate disabled", 0, 4, 0.5, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testExecutorConfig()
+			cfg.MaxFeeFraction = tt.maxFeeFraction
+			cfg.ClobFeeBps = 100
+			cfg.GasTokenPriceUSD = 1000
+			e, _ := newTestExecutor(t, cfg, &clobStub{})
+
+			err := e.checkFees(context.Background(), TradeRequest{TokenID: "123", Side: "buy", Amount: tt.amount, Price: tt.price})
+			var skip *ErrSkip
+			if tt.wantSkip {
+				if !errors.As(err, &skip) || skip.Reason != "skipped_unprofitable_fees" {
+					t.Fatalf("checkFees = %v, want skipped_unprofitable_fees", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkFees = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestEstimateFees(t *testing.T) {
+	cfg := testExecutorConfig()
+	cfg.ClobFeeBps = 100
+	cfg.GasTokenPriceUSD = 1000
+	e, _ := newTestExecutor(t, cfg, &clobStub{})
+
+	if got := e.estimateFees(context.Background(), 100); math.Abs(got-1.3) > 1e-9 {
+		t.Errorf("estimateFees(100) = %v, want 1.3", got)
+	}
+}
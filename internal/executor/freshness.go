@@ -0,0 +1,40 @@
+// internal/executor/freshness.go
+package executor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// checkLeaderboardFreshness pauses trading while the tracked trader set is
+// older than MaxLeaderboardStaleness, since we may be copying traders who've
+// dropped off. Trading resumes on its own once a refresh lands.
+func (e *Executor) checkLeaderboardFreshness(ctx context.Context) error {
+	if e.cfg.MaxLeaderboardStaleness <= 0 {
+		return nil
+	}
+
+	updated, err := e.db.GetLeaderboardUpdatedAt(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: failed to read leaderboard age: %w", ErrTransient, err)
+	}
+
+	if updated == nil || e.now().Sub(*updated) > e.cfg.MaxLeaderboardStaleness {
+		if !e.leaderboardStale.Swap(true) {
+			if updated == nil {
+				slog.Error("ALERT: no leaderboard data stored, pausing trading")
+			} else {
+				slog.Error("ALERT: leaderboard data is stale, pausing trading",
+					"age", e.now().Sub(*updated).Round(time.Second), "max", e.cfg.MaxLeaderboardStaleness)
+			}
+		}
+		return &ErrSkip{Reason: "skipped_stale_leaderboard"}
+	}
+
+	if e.leaderboardStale.Swap(false) {
+		slog.Info("leaderboard refreshed, resuming trading")
+	}
+	return nil
+}
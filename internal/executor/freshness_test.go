@@ -0,0 +1,78 @@
+// internal/executor/freshness_test.go
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/askwhyharsh/lazytrader/internal/database"
+)
+
+func TestLeaderboardStalenessPausesTrading(t *testing.T) {
+	ctx := context.Background()
+	req := TradeRequest{TokenID: "123", MarketID: "m", Question: "q", Outcome: "Yes", SourceTrader: testWallet,
+		Side: "buy", Amount: 10, Price: 0.5}
+
+	cfg := testExecutorConfig()
+	cfg.MaxLeaderboardStaleness = time.Hour
+	clob := &clobStub{}
+	e, db := newTestExecutor(t, cfg, clob)
+	var offset time.Duration
+	e.now = func() time.Time { return time.Now().Add(offset) }
+
+	refresh := func() {
+		t.Helper()
+		trader := database.TopTrader{Address: "0x00000000000000000000000000000000000000aa", Rank: 1}
+		if err := db.UpsertTopTrader(ctx, trader); err != nil {
+			t.Fatalf("UpsertTopTrader: %v", err)
+		}
+	}
+
+	steps := []struct {
+		name      string
+		refresh   bool
+		offset    time.Duration
+		wantStale bool
+	}{
+		{"no leaderboard stored", false, 0, true},
+		{"fresh leaderboard", true, 0, false},
+		{"leaderboard goes stale", false, 2 * time.Hour, true},
+		{"refresh resumes trading", true, 0, false},
+	}
+
+	posted := 0
+	for _, step := range steps {
+		if step.refresh {
+			refresh()
+		}
+		offset = step.offset
+
+		err := e.ExecuteTrade(ctx, req)
+		if step.wantStale {
+			var skip *ErrSkip
+			if !errors.As(err, &skip) || skip.Reason != "skipped_stale_leaderboard" {
+				t.Fatalf("%s: ExecuteTrade = %v, want skipped_stale_leaderboard", step.name, err)
+			}
+		} else {
+			if err != nil {
+				t.Fatalf("%s: ExecuteTrade = %v, want executed", step.name, err)
+			}
+			posted++
+		}
+		if n := len(clob.posted()); n != posted {
+			t.Errorf("%s: %d orders posted, want %d", step.name, n, posted)
+		}
+		if e.leaderboardStale.Load() != step.wantStale {
+			t.Errorf("%s: paused = %v, want %v", step.name, e.leaderboardStale.Load(), step.wantStale)
+		}
+	}
+}
+
+func TestLeaderboardStalenessDisabled(t *testing.T) {
+	e, _ := newTestExecutor(t, testExecutorConfig(), &clobStub{})
+	if err := e.checkLeaderboardFreshness(context.Background()); err != nil {
+		t.Errorf("checkLeaderboardFreshness = %v with the check off and no leaderboard, want nil", err)
+	}
+}
@@ -0,0 +1,34 @@
+// internal/executor/hedge.go
+package executor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// checkSelfHedge skips a buy that would offset a position we already hold in
+// another outcome of the same market. In a binary market YES and NO are
+// opposite sides, so copying both just hedges us to flat and burns fees.
+func (e *Executor) checkSelfHedge(ctx context.Context, req TradeRequest) error {
+	if !e.cfg.AvoidSelfHedging || req.Side != "buy" {
+		return nil
+	}
+	if req.MarketID == "" {
+		slog.Warn("market unknown, can't check for self-hedging", "token_id", req.TokenID)
+		return nil
+	}
+
+	positions, err := e.db.GetOpenPositionsInMarket(ctx, req.MarketID)
+	if err != nil {
+		return fmt.Errorf("%w: failed to check open positions: %w", ErrTransient, err)
+	}
+	for _, p := range positions {
+		if p.TokenID != req.TokenID {
+			slog.Info("buy would offset an open position", "token_id", req.TokenID,
+				"outcome", p.Outcome, "market_id", req.MarketID)
+			return &ErrSkip{Reason: "skipped_self_hedge"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// internal/executor/hedge_test.go
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSelfHedge(t *testing.T) {
+	// We hold YES (token 111) in market m1
+	tests := []struct {
+		name     string
+		avoid    bool
+		market   string
+		token    string
+		closed   bool // the YES position was already exited
+		wantSkip bool
+	}{
+		{"NO buy while holding YES", true, "m1", "222", false, true},
+		{"adding to YES", true, "m1", "111", false, false},
+		{"NO buy in another market", true, "m2", "222", false, false},
+		{"NO buy after YES was closed", true, "m1", "222", true, false},
+		{"check disabled", false, "m1", "222", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cfg := testExecutorConfig()
+			cfg.AvoidSelfHedging = tt.avoid
+			clob := &clobStub{}
+			e, db := newTestExecutor(t, cfg, clob)
+
+			yes, err := db.CreatePosition(ctx, "m1", "111", "Yes", 20, 0.6)
+			if err != nil {
+				t.Fatalf("CreatePosition: %v", err)
+			}
+			if tt.closed {
+				if err := db.ClosePosition(ctx, yes.ID, 0.7); err != nil {
+					t.Fatalf("ClosePosition: %v", err)
+				}
+			}
+
+			req := TradeRequest{TokenID: tt.token, MarketID: tt.market, Question: "q", Outcome: "No", SourceTrader: testWallet,
+				Side: "buy", Amount: 10, Price: 0.4}
+			err = e.ExecuteTrade(ctx, req)
+			if tt.wantSkip {
+				var skip *ErrSkip
+				if !errors.As(err, &skip) || skip.Reason != "skipped_self_hedge" {
+					t.Fatalf("ExecuteTrade = %v, want skipped_self_hedge", err)
+				}
+				if n := len(clob.posted()); n != 0 {
+					t.Errorf("%d orders posted for a self-hedging buy", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExecuteTrade = %v, want executed", err)
+			}
+			if n := len(clob.posted()); n != 1 {
+				t.Errorf("%d orders posted, want 1", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// internal/executor/key.go
+package executor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/askwhyharsh/lazytrader/internal/config"
+)
+
+// loadSigningKey decrypts the configured keystore, falling back to the
+// plaintext private key. A missing key is only accepted in dry-run mode,
+// where nothing is ever signed.
+func (e *Executor) loadSigningKey() (*ecdsa.PrivateKey, error) {
+	if e.cfg.KeystorePath != "" {
+		return loadKeystore(e.cfg.KeystorePath, e.cfg.KeystorePassphrase)
+	}
+
+	hexKey := strings.TrimPrefix(strings.TrimSpace(e.cfg.PrivateKey), "0x")
+	if hexKey == "" {
+		if e.cfg.DryRun {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("no signing key: set keystore_path (with %s) or private_key, or enable dry_run",
+			config.EnvName("keystore_passphrase"))
+	}
+	slog.Warn("signing with the plaintext private_key, move it to an encrypted keystore_path")
+
+	key, err := crypto.HexToECDSA(hexKey)
+	if err != nil {
+		// Don't echo the key material back into logs
+		return nil, fmt.Errorf("invalid private key")
+	}
+	return key, nil
+}
+
+// loadKeystore decrypts a go-ethereum JSON keystore file
+func loadKeystore(path, passphrase string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+	key, err := keystore.DecryptKey(data, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore %s: %w", path, err)
+	}
+	return key.PrivateKey, nil
+}
+
+// initSigner loads the key and chain ID before any signal is picked up, so a
+// bad key stops the executor at startup instead of failing the first trade
+func (e *Executor) initSigner(ctx context.Context) error {
+	key, err := e.loadSigningKey()
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		slog.Warn("no private key configured, running in dry-run mode without signing")
+		return nil
+	}
+
+	chainID, err := e.client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	e.privateKey = key
+	e.chainID = chainID
+	slog.Info("executor ready", "address", crypto.PubkeyToAddress(key.PublicKey).Hex())
+	return nil
+}
@@ -0,0 +1,80 @@
+// internal/executor/key_test.go
+package executor
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestInitSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hexKey := "0x" + hex.EncodeToString(crypto.FromECDSA(key))
+
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.ImportECDSA(key, "hunter2")
+	if err != nil {
+		t.Fatalf("ImportECDSA: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		privateKey string
+		keystore   string
+		passphrase string
+		dryRun     bool
+		wantErr    string
+		wantSigner bool
+	}{
+		{"missing key", "", "", "", false, "no signing key", false},
+		{"missing key in dry run", "", "", "", true, "", false},
+		{"invalid key", "0xnothex", "", "", false, "invalid private key", false},
+		{"invalid key in dry run", "0xnothex", "", "", true, "invalid private key", false},
+		{"valid key", hexKey, "", "", false, "", true},
+		{"keystore", "", account.URL.Path, "hunter2", false, "", true},
+		{"keystore wrong passphrase", "", account.URL.Path, "wrong", false, "failed to decrypt keystore", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testExecutorConfig()
+			cfg.PrivateKey = tt.privateKey
+			cfg.KeystorePath = tt.keystore
+			cfg.KeystorePassphrase = tt.passphrase
+			cfg.DryRun = tt.dryRun
+			e, _ := newTestExecutor(t, cfg, &clobStub{})
+			e.privateKey, e.chainID = nil, nil
+
+			err := e.initSigner(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("initSigner = %v, want an error mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("initSigner = %v, want nil", err)
+			}
+
+			if !tt.wantSigner {
+				if e.privateKey != nil {
+					t.Error("signing key loaded without one configured")
+				}
+				return
+			}
+			if e.privateKey == nil || crypto.PubkeyToAddress(e.privateKey.PublicKey) != account.Address {
+				t.Errorf("loaded the wrong signing key")
+			}
+			if e.chainID == nil || e.chainID.Int64() != 137 {
+				t.Errorf("chainID = %v, want 137", e.chainID)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+// internal/executor/markets.go
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/askwhyharsh/lazytrader/internal/polymarket"
+)
+
+// checkMarketFilter skips copies of markets the market_allowlist and
+// market_denylist rule out. Entries match a market's condition ID, slug or
+// category; markets the Gamma API doesn't know can only match by ID.
+func (e *Executor) checkMarketFilter(ctx context.Context, req TradeRequest) error {
+	if len(e.cfg.MarketAllowlist) == 0 && len(e.cfg.MarketDenylist) == 0 {
+		return nil
+	}
+
+	token, err := e.tokens.Resolve(ctx, req.TokenID)
+	if err != nil && !errors.Is(err, polymarket.ErrMarketNotFound) {
+		return fmt.Errorf("%w: failed to resolve market for filtering: %w", ErrTransient, err)
+	}
+	keys := []string{req.MarketID, token.MarketID, token.Slug, token.Category}
+
+	if entry, ok := matchMarket(e.cfg.MarketDenylist, keys); ok {
+		slog.Info("market denylisted", "token_id", req.TokenID, "market", token.Question, "entry", entry)
+		return &ErrSkip{Reason: "skipped_market_denied"}
+	}
+	if len(e.cfg.MarketAllowlist) == 0 {
+		return nil
+	}
+	if _, ok := matchMarket(e.cfg.MarketAllowlist, keys); !ok {
+		slog.Info("market not allowlisted", "token_id", req.TokenID, "market", token.Question,
+			"market_id", token.MarketID, "category", token.Category)
+		return &ErrSkip{Reason: "skipped_market_not_allowed"}
+	}
+	return nil
+}
+
+// matchMarket returns the first list entry equal to one of a market's
+// non-empty keys, ignoring case
+func matchMarket(list, keys []string) (string, bool) {
+	for _, entry := range list {
+		for _, key := range keys {
+			if key != "" && strings.EqualFold(entry, key) {
+				return entry, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,226 @@
+// internal/executor/orderbook.go
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	CLOB_API = "https://clob.polymarket.com"
+)
+
+// BookLevel is one price level of the CLOB order book
+type BookLevel struct {
+	Price float64
+	Size  float64
+}
+
+// OrderBook holds bids (best first, descending) and asks (best first, ascending)
+type OrderBook struct {
+	Bids []BookLevel
+	Asks []BookLevel
+}
+
+// fetchOrderBook loads the current CLOB book for a token
+func (e *Executor) fetchOrderBook(ctx context.Context, tokenID string) (*OrderBook, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", CLOB_API+"/book?token_id="+url.QueryEscape(tokenID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch order book: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("order book API returned status %d", resp.StatusCode)
+	}
+
+	// The CLOB encodes prices and sizes as strings
+	var raw struct {
+		Bids []struct{ Price, Size string } `json:"bids"`
+		Asks []struct{ Price, Size string } `json:"asks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode order book: %w", err)
+	}
+
+	book := &OrderBook{}
+	for _, l := range raw.Bids {
+		book.Bids = append(book.Bids, parseLevel(l.Price, l.Size))
+	}
+	for _, l := range raw.Asks {
+		book.Asks = append(book.Asks, parseLevel(l.Price, l.Size))
+	}
+	sort.Slice(book.Bids, func(i, j int) bool { return book.Bids[i].Price > book.Bids[j].Price })
+	sort.Slice(book.Asks, func(i, j int) bool { return book.Asks[i].Price < book.Asks[j].Price })
+	return book, nil
+}
+
+func parseLevel(price, size string) BookLevel {
+	p, _ := strconv.ParseFloat(price, 64)
+	s, _ := strconv.ParseFloat(size, 64)
+	return BookLevel{Price: p, Size: s}
+}
+
+// Mid returns the midpoint of the best bid and ask, or the best level of
+// whichever side has one when the book is one-sided
+func (b *OrderBook) Mid() (float64, bool) {
+	switch {
+	case len(b.Bids) > 0 && len(b.Asks) > 0:
+		return (b.Bids[0].Price + b.Asks[0].Price) / 2, true
+	case len(b.Bids) > 0:
+		return b.Bids[0].Price, true
+	case len(b.Asks) > 0:
+		return b.Asks[0].Price, true
+	}
+	return 0, false
+}
+
+// Outcome token prices live strictly inside (0, 1); limit prices are kept
+// within one tick of the bounds
+const (
+	minLimitPrice = 0.001
+	maxLimitPrice = 0.999
+)
+
+func plausiblePrice(p float64) bool {
+	return p > 0 && p < 1
+}
+
+// resolvePrice returns the price to copy at: the signal's own price when
+// it's plausible, otherwise the current market mid, clamped to the valid
+// range. ok is false when neither is available.
+func (e *Executor) resolvePrice(ctx context.Context, tokenID string, signalPrice float64) (float64, bool) {
+	price := signalPrice
+	if !plausiblePrice(price) {
+		book, err := e.fetchOrderBook(ctx, tokenID)
+		if err != nil {
+			slog.Warn("no usable price and order book unavailable", "token_id", tokenID, "signal_price", signalPrice, "err", err)
+			return 0, false
+		}
+		mid, ok := book.Mid()
+		if !ok || !plausiblePrice(mid) {
+			slog.Warn("no usable price and empty order book", "token_id", tokenID, "signal_price", signalPrice)
+			return 0, false
+		}
+		slog.Info("signal price is implausible, using market mid", "token_id", tokenID, "signal_price", signalPrice, "mid", mid)
+		price = mid
+	}
+	return math.Min(math.Max(price, minLimitPrice), maxLimitPrice), true
+}
+
+// levels returns the side of the book an order of the given side takes from
+func (b *OrderBook) levels(side string) []BookLevel {
+	if side == "sell" {
+		return b.Bids
+	}
+	return b.Asks
+}
+
+// EstimateFill walks the book for an order of size shares and returns the
+// volume-weighted fill price, the price impact against the best level in
+// basis points, and how much of the size the book can absorb
+func (b *OrderBook) EstimateFill(side string, size float64) (vwap, impactBps, filled float64) {
+	levels := b.levels(side)
+	if len(levels) == 0 || size <= 0 {
+		return 0, 0, 0
+	}
+
+	var cost float64
+	for _, level := range levels {
+		take := level.Size
+		if remaining := size - filled; take > remaining {
+			take = remaining
+		}
+		cost += take * level.Price
+		filled += take
+		if filled >= size {
+			break
+		}
+	}
+	if filled == 0 {
+		return 0, 0, 0
+	}
+
+	vwap = cost / filled
+	best := levels[0].Price
+	if side == "sell" {
+		impactBps = (best - vwap) / best * 10000
+	} else {
+		impactBps = (vwap - best) / best * 10000
+	}
+	return vwap, impactBps, filled
+}
+
+// TrimToImpact returns the largest size up to size whose estimated price
+// impact stays within maxBps, together with that impact. Sizes the book
+// can't absorb are trimmed to the available depth.
+func (b *OrderBook) TrimToImpact(side string, size, maxBps float64) (float64, float64) {
+	if _, impact, filled := b.EstimateFill(side, size); filled >= size && impact <= maxBps {
+		return size, impact
+	}
+
+	// Impact grows monotonically with size, so binary search the cutoff
+	lo, hi := 0.0, size
+	for i := 0; i < 50; i++ {
+		mid := (lo + hi) / 2
+		_, impact, filled := b.EstimateFill(side, mid)
+		if filled >= mid && impact <= maxBps {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	_, impact, _ := b.EstimateFill(side, lo)
+	return lo, impact
+}
+
+// maxPriceImpactBps is the price impact limit for a market: its
+// market_max_price_impact_bps override, or max_price_impact_bps
+func (e *Executor) maxPriceImpactBps(marketID string) float64 {
+	for market, bps := range e.cfg.MarketMaxPriceImpactBps {
+		if marketID != "" && strings.EqualFold(market, marketID) {
+			return bps
+		}
+	}
+	return e.cfg.MaxPriceImpactBps
+}
+
+// limitPriceImpact trims a trade so its estimated price impact stays within
+// the market's limit (maxPriceImpactBps). If the book can't be loaded the
+// trade goes ahead as is.
+func (e *Executor) limitPriceImpact(ctx context.Context, req TradeRequest) (TradeRequest, error) {
+	maxImpact := e.maxPriceImpactBps(req.MarketID)
+	if maxImpact <= 0 {
+		return req, nil
+	}
+
+	book, err := e.fetchOrderBook(ctx, req.TokenID)
+	if err != nil {
+		slog.Warn("skipping price impact check", "token_id", req.TokenID, "err", err)
+		return req, nil
+	}
+
+	size, impact := book.TrimToImpact(req.Side, req.Amount, maxImpact)
+	if size <= 0 {
+		return req, &ErrSkip{Reason: "skipped_price_impact"}
+	}
+	if size < req.Amount {
+		slog.Info("trimmed order to price impact limit", "side", req.Side, "token_id", req.TokenID,
+			"amount", req.Amount, "trimmed", size, "impact_bps", impact, "max_impact_bps", maxImpact)
+		req.Amount = size
+	}
+	return req, nil
+}
@@ -0,0 +1,259 @@
+// internal/executor/orderbook_test.go
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/askwhyharsh/lazytrader/internal/config"
+	"github.com/askwhyharsh/lazytrader/internal/database"
+)
+
+// Asks of 100 @ 0.50, 100 @ 0.55, 100 @ 0.60; bids of 100 @ 0.48, 100 @ 0.40
+func testBook() *OrderBook {
+	return &OrderBook{
+		Bids: []BookLevel{{Price: 0.48, Size: 100}, {Price: 0.40, Size: 100}},
+		Asks: []BookLevel{{Price: 0.50, Size: 100}, {Price: 0.55, Size: 100}, {Price: 0.60, Size: 100}},
+	}
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestEstimateFill(t *testing.T) {
+	tests := []struct {
+		name       string
+		side       string
+		size       float64
+		wantVWAP   float64
+		wantImpact float64
+		wantFilled float64
+	}{
+		{"buy within best level", "buy", 50, 0.50, 0, 50},
+		{"buy across two levels", "buy", 200, 0.525, 500, 200},
+		{"buy deeper than the book", "buy", 400, 0.55, 1000, 300},
+		{"sell within best level", "sell", 100, 0.48, 0, 100},
+		{"sell across two levels", "sell", 200, 0.44, 833.333333, 200},
+		{"zero size", "buy", 0, 0, 0, 0},
+	}
+
+	book := testBook()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vwap, impact, filled := book.EstimateFill(tt.side, tt.size)
+			if !approx(vwap, tt.wantVWAP) || !approx(impact, tt.wantImpact) || !approx(filled, tt.wantFilled) {
+				t.Errorf("EstimateFill(%s, %v) = (%v, %v, %v), want (%v, %v, %v)",
+					tt.side, tt.size, vwap, impact, filled, tt.wantVWAP, tt.wantImpact, tt.wantFilled)
+			}
+		})
+	}
+}
+
+func TestEstimateFillEmptyBook(t *testing.T) {
+	book := &OrderBook{}
+	if vwap, impact, filled := book.EstimateFill("buy", 10); vwap != 0 || impact != 0 || filled != 0 {
+		t.Errorf("EstimateFill on empty book = (%v, %v, %v), want zeros", vwap, impact, filled)
+	}
+}
+
+func TestTrimToImpact(t *testing.T) {
+	tests := []struct {
+		name     string
+		side     string
+		size     float64
+		maxBps   float64
+		wantSize float64
+	}{
+		{"within limit", "buy", 100, 100, 100},
+		// 100 @ 0.50 + x @ 0.55 has a VWAP of 0.505 (100 bps) at x = 100/9
+		{"trimmed to limit", "buy", 200, 100, 100 + 100.0/9},
+		{"trimmed to depth", "buy", 500, 10000, 300},
+		{"no impact allowed", "sell", 150, 0, 100},
+	}
+
+	book := testBook()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, impact := book.TrimToImpact(tt.side, tt.size, tt.maxBps)
+			if math.Abs(size-tt.wantSize) > 1e-3 {
+				t.Errorf("size = %v, want %v", size, tt.wantSize)
+			}
+			if impact > tt.maxBps+1e-6 {
+				t.Errorf("impact = %v bps, over the %v limit", impact, tt.maxBps)
+			}
+		})
+	}
+}
+
+func TestMid(t *testing.T) {
+	tests := []struct {
+		name   string
+		book   *OrderBook
+		want   float64
+		wantOK bool
+	}{
+		{"both sides", testBook(), 0.49, true},
+		{"bids only", &OrderBook{Bids: []BookLevel{{Price: 0.3, Size: 1}}}, 0.3, true},
+		{"asks only", &OrderBook{Asks: []BookLevel{{Price: 0.7, Size: 1}}}, 0.7, true},
+		{"empty", &OrderBook{}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.book.Mid()
+			if ok != tt.wantOK || !approx(got, tt.want) {
+				t.Errorf("Mid() = (%v, %v), want (%v, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestMaxPriceImpactBps(t *testing.T) {
+	e := &Executor{cfg: &config.Config{
+		MaxPriceImpactBps:       100,
+		MarketMaxPriceImpactBps: map[string]float64{"0xABC": 300, "0xdef": 0},
+	}}
+
+	tests := []struct {
+		marketID string
+		want     float64
+	}{
+		{"0xabc", 300},
+		{"0xDEF", 0},
+		{"0x123", 100},
+		{"", 100},
+	}
+	for _, tt := range tests {
+		if got := e.maxPriceImpactBps(tt.marketID); got != tt.want {
+			t.Errorf("maxPriceImpactBps(%q) = %v, want %v", tt.marketID, got, tt.want)
+		}
+	}
+}
+
+// bookClient returns an HTTP client that answers every order book request
+// with body
+func bookClient(body string) *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    r,
+		}, nil
+	})}
+}
+
+func TestLimitPriceImpact(t *testing.T) {
+	const book = `{"bids":[{"price":"0.48","size":"100"}],"asks":[{"price":"0.55","size":"100"},{"price":"0.50","size":"100"}]}`
+
+	tests := []struct {
+		name       string
+		maxBps     float64
+		amount     float64
+		wantAmount float64
+		wantSkip   bool
+	}{
+		{"disabled", 0, 500, 500, false},
+		{"within limit", 100, 100, 100, false},
+		{"trimmed", 100, 200, 100 + 100.0/9, false},
+		{"best level only", 100, 50, 50, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Executor{
+				cfg:        &config.Config{MaxPriceImpactBps: tt.maxBps},
+				httpClient: bookClient(book),
+			}
+			req := TradeRequest{TokenID: "1", Side: "buy", Amount: tt.amount, Price: 0.5}
+
+			got, err := e.limitPriceImpact(context.Background(), req)
+			var skip *ErrSkip
+			if tt.wantSkip != errors.As(err, &skip) {
+				t.Fatalf("err = %v, want skip %v", err, tt.wantSkip)
+			}
+			if math.Abs(got.Amount-tt.wantAmount) > 1e-3 {
+				t.Errorf("amount = %v, want %v", got.Amount, tt.wantAmount)
+			}
+		})
+	}
+}
+
+func TestLimitPriceImpactEmptyBook(t *testing.T) {
+	e := &Executor{
+		cfg:        &config.Config{MaxPriceImpactBps: 100},
+		httpClient: bookClient(`{"bids":[],"asks":[]}`),
+	}
+	_, err := e.limitPriceImpact(context.Background(), TradeRequest{TokenID: "1", Side: "buy", Amount: 10, Price: 0.5})
+
+	var skip *ErrSkip
+	if !errors.As(err, &skip) || skip.Reason != "skipped_price_impact" {
+		t.Errorf("err = %v, want skipped_price_impact", err)
+	}
+}
+
+func TestResolvePrice(t *testing.T) {
+	const book = `{"bids":[{"price":"0.48","size":"100"}],"asks":[{"price":"0.50","size":"100"}]}`
+
+	tests := []struct {
+		name        string
+		book        string
+		signalPrice float64
+		want        float64
+		wantOK      bool
+	}{
+		{"plausible signal price", book, 0.6, 0.6, true},
+		{"missing price uses mid", book, 0, 0.49, true},
+		{"price above 1 uses mid", book, 1.7, 0.49, true},
+		{"negative price uses mid", book, -0.2, 0.49, true},
+		{"clamped below 1", book, 0.9999, maxLimitPrice, true},
+		{"clamped above 0", book, 0.0001, minLimitPrice, true},
+		{"no price and empty book", `{"bids":[],"asks":[]}`, 0, 0, false},
+		{"one-sided book uses its best price", `{"bids":[{"price":"0.48","size":"100"}],"asks":[]}`, 0, 0.48, true},
+		{"no price and book unavailable", `not json`, 1.5, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Executor{cfg: &config.Config{}, httpClient: bookClient(tt.book)}
+
+			got, ok := e.resolvePrice(context.Background(), "1", tt.signalPrice)
+			if ok != tt.wantOK || !approx(got, tt.want) {
+				t.Errorf("resolvePrice(%v) = %v, %v, want %v, %v", tt.signalPrice, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestProcessSignalWithoutPrice(t *testing.T) {
+	ctx := context.Background()
+	clob := &clobStub{book: `{"bids":[],"asks":[]}`}
+	e, db := newTestExecutor(t, testExecutorConfig(), clob)
+
+	// A fill without a price, in a market with no quotes
+	sig, _, err := db.CreateSignal(ctx, &database.Signal{Trader: testWallet, Side: "BUY", MarketID: "m", TokenID: "123",
+		Amount: "100000000", TxHash: "0x01", BlockNumber: 10})
+	if err != nil {
+		t.Fatalf("CreateSignal: %v", err)
+	}
+
+	if !e.processSignal(ctx, *sig) {
+		t.Fatal("signal without a price left pending, want it settled")
+	}
+	stored, err := db.GetSignalHistory(ctx, 1)
+	if err != nil || len(stored) != 1 {
+		t.Fatalf("GetSignalHistory = %v, %v", stored, err)
+	}
+	if stored[0].Status != "skipped" || stored[0].Reason != "skipped_no_price" {
+		t.Errorf("signal %s (%s), want skipped with skipped_no_price", stored[0].Status, stored[0].Reason)
+	}
+	if n := len(clob.posted()); n != 0 {
+		t.Errorf("%d orders posted without a price", n)
+	}
+}
@@ -0,0 +1,152 @@
+// internal/executor/prices.go
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/askwhyharsh/lazytrader/internal/config"
+	"github.com/askwhyharsh/lazytrader/internal/database"
+	"github.com/askwhyharsh/lazytrader/internal/metrics"
+)
+
+// Tokens per POST /midpoints request
+const midpointBatchSize = 50
+
+// PriceRefresher keeps open positions marked to the CLOB midpoint. It needs
+// no wallet or RPC, so it runs whether or not the executor does.
+type PriceRefresher struct {
+	cfg        *config.Config
+	db         *database.DB
+	httpClient *http.Client
+	metrics    *metrics.Metrics
+}
+
+func NewPriceRefresher(cfg *config.Config, db *database.DB) *PriceRefresher {
+	return &PriceRefresher{
+		cfg:        cfg,
+		db:         db,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		metrics:    metrics.For(cfg.StrategyID),
+	}
+}
+
+// Start refreshes prices every PriceRefreshInterval until ctx is cancelled
+func (r *PriceRefresher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(r.cfg.PriceRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := r.Refresh(ctx); err != nil && ctx.Err() == nil {
+				slog.Error("failed to refresh position prices", "err", err)
+			}
+		}
+	}
+}
+
+// Refresh marks every open position to its token's current midpoint and
+// returns them. Positions whose price can't be fetched keep their last
+// stored price.
+func (r *PriceRefresher) Refresh(ctx context.Context) ([]database.Position, error) {
+	positions, err := r.db.GetOpenPositions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open positions: %w", err)
+	}
+	r.metrics.OpenPositions.Update(int64(len(positions)))
+
+	var tokenIDs []string
+	seen := make(map[string]bool)
+	for _, p := range positions {
+		if !seen[p.TokenID] {
+			seen[p.TokenID] = true
+			tokenIDs = append(tokenIDs, p.TokenID)
+		}
+	}
+
+	prices := make(map[string]float64, len(tokenIDs))
+	for start := 0; start < len(tokenIDs); start += midpointBatchSize {
+		batch := tokenIDs[start:min(start+midpointBatchSize, len(tokenIDs))]
+		mids, err := r.fetchMidpoints(ctx, batch)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			slog.Error("failed to fetch midpoints", "tokens", len(batch), "err", err)
+			continue
+		}
+		for tokenID, mid := range mids {
+			prices[tokenID] = mid
+		}
+	}
+
+	for i, p := range positions {
+		price, ok := prices[p.TokenID]
+		if !ok {
+			slog.Warn("no midpoint, keeping last price", "position_id", p.ID, "token_id", p.TokenID, "price", p.CurrentPrice)
+			continue
+		}
+		if err := r.db.UpdatePositionPrice(ctx, p.ID, price); err != nil {
+			return nil, fmt.Errorf("failed to update price of position %d: %w", p.ID, err)
+		}
+		positions[i].CurrentPrice = price
+	}
+	return positions, nil
+}
+
+// fetchMidpoints returns the CLOB midpoint of each token it has a usable
+// price for
+func (r *PriceRefresher) fetchMidpoints(ctx context.Context, tokenIDs []string) (map[string]float64, error) {
+	params := make([]map[string]string, 0, len(tokenIDs))
+	for _, tokenID := range tokenIDs {
+		params = append(params, map[string]string{"token_id": tokenID})
+	}
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", CLOB_API+"/midpoints", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch midpoints: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("midpoints API returned status %d", resp.StatusCode)
+	}
+
+	// Token ID to midpoint, encoded as a string
+	var raw map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode midpoints: %w", err)
+	}
+
+	mids := make(map[string]float64, len(raw))
+	for tokenID, s := range raw {
+		if mid, err := strconv.ParseFloat(s, 64); err == nil && plausiblePrice(mid) {
+			mids[tokenID] = mid
+		}
+	}
+	return mids, nil
+}
+
+// RefreshPositionPrices marks every open position to market and returns them
+func (e *Executor) RefreshPositionPrices(ctx context.Context) ([]database.Position, error) {
+	return e.prices.Refresh(ctx)
+}
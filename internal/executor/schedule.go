@@ -0,0 +1,64 @@
+// internal/executor/schedule.go
+package executor
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/askwhyharsh/lazytrader/internal/config"
+)
+
+// tradingAllowed reports whether the schedule permits trading at t. A
+// schedule that can't be interpreted blocks trading rather than ignoring it.
+func tradingAllowed(schedule config.TradingSchedule, t time.Time) bool {
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		slog.Error("invalid trading schedule timezone", "timezone", schedule.Timezone, "err", err)
+		return false
+	}
+	t = t.In(loc)
+
+	today := t.Format("2006-01-02")
+	for _, date := range schedule.BlackoutDates {
+		if date == today {
+			return false
+		}
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	for _, w := range schedule.BlackoutWindows {
+		if inWindow(w, minute) {
+			return false
+		}
+	}
+
+	if len(schedule.AllowedWindows) == 0 {
+		return true
+	}
+	for _, w := range schedule.AllowedWindows {
+		if inWindow(w, minute) {
+			return true
+		}
+	}
+	return false
+}
+
+// inWindow reports whether a minute of the day falls in [Start, End)
+func inWindow(w config.TimeWindow, minute int) bool {
+	start, err := time.Parse("15:04", w.Start)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse("15:04", w.End)
+	if err != nil {
+		return false
+	}
+
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	if from <= to {
+		return minute >= from && minute < to
+	}
+	// Wraps past midnight
+	return minute >= from || minute < to
+}
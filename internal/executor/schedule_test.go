@@ -0,0 +1,101 @@
+// internal/executor/schedule_test.go
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/askwhyharsh/lazytrader/internal/config"
+)
+
+func TestTradingAllowed(t *testing.T) {
+	ny := config.TradingSchedule{
+		Timezone:        "America/New_York",
+		AllowedWindows:  []config.TimeWindow{{Start: "09:00", End: "17:00"}},
+		BlackoutWindows: []config.TimeWindow{{Start: "12:00", End: "12:30"}},
+		BlackoutDates:   []string{"2026-07-04"},
+	}
+	overnight := config.TradingSchedule{
+		Timezone:        "UTC",
+		BlackoutWindows: []config.TimeWindow{{Start: "22:00", End: "02:00"}},
+	}
+
+	tests := []struct {
+		name     string
+		schedule config.TradingSchedule
+		at       string // RFC 3339
+		want     bool
+	}{
+		{"no schedule", config.TradingSchedule{Timezone: "UTC"}, "2026-03-10T03:00:00Z", true},
+		{"inside allowed hours", ny, "2026-03-10T14:00:00Z", true},  // 10:00 EDT
+		{"before allowed hours", ny, "2026-03-10T12:59:00Z", false}, // 08:59 EDT
+		{"end of window is exclusive", ny, "2026-03-10T21:00:00Z", false},
+		{"daily blackout", ny, "2026-03-10T16:15:00Z", false}, // 12:15 EDT
+		{"after daily blackout", ny, "2026-03-10T16:30:00Z", true},
+		{"blackout date", ny, "2026-07-04T14:00:00Z", false},
+		{"blackout date in local time", ny, "2026-07-05T03:00:00Z", false}, // 23:00 on the 4th
+		{"overnight blackout before midnight", overnight, "2026-03-10T23:00:00Z", false},
+		{"overnight blackout after midnight", overnight, "2026-03-11T01:59:00Z", false},
+		{"outside overnight blackout", overnight, "2026-03-11T02:00:00Z", true},
+		{"unknown timezone blocks trading", config.TradingSchedule{Timezone: "Mars/Olympus"}, "2026-03-10T14:00:00Z", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at, err := time.Parse(time.RFC3339, tt.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tradingAllowed(tt.schedule, at); got != tt.want {
+				t.Errorf("tradingAllowed at %s = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteTradeSchedule(t *testing.T) {
+	cfg := testExecutorConfig()
+	cfg.TradingSchedule = config.TradingSchedule{
+		Timezone:        "UTC",
+		BlackoutWindows: []config.TimeWindow{{Start: "13:00", End: "14:00"}},
+	}
+	req := TradeRequest{TokenID: "123", MarketID: "m", Question: "q", Outcome: "Yes", SourceTrader: testWallet,
+		Side: "buy", Amount: 10, Price: 0.5}
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		wantSkip bool
+	}{
+		{"during blackout", time.Date(2026, 3, 10, 13, 30, 0, 0, time.UTC), true},
+		{"outside blackout", time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clob := &clobStub{}
+			e, _ := newTestExecutor(t, cfg, clob)
+			e.now = func() time.Time { return tt.at }
+
+			err := e.ExecuteTrade(context.Background(), req)
+			var skip *ErrSkip
+			if tt.wantSkip {
+				if !errors.As(err, &skip) || skip.Reason != "skipped_outside_hours" {
+					t.Fatalf("ExecuteTrade = %v, want skipped_outside_hours", err)
+				}
+				if n := len(clob.posted()); n != 0 {
+					t.Errorf("%d orders posted during the blackout", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExecuteTrade = %v, want executed", err)
+			}
+			if n := len(clob.posted()); n != 1 {
+				t.Errorf("%d orders posted, want 1", n)
+			}
+		})
+	}
+}
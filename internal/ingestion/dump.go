@@ -0,0 +1,63 @@
+// internal/ingestion/dump.go
+package ingestion
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const dumpPrefix = "leaderboard-"
+
+// dumpResponse writes a raw API response body to DebugDumpDir, if configured,
+// and prunes the oldest dumps beyond DebugDumpMaxFiles. Failures are logged,
+// never returned, so debugging can't break ingestion.
+func (i *Ingestion) dumpResponse(body []byte) {
+	dir := i.cfg.DebugDumpDir
+	if dir == "" {
+		return
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		slog.Error("failed to create debug dump dir", "err", err)
+		return
+	}
+
+	// Timestamps sort lexically, which pruning relies on
+	name := fmt.Sprintf("%s%s.json", dumpPrefix, time.Now().UTC().Format("20060102T150405.000000000"))
+	if err := os.WriteFile(filepath.Join(dir, name), body, 0o644); err != nil {
+		slog.Error("failed to write debug dump", "err", err)
+		return
+	}
+
+	pruneDumps(dir, i.cfg.DebugDumpMaxFiles)
+}
+
+func pruneDumps(dir string, maxFiles int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		slog.Error("failed to list debug dumps", "err", err)
+		return
+	}
+
+	var dumps []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), dumpPrefix) {
+			dumps = append(dumps, entry.Name())
+		}
+	}
+	if len(dumps) <= maxFiles {
+		return
+	}
+
+	sort.Strings(dumps)
+	for _, name := range dumps[:len(dumps)-maxFiles] {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			slog.Error("failed to prune debug dump", "file", name, "err", err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// internal/ingestion/dump_test.go
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDumpResponse(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "dumps")
+	cfg := testConfig()
+	cfg.DebugDumpDir = dir
+	cfg.DebugDumpMaxFiles = 3
+
+	i, _ := newTestIngestion(t, cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(leaderboardPage))
+	}))
+
+	if _, err := i.GetLeaderboardWithParams(context.Background(), "week", "PNL", 20); err != nil {
+		t.Fatalf("GetLeaderboardWithParams: %v", err)
+	}
+	dumps, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(dumps) != 1 {
+		t.Fatalf("%d dumps, want 1", len(dumps))
+	}
+	body, err := os.ReadFile(filepath.Join(dir, dumps[0].Name()))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(body) != leaderboardPage {
+		t.Errorf("dump = %q, want the response body", body)
+	}
+}
+
+func TestPruneDumps(t *testing.T) {
+	dir := t.TempDir()
+	for n := 1; n <= 5; n++ {
+		name := fmt.Sprintf("%s20260101T00000%d.000000000.json", dumpPrefix, n)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	// Files that aren't dumps are left alone
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	pruneDumps(dir, 2)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	want := []string{
+		dumpPrefix + "20260101T000004.000000000.json",
+		dumpPrefix + "20260101T000005.000000000.json",
+		"notes.txt",
+	}
+	if fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Errorf("left %v, want %v", names, want)
+	}
+}
@@ -3,25 +3,49 @@ package ingestion
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"log"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/askwhyharsh/lazytrader/internal/config"
 	"github.com/askwhyharsh/lazytrader/internal/database"
+	"github.com/askwhyharsh/lazytrader/internal/metrics"
+	"github.com/askwhyharsh/lazytrader/internal/polymarket"
 )
 
-const (
-	POLYMARKET_LEADERBOARD_API = "https://data-api.polymarket.com/v1/leaderboard"
-)
+// ErrNonJSONResponse is returned when the API answers with something other
+// than JSON, typically a Cloudflare challenge page when we are being blocked
+var ErrNonJSONResponse = polymarket.ErrNonJSONResponse
+
 type Ingestion struct {
-	cfg            *config.Config
-	db             *database.DB
-	client         *http.Client
-	lastCheckTime  map[string]int64 // Track last check time per trader
+	cfg           *config.Config
+	db            *database.DB
+	client        *polymarket.Client
+	lastCheckTime map[string]int64 // Track last check time per trader
+	metrics       *metrics.Metrics
+
+	// Consecutive refresh cycles that exhausted their retries
+	failedCycles int
+	degraded     atomic.Bool
+
+	// Closed after the initial leaderboard refresh, successful or not
+	ready chan struct{}
+
+	// Per-trader win rates computed from closed positions
+	winRates winRateCache
+
+	// On-demand refreshes, served by the Start loop between ticks
+	refreshRequests chan chan refreshResult
+}
+
+// refreshResult is the outcome of one on-demand leaderboard refresh
+type refreshResult struct {
+	updated int
+	err     error
 }
 
 type LeaderboardEntry struct {
@@ -31,283 +55,337 @@ type LeaderboardEntry struct {
 }
 
 // Polymarket API response structure
-type PolymarketLeaderboardEntry struct {
-	Rank         string  `json:"rank"`
-	ProxyWallet  string  `json:"proxyWallet"`
-	UserName     string  `json:"userName"`
-	Vol          float64 `json:"vol"`
-	PnL          float64 `json:"pnl"`
-	ProfileImage string  `json:"profileImage"`
-}
+type PolymarketLeaderboardEntry = polymarket.LeaderboardEntry
 
 func New(cfg *config.Config, db *database.DB) *Ingestion {
-	return &Ingestion{
-		cfg:           cfg,
-		db:            db,
-		client: &http.Client{
+	i := &Ingestion{
+		cfg: cfg,
+		db:  db,
+		client: polymarket.NewClient(&http.Client{
 			Timeout: 15 * time.Second,
-		},
+		}),
 		lastCheckTime: make(map[string]int64),
+		metrics:       metrics.For(cfg.StrategyID),
+		ready:         make(chan struct{}),
+
+		refreshRequests: make(chan chan refreshResult),
 	}
+	i.client.Retries = cfg.APIRetryAttempts
+	i.client.Backoff = cfg.APIRetryBackoff
+	i.client.OnResponse = func(endpoint string, body []byte) {
+		if endpoint == "/v1/leaderboard" {
+			i.dumpResponse(body)
+		}
+	}
+	return i
 }
 
 func (i *Ingestion) Start(ctx context.Context) error {
-	log.Println("Starting ingestion service with Polymarket Data API...")
+	slog.Info("starting ingestion service with Polymarket Data API")
 
 	// Update top traders leaderboard from Polymarket API
-	leaderboardTicker := time.NewTicker(10 * time.Minute)
+	leaderboardTicker := time.NewTicker(i.cfg.LeaderboardPollInterval)
 	defer leaderboardTicker.Stop()
 
 	// Poll for new trades from top traders (via event listener)
 	// The event listener will handle the actual trade detection
 
 	// Initial leaderboard update
-	if err := i.updateLeaderboardFromAPIMock(ctx); err != nil {
-		log.Printf("Failed initial leaderboard update: %v", err)
+	if _, err := i.refreshLeaderboard(ctx); err != nil {
+		slog.Error("failed initial leaderboard update", "err", err)
 	}
+	close(i.ready)
 
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-leaderboardTicker.C:
-			if err := i.updateLeaderboardFromAPIMock(ctx); err != nil {
-				log.Printf("Failed to update leaderboard: %v", err)
+			if _, err := i.refreshLeaderboard(ctx); err != nil {
+				slog.Error("failed to update leaderboard", "err", err)
+			}
+		case done := <-i.refreshRequests:
+			slog.Info("leaderboard refresh requested")
+			updated, err := i.refreshLeaderboard(ctx)
+			if err != nil {
+				slog.Error("failed to update leaderboard", "err", err)
 			}
+			done <- refreshResult{updated: updated, err: err}
+			leaderboardTicker.Reset(i.cfg.LeaderboardPollInterval)
 		}
 	}
 }
 
-// updateLeaderboardFromAPI fetches top traders from Polymarket Data API
-func (i *Ingestion) updateLeaderboardFromAPI(ctx context.Context) error {
-	log.Println("🔍 Fetching top traders from Polymarket Data API...")
+// Refresh asks the Start loop for an immediate leaderboard refresh from the
+// Data API (updateLeaderboardFromAPI, with the usual retries) and waits for
+// it, returning how many traders were stored. If ctx ends first the
+// refresh still runs to completion in the background.
+func (i *Ingestion) Refresh(ctx context.Context) (int, error) {
+	done := make(chan refreshResult, 1)
+	select {
+	case i.refreshRequests <- done:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+
+	select {
+	case res := <-done:
+		return res.updated, res.err
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
 
-	// Build API URL with parameters
-	// timePeriod: "day", "week", "month"
-	// orderBy: "VOL" (volume) or "PNL" (profit/loss)
-	// category: "overall"
-	timePeriod := "week"
-	orderBy := "PNL" // Order by profit for best traders
-	limit := 20
-	offset := 0
+// Ready is closed once the initial leaderboard refresh has run
+func (i *Ingestion) Ready() <-chan struct{} {
+	return i.ready
+}
 
-	url := fmt.Sprintf("%s?timePeriod=%s&orderBy=%s&limit=%d&offset=%d&category=overall",
-		POLYMARKET_LEADERBOARD_API, timePeriod, orderBy, limit, offset)
+// Degraded reports whether leaderboard refreshes have been failing for
+// several consecutive cycles. The last-known tracked set keeps being served.
+func (i *Ingestion) Degraded() bool {
+	return i.degraded.Load()
+}
 
-	// mock with mock data for now 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+// refreshLeaderboard runs one refresh cycle, retrying with exponential backoff
+// up to the configured budget. Cycles that exhaust their retries count towards
+// the degraded threshold; any success clears it.
+func (i *Ingestion) refreshLeaderboard(ctx context.Context) (int, error) {
+	backoff := i.cfg.LeaderboardRetryBackoff
+
+	var updated int
+	var err error
+	for attempt := 1; attempt <= i.cfg.LeaderboardRetryAttempts; attempt++ {
+		if updated, err = i.updateLeaderboardFromAPI(ctx); err == nil {
+			break
+		}
+		if attempt == i.cfg.LeaderboardRetryAttempts {
+			break
+		}
+
+		slog.Warn("leaderboard refresh attempt failed, retrying",
+			"attempt", attempt, "max_attempts", i.cfg.LeaderboardRetryAttempts, "err", err, "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
 	}
 
-	resp, err := i.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to fetch from Polymarket API: %w", err)
+	if err == nil {
+		if i.degraded.Swap(false) {
+			slog.Info("leaderboard ingestion recovered", "failed_cycles", i.failedCycles)
+			i.metrics.IngestionDegraded.Update(0)
+		}
+		i.failedCycles = 0
+		return updated, nil
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API returned status %d: %s", resp.StatusCode, body)
+	i.failedCycles++
+	if i.failedCycles >= i.cfg.LeaderboardDegradedAfter && !i.degraded.Swap(true) {
+		slog.Error("ALERT: leaderboard ingestion degraded, serving last-known tracked set",
+			"failed_cycles", i.failedCycles)
+		i.metrics.IngestionDegraded.Update(1)
 	}
+	return 0, fmt.Errorf("leaderboard refresh failed after %d attempts: %w", i.cfg.LeaderboardRetryAttempts, err)
+}
 
-	var entries []PolymarketLeaderboardEntry
-	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
+// updateLeaderboardFromAPI fetches top traders from Polymarket Data API and
+// returns how many were stored
+func (i *Ingestion) updateLeaderboardFromAPI(ctx context.Context) (int, error) {
+	slog.Info("fetching top traders from Polymarket Data API")
+
+	// Page until enough traders clear the profit threshold to fill the tracked set
+	entries, err := i.fetchLeaderboard(ctx, polymarket.LeaderboardParams{
+		TimePeriod: i.cfg.LeaderboardTimePeriod,
+		OrderBy:    i.cfg.LeaderboardOrderBy,
+		Limit:      i.cfg.LeaderboardLimit,
+	}, func(entries []PolymarketLeaderboardEntry) bool {
+		qualifying := 0
+		for _, entry := range entries {
+			if entry.PnL >= i.cfg.MinProfitThreshold {
+				qualifying++
+			}
+		}
+		return qualifying >= i.cfg.TopTradersCount
+	})
+	if err != nil {
+		return 0, err
 	}
 
 	if len(entries) == 0 {
-		log.Println("⚠️  No leaderboard entries returned from API")
-		return nil
+		slog.Warn("no leaderboard entries returned from API")
+		return 0, nil
 	}
 
 	// Store top traders in database
-	count := 0
-	for _, entry := range entries {
-		// Filter by minimum profit threshold
-		if entry.PnL >= i.cfg.MinProfitThreshold {
-			// Calculate approximate win rate (we don't have exact data from this API)
-			// For now, assume higher PnL = higher win rate
-			estimatedWinRate := 0.5 + (entry.PnL / (entry.Vol + 1)) * 0.3
-			if estimatedWinRate > 0.9 {
-				estimatedWinRate = 0.9
-			}
+	count := i.storeLeaderboard(ctx, entries)
 
-			if err := i.db.UpsertTopTrader(entry.ProxyWallet, entry.PnL, estimatedWinRate); err != nil {
-				log.Printf("Failed to upsert trader %s: %v", entry.ProxyWallet, err)
-			} else {
-				count++
-				log.Printf("  ✓ Rank #%s: %s - PnL: $%.2f, Vol: $%.2f", 
-					entry.Rank, entry.UserName, entry.PnL, entry.Vol)
-			}
-		} else {
-			log.Printf("  ✗ Rank #%s: %s - PnL: $%.2f (below threshold)", 
-				entry.Rank, entry.UserName, entry.PnL)
-		}
-	}
+	slog.Info("updated leaderboard", "profitable", count, "total", len(entries))
+	i.pruneStaleTraders(ctx, entries)
 
-	log.Printf("✅ Updated leaderboard with %d profitable traders (out of %d total)", count, len(entries))
-	
 	// Log top traders we're tracking
-	topTraders, err := i.db.GetTopTraders(i.cfg.TopTradersCount)
+	topTraders, err := i.db.GetTopTradersByScore(ctx, i.cfg.TopTradersCount, i.scoreWeights())
 	if err == nil && len(topTraders) > 0 {
-		log.Printf("📊 Currently tracking top %d traders:", len(topTraders))
-		for idx, trader := range topTraders {
-			log.Printf("   %d. %s", idx+1, trader)
-		}
+		slog.Info("currently tracking top traders", "count", len(topTraders), "traders", topTraders)
 	}
 
-	return nil
+	return count, nil
 }
-// updateLeaderboardFromAPI fetches top traders from Polymarket Data API
-func (i *Ingestion) updateLeaderboardFromAPIMock(ctx context.Context) error {
-	log.Println("🔍 Fetching top traders from Polymarket Data API...")	
-	// log.Println("⚠️  Using MOCK leaderboard data (hardcoded)")
-	entries := []PolymarketLeaderboardEntry{
-		{
-			Rank:         "1",
-			ProxyWallet:  "0x1ff26f9f8a048d4f6fb2e4283f32f6ca64d2dbbd",
-			UserName:     "0x1ff26F9f8a048d4F6FB2e4283F32f6ca64d2DBBD-1757528275694",
-			Vol:          5838168.843809,
-			PnL:          824775.3512579,
-			ProfileImage: "",
-		},
-		{
-			Rank:         "2",
-			ProxyWallet:  "0x76062e7bbfc0fb783487ff884960c4bc17962836",
-			UserName:     "unpredictable666",
-			Vol:          3659067.912644,
-			PnL:          338912.807525593,
-			ProfileImage: "",
-		},
-		{
-			Rank:         "3",
-			ProxyWallet:  "0x3657862e57070b82a289b5887ec943a7c2166b14",
-			UserName:     "Mayuravarma",
-			Vol:          2700151.206408,
-			PnL:          319270.707733884,
-			ProfileImage: "https://polymarket-upload.s3.us-east-2.amazonaws.com/profile-image-3576478-bc4dca02-880d-45f9-85f7-a831e63cbbd5.jpeg",
-		},
-		{
-			Rank:         "4",
-			ProxyWallet:  "0x42592084120b0d5287059919d2a96b3b7acb936f",
-			UserName:     "antman-batman-superman-lakers-in-5",
-			Vol:          4056904.463206,
-			PnL:          287340.843661496,
-			ProfileImage: "https://polymarket-upload.s3.us-east-2.amazonaws.com/profile-image-2671131-0328059f-d6b6-4775-bd7b-dfb6d737a7b7.png",
-		},
-		{
-			Rank:         "5",
-			ProxyWallet:  "0x900c83447eb74c3f29f17658e848e2715ca41d7a",
-			UserName:     "Finubar",
-			Vol:          2254010.720214,
-			PnL:          239830.693446298,
-			ProfileImage: "",
-		},
-		{
-			Rank:         "6",
-			ProxyWallet:  "0x9b3dcd99eec7fe11602e6534e6302c0f318d7422",
-			UserName:     "unsunghero1990",
-			Vol:          749542.901607,
-			PnL:          211923.90666831,
-			ProfileImage: "",
-		},
-		{
-			Rank:         "7",
-			ProxyWallet:  "0xd38b71f3e8ed1af71983e5c309eac3dfa9b35029",
-			UserName:     "primm",
-			Vol:          810117.04,
-			PnL:          198260.75111339,
-			ProfileImage: "",
-		},
+
+// scoreWeights returns the configured tracked set ranking weights
+func (i *Ingestion) scoreWeights() database.ScoreWeights {
+	return database.ScoreWeights{
+		PnL:         i.cfg.ScoreWeightPnL,
+		WinRate:     i.cfg.ScoreWeightWinRate,
+		Consistency: i.cfg.ScoreWeightConsistency,
 	}
+}
 
-	if len(entries) == 0 {
-		log.Println("⚠️  No leaderboard entries returned from API")
-		return nil
+// Leaderboard decision reasons
+const (
+	ReasonAccepted       = "accepted"
+	ReasonBelowMinPnL    = "below_min_pnl"
+	ReasonInvalidAddress = "invalid_address"
+	ReasonStoreFailed    = "store_failed"
+)
+
+// pruneStaleTraders drops traders that have been off the leaderboard for
+// longer than TraderStaleAfter, keeping ones we hold positions from
+func (i *Ingestion) pruneStaleTraders(ctx context.Context, entries []PolymarketLeaderboardEntry) {
+	seen := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		seen = append(seen, entry.ProxyWallet)
 	}
 
-	// Store top traders in database
+	removed, retained, err := i.db.PruneStaleTopTraders(ctx, seen, i.cfg.TraderStaleAfter)
+	if err != nil {
+		slog.Error("failed to prune stale traders", "err", err)
+		return
+	}
+	for _, address := range removed {
+		slog.Info("trader dropped off the leaderboard, no longer tracked", "trader", address)
+	}
+	for _, address := range retained {
+		slog.Info("trader dropped off the leaderboard, still tracked for open positions", "trader", address)
+	}
+}
+
+// storeLeaderboard filters leaderboard entries, upserts the accepted ones and
+// records every accept/reject decision. It returns the number accepted.
+func (i *Ingestion) storeLeaderboard(ctx context.Context, entries []PolymarketLeaderboardEntry) int {
 	count := 0
 	for _, entry := range entries {
+		// Shutting down, the next refresh stores the rest
+		if ctx.Err() != nil {
+			break
+		}
+		reason := ReasonAccepted
+
 		// Filter by minimum profit threshold
 		if entry.PnL >= i.cfg.MinProfitThreshold {
-			// Calculate approximate win rate (we don't have exact data from this API)
-			// For now, assume higher PnL = higher win rate
-			estimatedWinRate := 0.5 + (entry.PnL / (entry.Vol + 1)) * 0.3
-			if estimatedWinRate > 0.9 {
-				estimatedWinRate = 0.9
+			// The leaderboard has no win rate, it comes from closed positions.
+			// Without one the stored win rate is kept.
+			winRate, ok := i.cachedWinRate(ctx, entry.ProxyWallet)
+			if !ok {
+				winRate = database.KeepWinRate
 			}
 
-			if err := i.db.UpsertTopTrader(entry.ProxyWallet, entry.PnL, estimatedWinRate); err != nil {
-				log.Printf("Failed to upsert trader %s: %v", entry.ProxyWallet, err)
+			rank, _ := strconv.Atoi(entry.Rank)
+			err := i.db.UpsertTopTrader(ctx, database.TopTrader{
+				Address:  entry.ProxyWallet,
+				UserName: entry.UserName,
+				Rank:     rank,
+				PnL:      entry.PnL,
+				Volume:   entry.Vol,
+				WinRate:  winRate,
+			})
+			if err != nil {
+				slog.Error("failed to upsert trader", "trader", entry.ProxyWallet, "err", err)
+				reason = ReasonStoreFailed
+				if errors.Is(err, database.ErrInvalidAddress) {
+					reason = ReasonInvalidAddress
+				}
 			} else {
 				count++
-				log.Printf("  ✓ Rank #%s: %s - PnL: $%.2f, Vol: $%.2f", 
-					entry.Rank, entry.UserName, entry.PnL, entry.Vol)
+				slog.Info("trader accepted", "rank", entry.Rank, "trader", entry.ProxyWallet,
+					"user_name", entry.UserName, "pnl", entry.PnL, "volume", entry.Vol)
 			}
 		} else {
-			log.Printf("  ✗ Rank #%s: %s - PnL: $%.2f (below threshold)", 
-				entry.Rank, entry.UserName, entry.PnL)
+			reason = ReasonBelowMinPnL
+			slog.Info("trader below profit threshold", "rank", entry.Rank, "trader", entry.ProxyWallet,
+				"user_name", entry.UserName, "pnl", entry.PnL)
 		}
-	}
 
-	log.Printf("[] Updated leaderboard with %d profitable traders (out of %d total)", count, len(entries))
-	
-	// Log top traders we're tracking
-	topTraders, err := i.db.GetTopTraders(i.cfg.TopTradersCount)
-	if err == nil && len(topTraders) > 0 {
-		log.Printf("[:] Currently tracking top %d traders:", len(topTraders))
-		for idx, trader := range topTraders {
-			log.Printf("   %d. %s", idx+1, trader)
+		decision := "rejected"
+		if reason == ReasonAccepted {
+			decision = "accepted"
+		}
+		if err := i.db.RecordLeaderboardDecision(ctx, entry.ProxyWallet, entry.PnL, entry.Vol, decision, reason); err != nil {
+			slog.Error("failed to record leaderboard decision", "trader", entry.ProxyWallet, "err", err)
 		}
 	}
-
-	return nil
+	i.metrics.LeaderboardSize.Update(int64(count))
+	return count
 }
-	
-
-// GetLeaderboardWithParams allows custom API parameters
-func (i *Ingestion) GetLeaderboardWithParams(ctx context.Context, timePeriod, orderBy string, limit int) ([]PolymarketLeaderboardEntry, error) {
-	url := fmt.Sprintf("%s?timePeriod=%s&orderBy=%s&limit=%d&offset=0&category=overall",
-		POLYMARKET_LEADERBOARD_API, timePeriod, orderBy, limit)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, err
+// Most leaderboard pages fetched per refresh, however few entries qualify
+const maxLeaderboardPages = 10
+
+// fetchLeaderboard pages through the leaderboard from offset 0, params.Limit
+// entries at a time, until enough reports the collected entries suffice, a
+// page comes back short or maxLeaderboardPages is reached. Each page request
+// goes through the client's retry and backoff.
+func (i *Ingestion) fetchLeaderboard(ctx context.Context, params polymarket.LeaderboardParams, enough func([]PolymarketLeaderboardEntry) bool) ([]PolymarketLeaderboardEntry, error) {
+	var all []PolymarketLeaderboardEntry
+	for page := 0; page < maxLeaderboardPages; page++ {
+		params.Offset = page * params.Limit
+		entries, err := i.client.Leaderboard(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("leaderboard page at offset %d: %w", params.Offset, err)
+		}
+		all = append(all, entries...)
+		if len(entries) < params.Limit || enough(all) {
+			return all, nil
+		}
 	}
+	slog.Warn("stopped paging the leaderboard", "pages", maxLeaderboardPages, "entries", len(all))
+	return all, nil
+}
 
-	resp, err := i.client.Do(req)
+// GetLeaderboardWithParams allows custom API parameters, fetching up to limit
+// entries in pages of the configured leaderboard_limit
+func (i *Ingestion) GetLeaderboardWithParams(ctx context.Context, timePeriod, orderBy string, limit int) ([]PolymarketLeaderboardEntry, error) {
+	entries, err := i.fetchLeaderboard(ctx, polymarket.LeaderboardParams{
+		TimePeriod: timePeriod,
+		OrderBy:    orderBy,
+		Limit:      min(limit, i.cfg.LeaderboardLimit),
+	}, func(entries []PolymarketLeaderboardEntry) bool {
+		return len(entries) >= limit
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
-	}
-
-	var entries []PolymarketLeaderboardEntry
-	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
-		return nil, err
+	if len(entries) > limit {
+		entries = entries[:limit]
 	}
-
 	return entries, nil
 }
 
 // Mock function for testing
-func (i *Ingestion) MockLeaderboard() error {
+func (i *Ingestion) MockLeaderboard(ctx context.Context) error {
 	mockTraders := []LeaderboardEntry{
 		{Address: "0x9c667a1d1c1337c6dca9d93241d386e4ed346b66", PnL: 3868.57, WinRate: 0.65},
 		{Address: "0xa61ef8773ec2e821962306ca87d4b57e39ff0abd", PnL: 3778.41, WinRate: 0.58},
 	}
 
 	for _, entry := range mockTraders {
-		if err := i.db.UpsertTopTrader(entry.Address, entry.PnL, entry.WinRate); err != nil {
+		if err := i.db.UpsertTopTrader(ctx, database.TopTrader{Address: entry.Address, PnL: entry.PnL, WinRate: entry.WinRate}); err != nil {
 			return err
 		}
 	}
 
-	log.Println("Loaded mock leaderboard data")
+	slog.Info("loaded mock leaderboard data")
 	return nil
-}
\ No newline at end of file
+}
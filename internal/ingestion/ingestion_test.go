@@ -0,0 +1,217 @@
+// internal/ingestion/ingestion_test.go
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/askwhyharsh/lazytrader/internal/config"
+	"github.com/askwhyharsh/lazytrader/internal/database"
+)
+
+const leaderboardPage = `[
+	{"rank":"1","proxyWallet":"0x00000000000000000000000000000000000000a1","userName":"one","vol":50000,"pnl":9000},
+	{"rank":"2","proxyWallet":"0x00000000000000000000000000000000000000a2","userName":"two","vol":20000,"pnl":4000},
+	{"rank":"3","proxyWallet":"0x00000000000000000000000000000000000000a3","userName":"three","vol":9000,"pnl":500}
+]`
+
+// testConfig is a config as Load would leave it, with retries kept fast
+func testConfig() *config.Config {
+	return &config.Config{
+		StrategyID:               "default",
+		TopTradersCount:          10,
+		MinProfitThreshold:       1000,
+		LeaderboardTimePeriod:    "week",
+		LeaderboardOrderBy:       "PNL",
+		LeaderboardLimit:         20,
+		LeaderboardPollInterval:  time.Hour,
+		LeaderboardRetryAttempts: 2,
+		LeaderboardRetryBackoff:  time.Millisecond,
+		LeaderboardDegradedAfter: 2,
+		APIRetryAttempts:         1,
+		APIRetryBackoff:          time.Millisecond,
+		TraderStaleAfter:         24 * time.Hour,
+		WinRateCacheTTL:          time.Hour,
+		ScoreWeightPnL:           1,
+	}
+}
+
+// newTestIngestion returns an Ingestion on a fresh database whose Data API
+// requests go to handler
+func newTestIngestion(t *testing.T, cfg *config.Config, handler http.Handler) (*Ingestion, *database.DB) {
+	t.Helper()
+	db, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("database.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	i := New(cfg, db)
+	i.client.WithBaseURL(srv.URL)
+	return i, db
+}
+
+// flakyAPI serves the leaderboard while up is set and 503s otherwise.
+// Closed positions are always empty.
+type flakyAPI struct {
+	up       atomic.Bool
+	requests atomic.Int32
+}
+
+func (a *flakyAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Path != "/v1/leaderboard" {
+		w.Write([]byte("[]"))
+		return
+	}
+	a.requests.Add(1)
+	if !a.up.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error":"unavailable"}`))
+		return
+	}
+	w.Write([]byte(leaderboardPage))
+}
+
+func TestRefreshDegradedAndRecovers(t *testing.T) {
+	ctx := context.Background()
+	api := &flakyAPI{}
+	api.up.Store(true)
+	i, db := newTestIngestion(t, testConfig(), api)
+
+	if updated, err := i.refreshLeaderboard(ctx); err != nil || updated != 2 {
+		t.Fatalf("first refresh = %d, %v, want 2 traders", updated, err)
+	}
+
+	// The API goes down: each cycle uses its whole retry budget, and the
+	// second failed cycle trips the degraded alert
+	api.up.Store(false)
+	api.requests.Store(0)
+	for cycle := 1; cycle <= 2; cycle++ {
+		if _, err := i.refreshLeaderboard(ctx); err == nil {
+			t.Fatalf("cycle %d succeeded against a failing API", cycle)
+		}
+		if got, want := i.Degraded(), cycle >= 2; got != want {
+			t.Errorf("after %d failed cycles Degraded() = %v, want %v", cycle, got, want)
+		}
+	}
+	if got := api.requests.Load(); got != 4 {
+		t.Errorf("leaderboard requests over 2 cycles = %d, want 2 attempts each", got)
+	}
+	if got := i.metrics.IngestionDegraded.Snapshot().Value(); got != 1 {
+		t.Errorf("ingestion_degraded = %d, want 1", got)
+	}
+
+	// The last-known tracked set is still served
+	traders, err := db.GetTopTraders(ctx, 10)
+	if err != nil || len(traders) != 2 {
+		t.Errorf("tracked traders while degraded = %v, %v, want the 2 from before", traders, err)
+	}
+
+	api.up.Store(true)
+	if _, err := i.refreshLeaderboard(ctx); err != nil {
+		t.Fatalf("refresh after recovery: %v", err)
+	}
+	if i.Degraded() {
+		t.Error("still degraded after a successful refresh")
+	}
+	if got := i.metrics.IngestionDegraded.Snapshot().Value(); got != 0 {
+		t.Errorf("ingestion_degraded = %d after recovery, want 0", got)
+	}
+}
+
+func TestRefreshRetriesWithinCycle(t *testing.T) {
+	api := &flakyAPI{}
+	i, _ := newTestIngestion(t, testConfig(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fails the first attempt only
+		if r.URL.Path == "/v1/leaderboard" && api.requests.Load() == 1 {
+			api.up.Store(true)
+		}
+		api.ServeHTTP(w, r)
+	}))
+
+	if updated, err := i.refreshLeaderboard(context.Background()); err != nil || updated != 2 {
+		t.Errorf("refresh = %d, %v, want success on the retry", updated, err)
+	}
+	if i.Degraded() || i.failedCycles != 0 {
+		t.Errorf("Degraded() = %v, failed cycles %d after a retried success", i.Degraded(), i.failedCycles)
+	}
+}
+
+func TestLeaderboardNonJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+	}{
+		{"challenge page claiming json", http.StatusOK, "application/json"},
+		{"forbidden challenge page", http.StatusForbidden, "text/html; charset=UTF-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i, _ := newTestIngestion(t, testConfig(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+				w.Write([]byte("<!DOCTYPE html><html><title>Just a moment...</title></html>"))
+			}))
+
+			_, err := i.updateLeaderboardFromAPI(context.Background())
+			if !errors.Is(err, ErrNonJSONResponse) {
+				t.Errorf("err = %v, want ErrNonJSONResponse", err)
+			}
+		})
+	}
+}
+
+func TestStoreLeaderboardDecisions(t *testing.T) {
+	ctx := context.Background()
+	i, db := newTestIngestion(t, testConfig(), &flakyAPI{})
+
+	entries := []PolymarketLeaderboardEntry{
+		{Rank: "1", ProxyWallet: "0x00000000000000000000000000000000000000A1", PnL: 5000, Vol: 10000},
+		{Rank: "2", ProxyWallet: "0x00000000000000000000000000000000000000a2", PnL: 999, Vol: 8000},
+		{Rank: "3", ProxyWallet: "not-an-address", PnL: 4000, Vol: 6000},
+	}
+	if accepted := i.storeLeaderboard(ctx, entries); accepted != 1 {
+		t.Errorf("storeLeaderboard accepted %d, want 1", accepted)
+	}
+
+	decisions, err := db.GetLeaderboardDecisions(ctx, "", 10)
+	if err != nil {
+		t.Fatalf("GetLeaderboardDecisions: %v", err)
+	}
+	want := map[string]struct{ decision, reason string }{
+		"0x00000000000000000000000000000000000000a1": {"accepted", ReasonAccepted},
+		"0x00000000000000000000000000000000000000a2": {"rejected", ReasonBelowMinPnL},
+		"not-an-address": {"rejected", ReasonInvalidAddress},
+	}
+	if len(decisions) != len(want) {
+		t.Fatalf("%d decisions recorded, want %d", len(decisions), len(want))
+	}
+	for _, d := range decisions {
+		w, ok := want[d.Address]
+		if !ok {
+			t.Errorf("unexpected decision for %s", d.Address)
+			continue
+		}
+		if d.Decision != w.decision || d.Reason != w.reason {
+			t.Errorf("%s: decision %s (%s), want %s (%s)", d.Address, d.Decision, d.Reason, w.decision, w.reason)
+		}
+	}
+
+	// Decisions can be looked up by address in any casing
+	mine, err := db.GetLeaderboardDecisions(ctx, "0x00000000000000000000000000000000000000A2", 10)
+	if err != nil || len(mine) != 1 || mine[0].PnL != 999 || mine[0].Volume != 8000 {
+		t.Errorf("decisions for a2 = %+v, %v", mine, err)
+	}
+}
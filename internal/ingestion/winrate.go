@@ -0,0 +1,144 @@
+// internal/ingestion/winrate.go
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Spacing between per-trader Data API calls when recomputing win rates
+const winRateRequestInterval = 500 * time.Millisecond
+
+// Most recent closed positions a win rate is computed over
+const winRateSampleSize = 500
+
+type cachedWinRate struct {
+	winRate   float64
+	fetchedAt time.Time
+}
+
+// winRateCache holds computed win rates per trader for WinRateCacheTTL, so
+// each leaderboard refresh doesn't re-fetch every trader's history
+type winRateCache struct {
+	mu        sync.Mutex
+	rates     map[string]cachedWinRate
+	lastFetch time.Time
+}
+
+// FetchWinRate computes a trader's win rate from their closed Polymarket
+// positions: the share that closed with a realized profit, among those that
+// made or lost anything
+func (i *Ingestion) FetchWinRate(ctx context.Context, address string) (float64, error) {
+	positions, err := i.client.ClosedPositions(ctx, address, winRateSampleSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch closed positions for %s: %w", address, err)
+	}
+
+	var wins, decided int
+	for _, p := range positions {
+		if p.RealizedPnL == 0 {
+			continue
+		}
+		decided++
+		if p.RealizedPnL > 0 {
+			wins++
+		}
+	}
+
+	winRate := 0.0
+	if decided > 0 {
+		winRate = float64(wins) / float64(decided)
+	}
+	i.winRates.store(address, winRate)
+	return winRate, nil
+}
+
+// cachedWinRate returns a trader's win rate, fetching it when the cached one
+// is missing or older than WinRateCacheTTL. Fetches are spaced at least
+// winRateRequestInterval apart. If a fetch fails the stale value is used;
+// ok is false when there's none.
+func (i *Ingestion) cachedWinRate(ctx context.Context, address string) (winRate float64, ok bool) {
+	cached, found := i.winRates.get(address)
+	if found && time.Since(cached.fetchedAt) < i.cfg.WinRateCacheTTL {
+		return cached.winRate, true
+	}
+
+	if err := i.winRates.wait(ctx); err != nil {
+		return cached.winRate, found
+	}
+	winRate, err := i.FetchWinRate(ctx, address)
+	if err != nil {
+		slog.Error("failed to fetch win rate", "trader", address, "err", err)
+		return cached.winRate, found
+	}
+	return winRate, true
+}
+
+func (c *winRateCache) get(address string) (cachedWinRate, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.rates[address]
+	return cached, ok
+}
+
+func (c *winRateCache) store(address string, winRate float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rates == nil {
+		c.rates = make(map[string]cachedWinRate)
+	}
+	c.rates[address] = cachedWinRate{winRate: winRate, fetchedAt: time.Now()}
+}
+
+// wait blocks until winRateRequestInterval has passed since the last fetch
+func (c *winRateCache) wait(ctx context.Context) error {
+	c.mu.Lock()
+	delay := time.Until(c.lastFetch.Add(winRateRequestInterval))
+	c.lastFetch = time.Now().Add(max(delay, 0))
+	c.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+// RecomputeWinRates re-derives the win rate of every tracked trader,
+// rate-limited, and returns how many were updated. It stops early with
+// ctx's error when cancelled.
+func (i *Ingestion) RecomputeWinRates(ctx context.Context) (int, error) {
+	// SQLite treats a negative LIMIT as no limit
+	traders, err := i.db.GetTopTraders(ctx, -1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get tracked traders: %w", err)
+	}
+
+	updated := 0
+	for _, address := range traders {
+		if err := i.winRates.wait(ctx); err != nil {
+			return updated, err
+		}
+
+		winRate, err := i.FetchWinRate(ctx, address)
+		if err != nil {
+			slog.Error("failed to recompute win rate", "trader", address, "err", err)
+			continue
+		}
+		if err := i.db.UpdateTopTraderWinRate(ctx, address, winRate); err != nil {
+			slog.Error("failed to store win rate", "trader", address, "err", err)
+			continue
+		}
+		updated++
+	}
+
+	slog.Info("recomputed win rates", "updated", updated, "tracked", len(traders))
+	return updated, nil
+}
@@ -0,0 +1,124 @@
+// internal/ingestion/winrate_test.go
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/askwhyharsh/lazytrader/internal/database"
+)
+
+const (
+	winRateTrader1 = "0x00000000000000000000000000000000000000a1"
+	winRateTrader2 = "0x00000000000000000000000000000000000000a2"
+)
+
+// closedPositionsAPI serves canned closed positions per trader and counts
+// the requests for each
+type closedPositionsAPI struct {
+	positions map[string]string
+	onRequest func(user string)
+
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+func (a *closedPositionsAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user := r.URL.Query().Get("user")
+	a.mu.Lock()
+	if a.requests == nil {
+		a.requests = make(map[string]int)
+	}
+	a.requests[user]++
+	a.mu.Unlock()
+	if a.onRequest != nil {
+		a.onRequest(user)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	body, ok := a.positions[user]
+	if r.URL.Path != "/closed-positions" || !ok {
+		body = "[]"
+	}
+	w.Write([]byte(body))
+}
+
+func (a *closedPositionsAPI) requested(user string) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.requests[user]
+}
+
+// seedWinRates tracks both test traders with a stale 10% win rate
+func seedWinRates(t *testing.T, db *database.DB) {
+	t.Helper()
+	for rank, address := range []string{winRateTrader1, winRateTrader2} {
+		trader := database.TopTrader{Address: address, Rank: rank + 1, PnL: 5000, WinRate: 0.1}
+		if err := db.UpsertTopTrader(context.Background(), trader); err != nil {
+			t.Fatalf("UpsertTopTrader: %v", err)
+		}
+	}
+}
+
+// storedWinRates returns the stored win rate of each tracked trader
+func storedWinRates(t *testing.T, db *database.DB) map[string]float64 {
+	t.Helper()
+	traders, err := db.GetTopTradersDetailed(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("GetTopTradersDetailed: %v", err)
+	}
+	rates := make(map[string]float64)
+	for _, tr := range traders {
+		rates[tr.Address] = tr.WinRate
+	}
+	return rates
+}
+
+func TestRecomputeWinRates(t *testing.T) {
+	api := &closedPositionsAPI{positions: map[string]string{
+		// 3 wins and a loss; the break-even position doesn't count
+		winRateTrader1: `[{"realizedPnl":10},{"realizedPnl":5},{"realizedPnl":1},{"realizedPnl":-4},{"realizedPnl":0}]`,
+		winRateTrader2: `[{"realizedPnl":10},{"realizedPnl":-10}]`,
+	}}
+	i, db := newTestIngestion(t, testConfig(), api)
+	seedWinRates(t, db)
+
+	updated, err := i.RecomputeWinRates(context.Background())
+	if err != nil || updated != 2 {
+		t.Fatalf("RecomputeWinRates = %d, %v, want 2 updated", updated, err)
+	}
+
+	rates := storedWinRates(t, db)
+	if rates[winRateTrader1] != 0.75 || rates[winRateTrader2] != 0.5 {
+		t.Errorf("win rates = %v, want 0.75 and 0.5", rates)
+	}
+}
+
+func TestRecomputeWinRatesCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	api := &closedPositionsAPI{
+		positions: map[string]string{winRateTrader1: `[{"realizedPnl":10}]`, winRateTrader2: `[{"realizedPnl":10}]`},
+		// The caller goes away while the first trader is being fetched
+		onRequest: func(string) { cancel() },
+	}
+	i, db := newTestIngestion(t, testConfig(), api)
+	seedWinRates(t, db)
+
+	updated, err := i.RecomputeWinRates(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("RecomputeWinRates err = %v, want context.Canceled", err)
+	}
+	if updated > 1 {
+		t.Errorf("%d traders updated after cancellation, want at most 1", updated)
+	}
+	if n := api.requested(winRateTrader2); n != 0 {
+		t.Errorf("second trader fetched %d times after cancellation", n)
+	}
+	if rate := storedWinRates(t, db)[winRateTrader2]; rate != 0.1 {
+		t.Errorf("second trader's win rate = %v, want it left at 0.1", rate)
+	}
+}
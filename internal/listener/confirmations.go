@@ -0,0 +1,89 @@
+// internal/listener/confirmations.go
+package listener
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// How often unconfirmed signals are re-checked, and how many per pass
+const (
+	confirmInterval  = 5 * time.Second
+	confirmBatchSize = 100
+)
+
+// confirmSignals periodically confirms or discards unconfirmed signals until
+// ctx is cancelled
+func (l *PolymarketListener) confirmSignals(ctx context.Context) {
+	ticker := time.NewTicker(confirmInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.confirmDeepSignals(ctx)
+		}
+	}
+}
+
+// confirmDeepSignals re-checks the signals whose block now has
+// ConfirmationBlocks confirmations. A fill still on the canonical chain that
+// deep becomes pending for the executor; one whose transaction is gone or
+// reverted was reorged out and is skipped.
+func (l *PolymarketListener) confirmDeepSignals(ctx context.Context) {
+	head := l.chainHead.Load()
+	depth := uint64(l.cfg.ConfirmationBlocks)
+	if head+1 < depth {
+		return
+	}
+	deepest := head + 1 - depth
+
+	signals, err := l.db.GetUnconfirmedSignals(ctx, deepest, confirmBatchSize)
+	if err != nil {
+		slog.Error("failed to get unconfirmed signals", "err", err)
+		return
+	}
+
+	for _, sig := range signals {
+		receipt, err := l.rpc().TransactionReceipt(ctx, common.HexToHash(sig.TxHash))
+		switch {
+		case errors.Is(err, ethereum.NotFound):
+			slog.Warn("signal's fill was reorged out, discarding", "trader", sig.Trader, "tx_hash", sig.TxHash,
+				"block_number", sig.BlockNumber)
+			if err := l.db.DiscardSignal(ctx, sig.ID, "reorged"); err != nil {
+				slog.Error("failed to discard signal", "signal_id", sig.ID, "err", err)
+			}
+			continue
+		case err != nil:
+			// Retried on the next pass
+			slog.Error("failed to fetch receipt for signal", "tx_hash", sig.TxHash, "err", err)
+			return
+		case receipt.Status != types.ReceiptStatusSuccessful:
+			slog.Warn("signal's transaction reverted after a reorg, discarding", "trader", sig.Trader,
+				"tx_hash", sig.TxHash, "block_number", receipt.BlockNumber)
+			if err := l.db.DiscardSignal(ctx, sig.ID, "reverted"); err != nil {
+				slog.Error("failed to discard signal", "signal_id", sig.ID, "err", err)
+			}
+			continue
+		case receipt.BlockNumber.Uint64() > deepest:
+			// Reorged into a later block, which isn't deep enough yet
+			slog.Info("signal's fill moved to a later block, waiting for confirmations", "tx_hash", sig.TxHash,
+				"from_block", sig.BlockNumber, "block_number", receipt.BlockNumber)
+			continue
+		}
+
+		if err := l.db.ConfirmSignal(ctx, sig.ID); err != nil {
+			slog.Error("failed to confirm signal", "signal_id", sig.ID, "err", err)
+			continue
+		}
+		slog.Debug("signal confirmed", "tx_hash", sig.TxHash, "block_number", receipt.BlockNumber, "confirmations", head-receipt.BlockNumber.Uint64()+1)
+	}
+}
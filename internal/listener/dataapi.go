@@ -0,0 +1,196 @@
+// internal/listener/dataapi.go
+package listener
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/askwhyharsh/lazytrader/internal/config"
+	"github.com/askwhyharsh/lazytrader/internal/database"
+	"github.com/askwhyharsh/lazytrader/internal/events"
+	"github.com/askwhyharsh/lazytrader/internal/metrics"
+	"github.com/askwhyharsh/lazytrader/internal/polymarket"
+)
+
+// Trades fetched per trader per poll
+const dataAPITradeLimit = 50
+
+// Data API fills have no log index. Grouped fills get a synthetic one above
+// anything a real block produces so they can't collide with on-chain rows.
+const dataAPILogIndexBase = 1 << 30
+
+// DataAPIPoller derives copy signals from tracked traders' trade history on
+// the Data API. It's a cheaper alternative to watching chain events and
+// feeds the same signals table.
+type DataAPIPoller struct {
+	cfg     *config.Config
+	db      *database.DB
+	bus     *events.Bus
+	client  *polymarket.Client
+	metrics *metrics.Metrics
+
+	// Newest trade timestamp seen per trader; older trades are ignored
+	since map[string]int64
+	start int64
+}
+
+func NewDataAPIPoller(cfg *config.Config, db *database.DB, bus *events.Bus) *DataAPIPoller {
+	client := polymarket.NewClient(&http.Client{
+		Timeout: 15 * time.Second,
+	})
+	client.Retries = cfg.APIRetryAttempts
+	client.Backoff = cfg.APIRetryBackoff
+
+	return &DataAPIPoller{
+		cfg:     cfg,
+		db:      db,
+		bus:     bus,
+		client:  client,
+		metrics: metrics.For(cfg.StrategyID),
+		since:   make(map[string]int64),
+	}
+}
+
+func (p *DataAPIPoller) Start(ctx context.Context) error {
+	slog.Info("starting Data API signal poller")
+
+	// Only trades made after startup are copied
+	p.start = time.Now().Unix()
+
+	ticker := time.NewTicker(p.cfg.DataAPIPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			p.poll(ctx)
+		}
+	}
+}
+
+// poll fetches recent trades for every tracked trader
+func (p *DataAPIPoller) poll(ctx context.Context) {
+	traders, err := p.db.GetTopTradersByScore(ctx, p.cfg.TopTradersCount, database.ScoreWeights{
+		PnL:         p.cfg.ScoreWeightPnL,
+		WinRate:     p.cfg.ScoreWeightWinRate,
+		Consistency: p.cfg.ScoreWeightConsistency,
+	})
+	if err != nil {
+		slog.Error("failed to get top traders", "err", err)
+		return
+	}
+
+	for _, trader := range traders {
+		if ctx.Err() != nil {
+			return
+		}
+		trades, err := p.client.TraderTrades(ctx, trader, dataAPITradeLimit)
+		if err != nil {
+			slog.Error("failed to fetch trades", "trader", trader, "err", err)
+			continue
+		}
+		for _, sig := range p.newSignals(trader, trades) {
+			if err := p.store(ctx, sig); err != nil {
+				slog.Error("failed to store Data API signal", "tx_hash", sig.TxHash, "err", err)
+			}
+		}
+	}
+}
+
+// newSignals turns trades newer than the last poll into signals. Fills of the
+// same token and side in one transaction are combined, matching what the
+// on-chain listener stores for partial fills.
+func (p *DataAPIPoller) newSignals(trader string, trades []polymarket.Trade) []*database.Signal {
+	since, ok := p.since[trader]
+	if !ok {
+		since = p.start
+	}
+
+	type group struct {
+		sig      *database.Signal
+		size     float64
+		notional float64
+	}
+	groups := make(map[string]*group)
+	var keys []string
+	newest := since
+	for _, t := range trades {
+		if t.Timestamp <= since || t.TransactionHash == "" || t.Size <= 0 {
+			continue
+		}
+		if t.Timestamp > newest {
+			newest = t.Timestamp
+		}
+
+		side := strings.ToUpper(t.Side)
+		key := t.TransactionHash + "|" + t.Asset + "|" + side
+		g, ok := groups[key]
+		if !ok {
+			g = &group{sig: &database.Signal{
+				Trader:   trader,
+				Side:     side,
+				MarketID: t.ConditionID,
+				TokenID:  t.Asset,
+				TxHash:   t.TransactionHash,
+			}}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.size += t.Size
+		g.notional += t.Size * t.Price
+	}
+	p.since[trader] = newest
+
+	// Deterministic log indexes: fills within a tx in key order
+	sort.Strings(keys)
+	signals := make([]*database.Signal, 0, len(keys))
+	perTx := make(map[string]uint)
+	for _, key := range keys {
+		g := groups[key]
+		if p.cfg.MinSignalNotionalUSDC > 0 && g.notional < p.cfg.MinSignalNotionalUSDC {
+			slog.Debug("skipping Data API signal below minimum notional", "trader", trader, "side", g.sig.Side,
+				"tx_hash", g.sig.TxHash, "notional", g.notional, "min_notional", p.cfg.MinSignalNotionalUSDC)
+			continue
+		}
+		g.sig.Amount = toBaseUnits(g.size)
+		if g.notional > 0 {
+			g.sig.Price = toBaseUnits(g.notional / g.size)
+		}
+		g.sig.LogIndex = dataAPILogIndexBase + perTx[g.sig.TxHash]
+		perTx[g.sig.TxHash]++
+		signals = append(signals, g.sig)
+	}
+	return signals
+}
+
+// store writes a signal, deduping against on-chain signals for the same fill
+func (p *DataAPIPoller) store(ctx context.Context, sig *database.Signal) error {
+	slog.Info("storing Data API trade signal", "trader", sig.Trader, "side", sig.Side,
+		"token_id", sig.TokenID, "amount", sig.Amount, "tx_hash", sig.TxHash)
+
+	stored, inserted, err := p.db.CreateSignalOnce(ctx, sig)
+	if err != nil {
+		return err
+	}
+	if !inserted {
+		slog.Debug("signal already stored, skipping side effects", "tx_hash", sig.TxHash)
+		return nil
+	}
+	p.metrics.SignalsDetected.Inc(1)
+	p.bus.Publish(events.SignalDetected, *stored)
+	return nil
+}
+
+// toBaseUnits converts a human amount to the 6-decimal integer string used
+// for on-chain signals
+func toBaseUnits(v float64) string {
+	return fmt.Sprintf("%.0f", math.Round(v*1e6))
+}
@@ -0,0 +1,143 @@
+// internal/listener/dataapi_test.go
+package listener
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/askwhyharsh/lazytrader/internal/database"
+	"github.com/askwhyharsh/lazytrader/internal/events"
+	"github.com/askwhyharsh/lazytrader/internal/polymarket"
+)
+
+// activityFixture is a tracked trader's /trades response, newest first
+const activityFixture = `[
+	{"proxyWallet":"0x00000000000000000000000000000000000000aa","side":"BUY","asset":"111","conditionId":"0xm1","size":60,"price":0.5,"timestamp":1100,"transactionHash":"0x01"},
+	{"proxyWallet":"0x00000000000000000000000000000000000000aa","side":"BUY","asset":"111","conditionId":"0xm1","size":40,"price":0.6,"timestamp":1100,"transactionHash":"0x01"},
+	{"proxyWallet":"0x00000000000000000000000000000000000000aa","side":"SELL","asset":"222","conditionId":"0xm2","size":10,"price":0.3,"timestamp":1050,"transactionHash":"0x02"},
+	{"proxyWallet":"0x00000000000000000000000000000000000000aa","side":"BUY","asset":"333","conditionId":"0xm3","size":1,"price":0.1,"timestamp":1040,"transactionHash":"0x04"},
+	{"proxyWallet":"0x00000000000000000000000000000000000000aa","side":"BUY","asset":"444","conditionId":"0xm4","size":5,"price":0.5,"timestamp":1030,"transactionHash":""},
+	{"proxyWallet":"0x00000000000000000000000000000000000000aa","side":"BUY","asset":"555","conditionId":"0xm5","size":50,"price":0.5,"timestamp":900,"transactionHash":"0x03"}
+]`
+
+// newTestPoller returns a poller tracking testMaker against a Data API stub
+// serving activityFixture, copying trades made after timestamp 1000
+func newTestPoller(t *testing.T) (*DataAPIPoller, *database.DB) {
+	t.Helper()
+	db, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("database.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.UpsertTopTrader(context.Background(), database.TopTrader{Address: testMaker.Hex(), Rank: 1}); err != nil {
+		t.Fatalf("UpsertTopTrader: %v", err)
+	}
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/trades" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(activityFixture))
+	}))
+	t.Cleanup(api.Close)
+
+	cfg := testListenerConfig()
+	cfg.TopTradersCount = 10
+	cfg.MinSignalNotionalUSDC = 1
+	p := NewDataAPIPoller(cfg, db, events.New())
+	p.client = polymarket.NewClient(api.Client()).WithBaseURL(api.URL)
+	p.client.Retries = 1
+	p.start = 1000
+	return p, db
+}
+
+func TestDataAPIPollerDerivesSignals(t *testing.T) {
+	ctx := context.Background()
+	p, db := newTestPoller(t)
+	detected, unsubscribe := p.bus.Subscribe(events.SignalDetected, 10)
+	defer unsubscribe()
+
+	p.poll(ctx)
+
+	// The two partial fills in 0x01 are combined; the tiny fill, the one
+	// without a tx hash and the one from before startup are dropped
+	signals, err := db.GetSignalHistory(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetSignalHistory: %v", err)
+	}
+	byTx := make(map[string]database.Signal)
+	for _, sig := range signals {
+		byTx[sig.TxHash] = sig
+	}
+	if len(signals) != 2 {
+		t.Fatalf("%d signals stored, want 2: %+v", len(signals), signals)
+	}
+
+	tests := []struct {
+		txHash, side, tokenID, marketID, amount, price string
+	}{
+		{"0x01", "BUY", "111", "0xm1", "100000000", "540000"}, // 100 tokens at a 0.54 VWAP
+		{"0x02", "SELL", "222", "0xm2", "10000000", "300000"},
+	}
+	for _, tt := range tests {
+		sig, ok := byTx[tt.txHash]
+		if !ok {
+			t.Errorf("no signal for %s", tt.txHash)
+			continue
+		}
+		if sig.Trader != "0x00000000000000000000000000000000000000aa" || sig.Side != tt.side ||
+			sig.TokenID != tt.tokenID || sig.MarketID != tt.marketID {
+			t.Errorf("%s: signal = %+v", tt.txHash, sig)
+		}
+		if sig.Amount != tt.amount || sig.Price != tt.price {
+			t.Errorf("%s: amount %s price %s, want %s and %s", tt.txHash, sig.Amount, sig.Price, tt.amount, tt.price)
+		}
+		if sig.LogIndex < dataAPILogIndexBase {
+			t.Errorf("%s: log index %d could collide with an on-chain log", tt.txHash, sig.LogIndex)
+		}
+	}
+	if n := len(detected); n != 2 {
+		t.Errorf("%d SignalDetected events, want 2", n)
+	}
+
+	// The next poll sees the same trades and stores nothing new
+	p.poll(ctx)
+	if n := countSignals(t, db); n != 2 {
+		t.Errorf("%d signals after a second poll, want 2", n)
+	}
+	if n := len(detected); n != 2 {
+		t.Errorf("%d SignalDetected events after a second poll, want 2", n)
+	}
+}
+
+func TestDataAPIPollerDedupsOnChainSignal(t *testing.T) {
+	ctx := context.Background()
+	p, db := newTestPoller(t)
+
+	// The listener already stored the 0x02 sell from its log
+	onChain := &database.Signal{Trader: testMaker.Hex(), Side: "SELL", MarketID: "0xm2", TokenID: "222",
+		Amount: "10000000", Price: "300000", TxHash: "0x02", BlockNumber: 50, LogIndex: 3}
+	if _, _, err := db.CreateSignal(ctx, onChain); err != nil {
+		t.Fatalf("CreateSignal: %v", err)
+	}
+
+	p.poll(ctx)
+
+	signals, err := db.GetSignalHistory(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetSignalHistory: %v", err)
+	}
+	if len(signals) != 2 {
+		t.Fatalf("%d signals stored, want the on-chain one and 0x01", len(signals))
+	}
+	for _, sig := range signals {
+		if sig.TxHash == "0x02" && sig.LogIndex != 3 {
+			t.Errorf("0x02 stored again by the poller with log index %d", sig.LogIndex)
+		}
+	}
+}
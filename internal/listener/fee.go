@@ -0,0 +1,31 @@
+// internal/listener/fee.go
+package listener
+
+import (
+	"math/big"
+
+	"github.com/askwhyharsh/lazytrader/internal/units"
+)
+
+// FeeUSDC converts the raw OrderFilled fee to a human USDC amount signed from
+// the tracked trader's point of view.
+//
+// The exchange charges the fee to the maker of the filled order (the event's
+// Maker). Amounts use 6 decimals, the same as USDC and the outcome tokens.
+// Sign convention:
+//   - negative: the tracked trader was the maker and paid the fee
+//   - positive: the counterparty paid the fee, it costs the trader nothing
+//
+// Conversion goes through units.ToFloat, so uint256 values never wrap the way
+// Int64() would; values beyond float64's exact integer range are logged.
+func FeeUSDC(fee *big.Int, traderIsMaker bool) float64 {
+	if fee == nil || fee.Sign() == 0 {
+		return 0
+	}
+
+	amount := units.ToFloat(fee, units.Decimals)
+	if traderIsMaker {
+		return -amount
+	}
+	return amount
+}
@@ -0,0 +1,38 @@
+// internal/listener/fee_test.go
+package listener
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestFeeUSDC(t *testing.T) {
+	// 2^255, far past int64: Int64() would wrap it
+	huge := new(big.Int).Lsh(big.NewInt(1), 255)
+
+	tests := []struct {
+		name          string
+		fee           *big.Int
+		traderIsMaker bool
+		want          float64
+	}{
+		{"no fee", nil, true, 0},
+		{"zero fee", big.NewInt(0), true, 0},
+		{"maker pays", big.NewInt(1_500_000), true, -1.5},
+		{"counterparty pays", big.NewInt(1_500_000), false, 1.5},
+		{"sub-cent fee", big.NewInt(2_500), true, -0.0025},
+		{"one base unit", big.NewInt(1), false, 0.000001},
+		{"uint256 beyond int64 as maker", huge, true, -math.Ldexp(1, 255) / 1e6},
+		{"uint256 beyond int64 as taker", huge, false, math.Ldexp(1, 255) / 1e6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FeeUSDC(tt.fee, tt.traderIsMaker)
+			if math.Abs(got-tt.want) > math.Abs(tt.want)*1e-12 {
+				t.Errorf("FeeUSDC = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,9 +4,11 @@ package listener
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"math/big"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -15,9 +17,13 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
-	
+
 	"github.com/askwhyharsh/lazytrader/internal/config"
 	"github.com/askwhyharsh/lazytrader/internal/database"
+	"github.com/askwhyharsh/lazytrader/internal/events"
+	"github.com/askwhyharsh/lazytrader/internal/metrics"
+	"github.com/askwhyharsh/lazytrader/internal/polygon"
+	"github.com/askwhyharsh/lazytrader/internal/units"
 )
 
 // Polymarket contract addresses on Polygon
@@ -28,135 +34,427 @@ const (
 	USDC_ADDR              = "0x2791bca1f2de4661ed88a30c99a7a9449aa84174" // USDC.e on Polygon
 )
 
+// Delay before reconnecting a dropped head subscription, doubled per failure
+const (
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = 60 * time.Second
+)
+
 type PolymarketListener struct {
-	cfg       *config.Config
-	db        *database.DB
-	client    *ethclient.Client
-	
+	cfg     *config.Config
+	db      *database.DB
+	client  *polygon.Client // Fails over to the next endpoint on reconnect
+	bus     *events.Bus
+	metrics *metrics.Metrics
+
 	// Contract ABIs
 	exchangeABI abi.ABI
-	
+
 	// Event signatures
-	orderFilledSig common.Hash
+	orderFilledSig   common.Hash
 	ordersMatchedSig common.Hash
-	
-	// Tracked traders
-	topTraders map[string]bool
+
+	// Tracked traders, lowercased. Replaced wholesale on refresh while
+	// blocks are being processed.
+	topTraders atomic.Pointer[map[string]bool]
+
+	// Blocks dropped because the work queue was full, backfilled later
+	missedMu   sync.Mutex
+	missedFrom uint64
+	missedTo   uint64
+
+	// Latest head seen on the subscription, and whether a backfill is running
+	chainHead   atomic.Uint64
+	backfilling atomic.Bool
+
+	// Host clock minus the latest block timestamp, and whether it's over MaxClockSkew
+	clockSkew   atomic.Int64
+	skewAlerted atomic.Bool
+	now         func() time.Time
+
+	// Reports of past backfills, for reconciliation after downtime
+	recoveries recoveryLog
+
+	// Fills from untracked traders evaluated, for log sampling
+	skippedFills atomic.Uint64
+
+	// Closed once the head subscription is established
+	ready     chan struct{}
+	readyOnce sync.Once
+}
+
+// SyncStatus reports how far the listener is behind the chain
+type SyncStatus struct {
+	LastProcessedBlock uint64     `json:"last_processed_block"`
+	ChainHead          uint64     `json:"chain_head"`
+	Lag                uint64     `json:"lag"`
+	LastProcessedAt    *time.Time `json:"last_processed_at,omitempty"`
+	Backfilling        bool       `json:"backfilling"`
+	ClockSkewSeconds   float64    `json:"clock_skew_seconds"` // Host clock minus latest block time
 }
 
 // OrderFilledEvent represents the OrderFilled event from CTF Exchange
 type OrderFilledEvent struct {
-    OrderHash          [32]byte
-    Maker              common.Address
-    Taker              common.Address
-    MakerAssetId       *big.Int
-    TakerAssetId       *big.Int
-    MakerAmountFilled  *big.Int
-    TakerAmountFilled  *big.Int
-    Fee                *big.Int
+	OrderHash         [32]byte
+	Maker             common.Address
+	Taker             common.Address
+	MakerAssetId      *big.Int
+	TakerAssetId      *big.Int
+	MakerAmountFilled *big.Int
+	TakerAmountFilled *big.Int
+	Fee               *big.Int
 }
 
-
 // OrdersMatchedEvent represents batch order matching
 type OrdersMatchedEvent struct {
-	TakerOrderHash [32]byte
-	TakerOrderMaker common.Address
-	MakerAssetId   *big.Int
-	TakerAssetId   *big.Int
+	TakerOrderHash    [32]byte
+	TakerOrderMaker   common.Address
+	MakerAssetId      *big.Int
+	TakerAssetId      *big.Int
 	MakerAmountFilled *big.Int
 	TakerAmountFilled *big.Int
 }
 
-func NewPolymarketListener(cfg *config.Config, db *database.DB) (*PolymarketListener, error) {
-	client, err := ethclient.Dial(cfg.PolygonRPCURL)
+func NewPolymarketListener(cfg *config.Config, db *database.DB, bus *events.Bus) (*PolymarketListener, error) {
+	client, err := polygon.Dial(context.Background(), cfg.PolygonRPCURLs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Polygon: %w", err)
+		return nil, err
 	}
-	
+
 	// Parse the exchange ABI
 	exchangeABI, err := abi.JSON(strings.NewReader(CTFExchangeABI))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse ABI: %w", err)
 	}
-	
+
 	// Calculate event signatures
 	orderFilledSig := crypto.Keccak256Hash([]byte("OrderFilled(bytes32,address,address,uint256,uint256,uint256,uint256,uint256)"))
-	ordersMatchedSig := crypto.Keccak256Hash([]byte("OrdersMatched(bytes32,bytes32[],uint256,uint256,uint256,uint256)"))
-	
-	return &PolymarketListener{
+	ordersMatchedSig := crypto.Keccak256Hash([]byte("OrdersMatched(bytes32,address,uint256,uint256,uint256,uint256)"))
+
+	l := &PolymarketListener{
 		cfg:              cfg,
 		db:               db,
-		client:           client,
+		bus:              bus,
+		metrics:          metrics.For(cfg.StrategyID),
 		exchangeABI:      exchangeABI,
 		orderFilledSig:   orderFilledSig,
 		ordersMatchedSig: ordersMatchedSig,
-		topTraders:       make(map[string]bool),
-	}, nil
+		now:              time.Now,
+		ready:            make(chan struct{}),
+		client:           client,
+	}
+	return l, nil
 }
 
 func (l *PolymarketListener) Start(ctx context.Context) error {
-	log.Println("Starting Polymarket event listener...")
-	
-	// Update top traders list periodically
+	slog.Info("starting Polymarket event listener")
+
+	// Update top traders list now, so catch-up knows who to look for, and
+	// periodically after
+	l.refreshTopTraders(ctx)
 	go l.updateTopTraders(ctx)
-	
+
+	// Blocks skipped before a restart are still owed a backfill
+	l.loadMissed(ctx)
+
+	// Heads are drained promptly into a work queue so slow block processing
+	// can't overflow the subscription
+	blocks := make(chan uint64, l.cfg.HeaderBufferSize)
+	go l.processBlocks(ctx, blocks)
+
+	// Also poll old blocks in case we missed any
+	go l.pollHistoricalBlocks(ctx)
+
+	// Signals from recent blocks wait out reorgs before being acted on
+	if l.cfg.ConfirmationBlocks > 1 {
+		go l.confirmSignals(ctx)
+	}
+
+	// Dropped subscriptions are routine on long-lived WebSocket connections:
+	// reconnect with backoff, catching up on what was missed meanwhile
+	backoff := minReconnectBackoff
+	attempt := 0
+	for {
+		subscribed, err := l.follow(ctx, blocks)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if subscribed {
+			backoff, attempt = minReconnectBackoff, 0
+		}
+		attempt++
+
+		slog.Warn("head subscription lost, reconnecting", "err", err, "attempt", attempt, "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxReconnectBackoff)
+
+		if err := l.redial(ctx); err != nil {
+			slog.Error("reconnect to Polygon failed", "err", err)
+		}
+	}
+}
+
+// follow catches up from the checkpoint, then feeds new heads to the work
+// queue until the subscription fails or ctx is cancelled. subscribed reports
+// whether it got as far as a live subscription.
+func (l *PolymarketListener) follow(ctx context.Context, blocks chan<- uint64) (subscribed bool, err error) {
+	// Time-based checks assume the host clock agrees with the chain
+	var caughtUpTo uint64
+	if head, err := l.rpc().HeaderByNumber(ctx, nil); err != nil {
+		slog.Error("failed to fetch latest header, skipping catch-up", "err", err)
+	} else {
+		l.checkClockSkew(head.Time)
+		l.observeHead(head.Number.Uint64())
+
+		// Scan blocks produced while we were down before going live
+		caughtUpTo = head.Number.Uint64()
+		if err := l.catchUp(ctx, caughtUpTo); err != nil {
+			return false, err
+		}
+	}
+
 	// Subscribe to new blocks
-	headers := make(chan *types.Header)
-	sub, err := l.client.SubscribeNewHead(ctx, headers)
+	headers := make(chan *types.Header, l.cfg.HeaderBufferSize)
+	sub, err := l.rpc().SubscribeNewHead(ctx, headers)
 	if err != nil {
-		return fmt.Errorf("failed to subscribe to new heads: %w", err)
+		return false, fmt.Errorf("failed to subscribe to new heads: %w", err)
 	}
 	defer sub.Unsubscribe()
-	
-	// Also poll old blocks in case we missed any
-	go l.pollHistoricalBlocks(ctx)
-	
+	l.readyOnce.Do(func() { close(l.ready) })
+
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return true, ctx.Err()
 		case err := <-sub.Err():
-			log.Printf("Subscription error: %v", err)
-			return err
+			slog.Error("subscription error", "err", err)
+			return true, err
 		case header := <-headers:
-			if err := l.processBlock(ctx, header.Number); err != nil {
-				log.Printf("Error processing block %d: %v", header.Number.Uint64(), err)
+			// Blocks between the catch-up and the first live head
+			if caughtUpTo > 0 && header.Number.Uint64() > caughtUpTo+1 {
+				l.markMissed(ctx, caughtUpTo+1, header.Number.Uint64()-1)
 			}
+			caughtUpTo = 0
+
+			l.observeHead(header.Number.Uint64())
+			l.checkClockSkew(header.Time)
+			l.enqueueBlock(ctx, blocks, header.Number.Uint64())
 		}
 	}
 }
 
+// rpc returns the current Polygon client, replaced on reconnect
+func (l *PolymarketListener) rpc() *ethclient.Client {
+	return l.client.Eth()
+}
+
+// redial reconnects on the next configured endpoint that answers, cycling
+// through the list
+func (l *PolymarketListener) redial(ctx context.Context) error {
+	slog.Info("reconnecting to Polygon", "from", l.client.URL())
+	return l.client.Failover(ctx)
+}
+
+// Ready is closed once the listener is subscribed to new blocks
+func (l *PolymarketListener) Ready() <-chan struct{} {
+	return l.ready
+}
+
+// observeHead caches the highest block number seen on the subscription
+func (l *PolymarketListener) observeHead(blockNumber uint64) {
+	for {
+		head := l.chainHead.Load()
+		if blockNumber <= head || l.chainHead.CompareAndSwap(head, blockNumber) {
+			return
+		}
+	}
+}
+
+// checkClockSkew measures the host clock against a block timestamp and
+// alerts once when the difference exceeds MaxClockSkew, and again on recovery
+func (l *PolymarketListener) checkClockSkew(blockTime uint64) {
+	skew := l.now().Sub(time.Unix(int64(blockTime), 0))
+	l.clockSkew.Store(int64(skew))
+
+	if l.cfg.MaxClockSkew <= 0 {
+		return
+	}
+	if skew > l.cfg.MaxClockSkew || skew < -l.cfg.MaxClockSkew {
+		if !l.skewAlerted.Swap(true) {
+			slog.Error("ALERT: host clock is off the latest block timestamp, time-based checks may misbehave",
+				"skew", skew.Round(time.Second), "max_skew", l.cfg.MaxClockSkew)
+		}
+	} else if l.skewAlerted.Swap(false) {
+		slog.Info("host clock back within range of the chain", "max_skew", l.cfg.MaxClockSkew)
+	}
+}
+
+// SyncStatus combines the stored checkpoint with the cached chain head
+func (l *PolymarketListener) SyncStatus(ctx context.Context) (SyncStatus, error) {
+	status := SyncStatus{
+		ChainHead:        l.chainHead.Load(),
+		Backfilling:      l.backfilling.Load(),
+		ClockSkewSeconds: time.Duration(l.clockSkew.Load()).Seconds(),
+	}
+
+	cp, err := l.db.GetListenerCheckpoint(ctx)
+	if err != nil {
+		return status, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if cp != nil {
+		status.LastProcessedBlock = cp.LastProcessedBlock
+		status.LastProcessedAt = &cp.UpdatedAt
+	}
+	if status.ChainHead > status.LastProcessedBlock {
+		status.Lag = status.ChainHead - status.LastProcessedBlock
+	}
+	return status, nil
+}
+
+// enqueueBlock hands a block to the processing worker without blocking. When
+// the queue is full the block is recorded as missed for the backfill instead.
+func (l *PolymarketListener) enqueueBlock(ctx context.Context, blocks chan<- uint64, blockNumber uint64) {
+	select {
+	case blocks <- blockNumber:
+	default:
+		slog.Warn("block queue full, deferring block to backfill", "block_number", blockNumber)
+		l.markMissed(ctx, blockNumber, blockNumber)
+	}
+}
+
+// processBlocks runs the block work queue until ctx is cancelled. A block
+// that fails is left to the backfill: the checkpoint may already be past it.
+func (l *PolymarketListener) processBlocks(ctx context.Context, blocks <-chan uint64) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case blockNumber := <-blocks:
+			if _, err := l.processBlock(ctx, new(big.Int).SetUint64(blockNumber)); err != nil {
+				slog.Error("error processing block, deferring to backfill", "block_number", blockNumber, "err", err)
+				l.markMissed(ctx, blockNumber, blockNumber)
+			}
+		}
+	}
+}
+
+// markMissed adds blocks from..to to the range awaiting backfill. The range
+// is also stored, since the checkpoint moves past it: a restart before the
+// backfill picks it up again in loadMissed.
+func (l *PolymarketListener) markMissed(ctx context.Context, from, to uint64) {
+	l.missedMu.Lock()
+	if l.missedFrom == 0 || from < l.missedFrom {
+		l.missedFrom = from
+	}
+	if to > l.missedTo {
+		l.missedTo = to
+	}
+	l.missedMu.Unlock()
+
+	// Also called while shutting down, for a backfill cut short
+	if err := l.db.AddMissedBlocks(context.WithoutCancel(ctx), from, to); err != nil {
+		slog.Error("failed to store missed blocks, they're lost on restart", "from", from, "to", to, "err", err)
+	}
+}
+
+// loadMissed restores the missed range stored before a restart
+func (l *PolymarketListener) loadMissed(ctx context.Context) {
+	from, to, ok, err := l.db.GetMissedBlocks(ctx)
+	if err != nil {
+		slog.Error("failed to load missed blocks", "err", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	slog.Info("resuming backfill of blocks missed before restart", "from", from, "to", to)
+	l.missedMu.Lock()
+	l.missedFrom, l.missedTo = from, to
+	l.missedMu.Unlock()
+}
+
+// takeMissed returns and clears the range of blocks awaiting backfill
+func (l *PolymarketListener) takeMissed() (from, to uint64, ok bool) {
+	l.missedMu.Lock()
+	defer l.missedMu.Unlock()
+
+	if l.missedFrom == 0 {
+		return 0, 0, false
+	}
+	from, to = l.missedFrom, l.missedTo
+	l.missedFrom, l.missedTo = 0, 0
+	return from, to, true
+}
+
 func (l *PolymarketListener) updateTopTraders(ctx context.Context) {
 	ticker := time.NewTicker(5 * time.Minute)
 	defer ticker.Stop()
-	
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			traders, err := l.db.GetTopTraders(l.cfg.TopTradersCount)
-			if err != nil {
-				log.Printf("Failed to get top traders: %v", err)
-				continue
-			}
-			
-			// Update map
-			l.topTraders = make(map[string]bool)
-			for _, trader := range traders {
-				l.topTraders[strings.ToLower(trader)] = true
-			}
-			
-			log.Printf("Updated top traders list: %d traders", len(l.topTraders))
+			l.refreshTopTraders(ctx)
 		}
 	}
 }
 
-func (l *PolymarketListener) processBlock(ctx context.Context, blockNumber *big.Int) error {
+func (l *PolymarketListener) refreshTopTraders(ctx context.Context) {
+	traders, err := l.db.GetTopTradersByScore(ctx, l.cfg.TopTradersCount, database.ScoreWeights{
+		PnL:         l.cfg.ScoreWeightPnL,
+		WinRate:     l.cfg.ScoreWeightWinRate,
+		Consistency: l.cfg.ScoreWeightConsistency,
+	})
+	if err != nil {
+		slog.Error("failed to get top traders", "err", err)
+		return
+	}
+
+	// Update map
+	topTraders := make(map[string]bool)
+	for _, trader := range traders {
+		topTraders[strings.ToLower(trader)] = true
+	}
+	l.topTraders.Store(&topTraders)
+
+	slog.Info("updated top traders list", "count", len(topTraders))
+}
+
+// isTracked reports whether address is a tracked trader
+func (l *PolymarketListener) isTracked(address string) bool {
+	traders := l.topTraders.Load()
+	return traders != nil && (*traders)[strings.ToLower(address)]
+}
+
+// processBlock scans one block for top trader fills and stores their signals.
+//
+// The checkpoint is the last write: it only advances once every signal from
+// the block is committed. A crash mid-block leaves the checkpoint behind, so
+// the block is scanned again on restart; signals already stored are not
+// duplicated thanks to the UNIQUE (tx_hash, log_index) dedup on the signals
+// table. Together that gives exactly-once signals without a transaction
+// spanning the RPC calls.
+//
+// It returns the signals that were newly stored, leaving out ones already
+// seen on an earlier scan.
+func (l *PolymarketListener) processBlock(ctx context.Context, blockNumber *big.Int) ([]database.Signal, error) {
+	n := blockNumber.Uint64()
+	return l.processRange(ctx, n, n)
+}
+
+// processRange is processBlock for blocks from..to with a single FilterLogs
+// call. Fills are still deduped and aggregated per block, and the checkpoint
+// advances to to once the whole range is stored.
+func (l *PolymarketListener) processRange(ctx context.Context, from, to uint64) ([]database.Signal, error) {
 	// Query for OrderFilled events from both exchanges
 	query := ethereum.FilterQuery{
-		FromBlock: blockNumber,
-		ToBlock:   blockNumber,
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
 		Addresses: []common.Address{
 			common.HexToAddress(CTF_EXCHANGE_ADDR),
 			common.HexToAddress(NEG_RISK_EXCHANGE_ADDR),
@@ -165,46 +463,110 @@ func (l *PolymarketListener) processBlock(ctx context.Context, blockNumber *big.
 			{l.orderFilledSig, l.ordersMatchedSig},
 		},
 	}
-	
-	logs, err := l.client.FilterLogs(ctx, query)
+
+	logs, err := l.rpc().FilterLogs(ctx, query)
 	if err != nil {
-		return fmt.Errorf("failed to filter logs: %w", err)
+		return nil, fmt.Errorf("failed to filter logs: %w", err)
 	}
-	
+
+	// Logs come back in chain order, group them by block
+	var blockSignals [][]*TradeSignal
+	var lastBlock uint64
 	for _, vLog := range logs {
-		if err := l.processLog(vLog); err != nil {
-			log.Printf("Error processing log: %v", err)
-			// stop loop
-			break
+		// The live subscription and the backfill can both see a log; once
+		// its signal is stored it must never produce another
+		seen, err := l.db.IsLogProcessed(ctx, vLog.TxHash.Hex(), vLog.Index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check processed logs: %w", err)
+		}
+		if seen {
+			continue
+		}
+
+		signal, err := l.processLog(vLog)
+		if err != nil {
+			// A log we can't decode will never decode, don't hold the block on it
+			slog.Error("error processing log", "tx_hash", vLog.TxHash.Hex(), "log_index", vLog.Index, "err", err)
+			continue
+		}
+		if signal == nil {
+			continue
+		}
+		if len(blockSignals) == 0 || vLog.BlockNumber != lastBlock {
+			blockSignals = append(blockSignals, nil)
+			lastBlock = vLog.BlockNumber
+		}
+		blockSignals[len(blockSignals)-1] = append(blockSignals[len(blockSignals)-1], signal)
+	}
+
+	var inserted []database.Signal
+	for _, signals := range blockSignals {
+		// Every log that yielded a tracked trader's signal, including ones
+		// merged or dropped below. Any other log stays unprocessed, so a
+		// rescan after its trader is tracked can still copy it.
+		refs := make([]database.LogRef, 0, len(signals))
+		for _, signal := range signals {
+			if !l.isTracked(signal.Trader) {
+				continue
+			}
+			refs = append(refs, database.LogRef{TxHash: signal.TxHash, LogIndex: signal.LogIndex, BlockNumber: signal.BlockNumber})
+		}
+
+		signals = dropMatchedDuplicates(signals)
+		if !l.cfg.KeepCrossExchangeDuplicates {
+			signals = dedupeAcrossExchanges(signals)
+		}
+
+		// Partial fills of one order arrive as separate logs in the same block
+		for _, signal := range aggregateFills(signals) {
+			if l.belowMinNotional(signal) {
+				continue
+			}
+			stored, err := l.storeTradeSignal(ctx, signal, signal.TxHash)
+			if err != nil {
+				return inserted, fmt.Errorf("failed to store signal from tx %s: %w", signal.TxHash, err)
+			}
+			if stored != nil {
+				inserted = append(inserted, *stored)
+			}
 		}
+
+		// Only after the block's signals are stored, so a crash in between
+		// rescans the logs (the signals table dedups those) rather than losing them
+		if err := l.db.MarkLogsProcessed(ctx, refs); err != nil {
+			return inserted, err
+		}
+	}
+
+	if err := l.db.SetLastProcessedBlock(ctx, to); err != nil {
+		return inserted, fmt.Errorf("failed to advance checkpoint: %w", err)
 	}
-	
-	return nil
+	l.metrics.BlocksProcessed.Inc(int64(to - from + 1))
+	return inserted, nil
 }
 
-func (l *PolymarketListener) processLog(vLog types.Log) error {
-	// fmt.Println(vLog.Topics)
+func (l *PolymarketListener) processLog(vLog types.Log) (*TradeSignal, error) {
 	// Check if this is an OrderFilled event
 	if vLog.Topics[0] == l.orderFilledSig {
 		return l.processOrderFilled(vLog)
 	}
-	
+
 	// Check if this is an OrdersMatched event
 	if vLog.Topics[0] == l.ordersMatchedSig {
 		return l.processOrdersMatched(vLog)
 	}
-	
-	return nil
+
+	return nil, nil
 }
 
-func (l *PolymarketListener) processOrderFilled(vLog types.Log) error {
+func (l *PolymarketListener) processOrderFilled(vLog types.Log) (*TradeSignal, error) {
 	// Parse the event
 	event := &OrderFilledEvent{}
 	err := l.exchangeABI.UnpackIntoInterface(event, "OrderFilled", vLog.Data)
 	if err != nil {
-		return fmt.Errorf("failed to unpack OrderFilled: %w", err)
+		return nil, fmt.Errorf("failed to unpack OrderFilled: %w", err)
 	}
-	
+
 	// Extract indexed parameters from topics
 	// Topics[0] = event signature
 	// Topics[1] = orderHash (indexed)
@@ -215,63 +577,178 @@ func (l *PolymarketListener) processOrderFilled(vLog types.Log) error {
 		event.Maker = common.BytesToAddress(vLog.Topics[2].Bytes())
 		event.Taker = common.BytesToAddress(vLog.Topics[3].Bytes())
 	} else {
-		return fmt.Errorf("insufficient topics in log: expected 4, got %d", len(vLog.Topics))
+		return nil, fmt.Errorf("insufficient topics in log: expected 4, got %d", len(vLog.Topics))
 	}
-	
-	// fmt.Println("event", event, vLog.Data)
-	
+
 	maker := event.Maker.Hex()
 	taker := event.Taker.Hex()
-	
+
 	// Check if maker or taker is a top trader we're tracking
-	makerIsTop := l.topTraders[strings.ToLower(maker)]
-	takerIsTop := l.topTraders[strings.ToLower(taker)]
-	testCondition := strings.ToLower(taker) == "0x4bfb41d5b3570defd03c39a9a4d8de6bd8b8982e"
-	
-
-
-	// test condition
-	if(testCondition) {
-		log.Printf("🔔 Top trader activity detected!")
-		log.Printf("   Maker: %s (Top: %v)", maker[:10], makerIsTop)
-		log.Printf("   Taker: %s (Top: %v)", taker[:10], takerIsTop)
-		log.Printf("   Maker Asset: %s", event.MakerAssetId.String())
-		log.Printf("   Taker Asset: %s", event.TakerAssetId.String())
-		log.Printf("   Maker Amount: %s", event.MakerAmountFilled.String())
-		log.Printf("   Taker Amount: %s", event.TakerAmountFilled.String())
-		log.Printf("   Tx: %s", vLog.TxHash.Hex())
-
-				// Determine who initiated (maker or taker) and what they're doing
-		tradeSignal := l.extractTradeSignal(event, makerIsTop, takerIsTop)
-		// Store in database for executor to pick up
-		return l.storeTradeSignal(tradeSignal, vLog.TxHash.Hex())
-	}
-	if (!makerIsTop && !takerIsTop) {
-		// log.Printf(" Not a top trader activity :(")
-		// log.Printf(" Not a top trader activity :(")
-		log.Println("maker", testCondition, strings.ToLower(maker), strings.ToLower(taker))
-		return nil // Skip if not from top trader
-	}
-	
-	log.Printf("🔔 Top trader activity detected!")
-	log.Printf("   Maker: %s (Top: %v)", maker[:10], makerIsTop)
-	log.Printf("   Taker: %s (Top: %v)", taker[:10], takerIsTop)
-	log.Printf("   Maker Asset: %s", event.MakerAssetId.String())
-	log.Printf("   Taker Asset: %s", event.TakerAssetId.String())
-	log.Printf("   Maker Amount: %s", event.MakerAmountFilled.String())
-	log.Printf("   Taker Amount: %s", event.TakerAmountFilled.String())
-	log.Printf("   Tx: %s", vLog.TxHash.Hex())
-	
+	makerIsTop := l.isTracked(maker)
+	takerIsTop := l.isTracked(taker)
+	if !makerIsTop && !takerIsTop {
+		l.logSkippedFill(maker, taker, vLog)
+		return nil, nil // Skip if not from top trader
+	}
+
+	l.logTopTraderFill(event, vLog, makerIsTop, takerIsTop)
+
 	// Determine who initiated (maker or taker) and what they're doing
 	tradeSignal := l.extractTradeSignal(event, makerIsTop, takerIsTop)
-	// Store in database for executor to pick up
-	return l.storeTradeSignal(tradeSignal, vLog.TxHash.Hex())
+	l.annotateSignal(tradeSignal, vLog)
+	// Returned to processBlock, which stores it for the executor to pick up
+	return tradeSignal, nil
+}
+
+// annotateSignal records where in the chain a signal's fill came from
+func (l *PolymarketListener) annotateSignal(signal *TradeSignal, vLog types.Log) {
+	signal.TxHash = vLog.TxHash.Hex()
+	signal.Exchange = vLog.Address.Hex()
+	signal.BlockNumber = vLog.BlockNumber
+	signal.LogIndex = vLog.Index
+}
+
+// dedupeAcrossExchanges drops fills whose order was already seen in the block
+// on the other exchange. One user action can surface on both the CTF and
+// NegRisk exchanges; keyed on the order hash only the first exchange's fills
+// are kept. Repeated fills of an order on the same exchange are untouched,
+// aggregateFills merges those.
+func dedupeAcrossExchanges(signals []*TradeSignal) []*TradeSignal {
+	exchangeOf := make(map[string]string) // order hash -> first exchange seen
+	kept := signals[:0:0]
+	for _, signal := range signals {
+		if signal.OrderHash == "" {
+			kept = append(kept, signal)
+			continue
+		}
+		first, seen := exchangeOf[signal.OrderHash]
+		if !seen {
+			exchangeOf[signal.OrderHash] = signal.Exchange
+		} else if !strings.EqualFold(first, signal.Exchange) {
+			slog.Debug("dropping fill already seen on another exchange",
+				"tx_hash", signal.TxHash, "log_index", signal.LogIndex, "order_hash", signal.OrderHash, "first_exchange", first)
+			continue
+		}
+		kept = append(kept, signal)
+	}
+	return kept
+}
+
+// aggregateFills merges signals from one block that are partial fills of the
+// same order, by order hash, into a single signal. Fills of different orders
+// stay separate even when they share trader, token and side. Amounts and
+// fees are summed and the price becomes the amount-weighted average. The merged signal
+// keeps the position of its first fill.
+func aggregateFills(signals []*TradeSignal) []*TradeSignal {
+	var merged []*TradeSignal
+	groups := make(map[string]*TradeSignal)
+	weighted := make(map[string]*big.Int) // Sum of price * amount per group
+	priced := make(map[string]*big.Int)   // Amount of fills that carried a price
+
+	for _, signal := range signals {
+		if signal.TokenID == nil || signal.Amount == nil {
+			continue
+		}
+		key := signal.OrderHash + "|" + strings.ToLower(signal.Trader) + "|" + signal.TokenID.String() + "|" + signal.Side
+
+		group, ok := groups[key]
+		if !ok {
+			group = &TradeSignal{}
+			*group = *signal
+			group.Amount = new(big.Int)
+			group.Fee = 0
+			groups[key] = group
+			weighted[key] = new(big.Int)
+			priced[key] = new(big.Int)
+			merged = append(merged, group)
+		}
+
+		group.Amount.Add(group.Amount, signal.Amount)
+		group.Fee += signal.Fee
+		if signal.Price != nil {
+			weighted[key].Add(weighted[key], new(big.Int).Mul(signal.Price, signal.Amount))
+			priced[key].Add(priced[key], signal.Amount)
+		}
+	}
+
+	for key, group := range groups {
+		if priced[key].Sign() > 0 {
+			group.Price = new(big.Int).Div(weighted[key], priced[key])
+		}
+	}
+	return merged
+}
+
+// IsNegRiskExchange reports whether a fill came from the NegRisk exchange,
+// i.e. belongs to a multi-outcome market
+func IsNegRiskExchange(exchange string) bool {
+	return strings.EqualFold(exchange, NEG_RISK_EXCHANGE_ADDR)
+}
+
+// processOrdersMatched turns a match into a signal for the taker order's
+// maker when they're tracked. The event reports the taker order's side of the
+// match in the same shape as an OrderFilled with the taker order's maker as
+// maker, so it goes through the same extraction.
+func (l *PolymarketListener) processOrdersMatched(vLog types.Log) (*TradeSignal, error) {
+	event := &OrdersMatchedEvent{}
+	err := l.exchangeABI.UnpackIntoInterface(event, "OrdersMatched", vLog.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack OrdersMatched: %w", err)
+	}
+
+	// Topics[0] = event signature
+	// Topics[1] = takerOrderHash (indexed)
+	// Topics[2] = takerOrderMaker (indexed)
+	if len(vLog.Topics) < 3 {
+		return nil, fmt.Errorf("insufficient topics in log: expected 3, got %d", len(vLog.Topics))
+	}
+	event.TakerOrderHash = [32]byte(vLog.Topics[1])
+	event.TakerOrderMaker = common.BytesToAddress(vLog.Topics[2].Bytes())
+
+	maker := event.TakerOrderMaker.Hex()
+	if !l.isTracked(maker) {
+		l.logSkippedFill(maker, vLog.Address.Hex(), vLog)
+		return nil, nil
+	}
+
+	fill := &OrderFilledEvent{
+		OrderHash:         event.TakerOrderHash,
+		Maker:             event.TakerOrderMaker,
+		Taker:             vLog.Address,
+		MakerAssetId:      event.MakerAssetId,
+		TakerAssetId:      event.TakerAssetId,
+		MakerAmountFilled: event.MakerAmountFilled,
+		TakerAmountFilled: event.TakerAmountFilled,
+		Fee:               new(big.Int), // Fees are only reported on OrderFilled
+	}
+	l.logTopTraderFill(fill, vLog, true, false)
+
+	tradeSignal := l.extractTradeSignal(fill, true, false)
+	tradeSignal.FromMatch = true
+	l.annotateSignal(tradeSignal, vLog)
+	return tradeSignal, nil
 }
 
-func (l *PolymarketListener) processOrdersMatched(vLog types.Log) error {
-	// Similar to OrderFilled but for batch matching
-	log.Printf("OrdersMatched event in tx: %s", vLog.TxHash.Hex())
-	return nil
+// dropMatchedDuplicates drops signals from OrdersMatched when the same tx
+// also carried an OrderFilled for that order and trader. The exchange usually
+// emits both for the taker order, and only the OrderFilled one carries the
+// fee; counting both would double the copied amount.
+func dropMatchedDuplicates(signals []*TradeSignal) []*TradeSignal {
+	filled := make(map[string]bool)
+	for _, signal := range signals {
+		if !signal.FromMatch {
+			filled[signal.TxHash+"|"+signal.OrderHash+"|"+strings.ToLower(signal.Trader)] = true
+		}
+	}
+
+	kept := signals[:0:0]
+	for _, signal := range signals {
+		if signal.FromMatch && filled[signal.TxHash+"|"+signal.OrderHash+"|"+strings.ToLower(signal.Trader)] {
+			continue
+		}
+		kept = append(kept, signal)
+	}
+	return kept
 }
 
 type TradeSignal struct {
@@ -282,16 +759,23 @@ type TradeSignal struct {
 	Amount      *big.Int
 	Price       *big.Int
 	TxHash      string
+	Exchange    string  // CTF or NegRisk exchange that emitted the fill
+	OrderHash   string  // Order the fill belongs to
+	FromMatch   bool    // Derived from OrdersMatched rather than OrderFilled
+	Fee         float64 // USDC, negative when paid by Trader (see FeeUSDC)
+	BlockNumber uint64
+	LogIndex    uint
 }
 
 func (l *PolymarketListener) extractTradeSignal(event *OrderFilledEvent, makerIsTop, takerIsTop bool) *TradeSignal {
-	signal := &TradeSignal{}
-	
+	signal := &TradeSignal{OrderHash: common.Hash(event.OrderHash).Hex()}
+
 	// If maker asset is 0, maker is buying (providing USDC) // so we can buy - if maker is top trader
-	// If taker asset is 0, taker is buying (providing USDC) // 
-	
+	// If taker asset is 0, taker is buying (providing USDC) //
+
 	if makerIsTop {
 		signal.Trader = event.Maker.Hex()
+		signal.Fee = FeeUSDC(event.Fee, true)
 		if event.MakerAssetId.Cmp(big.NewInt(0)) == 0 {
 			signal.Side = "BUY"
 			signal.TokenID = event.TakerAssetId
@@ -303,6 +787,7 @@ func (l *PolymarketListener) extractTradeSignal(event *OrderFilledEvent, makerIs
 		}
 	} else if takerIsTop {
 		signal.Trader = event.Taker.Hex()
+		signal.Fee = FeeUSDC(event.Fee, false)
 		if event.TakerAssetId.Cmp(big.NewInt(0)) == 0 {
 			signal.Side = "BUY"
 			signal.TokenID = event.MakerAssetId
@@ -313,39 +798,235 @@ func (l *PolymarketListener) extractTradeSignal(event *OrderFilledEvent, makerIs
 			signal.Amount = event.TakerAmountFilled
 		}
 	}
-	
-	// Calculate price (simplified)
-	if signal.Side == "BUY" && event.MakerAmountFilled.Cmp(big.NewInt(0)) > 0 {
-		signal.Price = new(big.Int).Div(
-			new(big.Int).Mul(event.TakerAmountFilled, big.NewInt(1e6)),
-			event.MakerAmountFilled,
-		)
-	}
-	fmt.Printf("+v%s", signal)
+
+	signal.Price = fillPrice(event)
 	return signal
 }
 
-func (l *PolymarketListener) storeTradeSignal(signal *TradeSignal, txHash string) error {
+// fillPrice is a fill's USDC per outcome token in 6-decimal fixed point
+// (500000 = $0.50), the same for both sides of the trade. The USDC leg is
+// whichever asset ID is 0: a maker paying USDC fills makerAmount of it for
+// takerAmount tokens, a maker selling tokens gets takerAmount USDC for
+// makerAmount of them. Returns nil when the fill has no USDC leg or moved no
+// tokens.
+func fillPrice(event *OrderFilledEvent) *big.Int {
+	if event.MakerAmountFilled == nil || event.TakerAmountFilled == nil {
+		return nil
+	}
+	usdc, tokens := event.MakerAmountFilled, event.TakerAmountFilled
+	switch {
+	case event.MakerAssetId.Sign() == 0:
+	case event.TakerAssetId.Sign() == 0:
+		usdc, tokens = tokens, usdc
+	default:
+		return nil
+	}
+	if tokens.Sign() <= 0 {
+		return nil
+	}
+	return new(big.Int).Div(new(big.Int).Mul(usdc, big.NewInt(1e6)), tokens)
+}
+
+// belowMinNotional reports whether a signal is worth less than
+// min_signal_notional_usdc. Signals without a price are kept, their notional
+// is unknown.
+func (l *PolymarketListener) belowMinNotional(signal *TradeSignal) bool {
+	if l.cfg.MinSignalNotionalUSDC <= 0 || signal.Price == nil || signal.Amount == nil {
+		return false
+	}
+	// Amount and Price are both 6-decimal fixed point
+	notional := units.ToFloat(new(big.Int).Mul(signal.Amount, signal.Price), 2*units.Decimals)
+	if notional >= l.cfg.MinSignalNotionalUSDC {
+		return false
+	}
+	slog.Debug("skipping signal below minimum notional", "trader", signal.Trader, "side", signal.Side,
+		"tx_hash", signal.TxHash, "notional", notional, "min_notional", l.cfg.MinSignalNotionalUSDC)
+	return true
+}
+
+// storeTradeSignal stores a signal and returns it if it was newly inserted,
+// or nil if it was already stored
+func (l *PolymarketListener) storeTradeSignal(ctx context.Context, signal *TradeSignal, txHash string) (*database.Signal, error) {
 	// Store in database - executor will pick this up
-	// For now, just log
-	log.Printf("📝 Storing trade signal: %s %s token %s amount %s",
-		signal.Trader[:10], signal.Side, signal.TokenID.String(), signal.Amount.String())
-	return nil
+	slog.Info("storing trade signal", "trader", signal.Trader, "side", signal.Side,
+		"token_id", signal.TokenID.String(), "amount", signal.Amount.String(), "tx_hash", txHash, "block_number", signal.BlockNumber)
+
+	price := ""
+	if signal.Price != nil {
+		price = signal.Price.String()
+	}
+
+	create := l.db.CreateSignal
+	if l.cfg.SignalSource == "both" {
+		// The Data API poller may have stored this fill already
+		create = l.db.CreateSignalOnce
+	}
+	stored, inserted, err := create(ctx, &database.Signal{
+		Trader:      signal.Trader,
+		Side:        signal.Side,
+		MarketID:    signal.MarketID,
+		TokenID:     signal.TokenID.String(),
+		Amount:      signal.Amount.String(),
+		Price:       price,
+		TxHash:      txHash,
+		Exchange:    signal.Exchange,
+		OrderHash:   signal.OrderHash,
+		Fee:         signal.Fee,
+		BlockNumber: signal.BlockNumber,
+		LogIndex:    signal.LogIndex,
+		Status:      l.signalStatus(signal.BlockNumber),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// A re-seen log (backfill, restart) must not notify twice
+	if !inserted {
+		slog.Debug("signal already stored, skipping side effects", "tx_hash", txHash, "log_index", signal.LogIndex)
+		return nil, nil
+	}
+	l.metrics.SignalsDetected.Inc(1)
+	l.bus.Publish(events.SignalDetected, *stored)
+	return stored, nil
+}
+
+// signalStatus is "pending" for a signal from a block already
+// ConfirmationBlocks deep, as backfilled ones usually are, and "unconfirmed"
+// for one that must wait for confirmSignals
+func (l *PolymarketListener) signalStatus(blockNumber uint64) string {
+	head := l.chainHead.Load()
+	if l.cfg.ConfirmationBlocks <= 1 || head >= blockNumber && head-blockNumber+1 >= uint64(l.cfg.ConfirmationBlocks) {
+		return "pending"
+	}
+	return "unconfirmed"
 }
 
 func (l *PolymarketListener) pollHistoricalBlocks(ctx context.Context) {
 	// Poll for any missed blocks periodically
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
-	
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			// Implement backfill logic if needed
+			l.detectGap(ctx)
+			l.backfillMissed(ctx)
+		}
+	}
+}
+
+// detectGap marks blocks missed when the checkpoint has fallen further
+// behind the chain than the work queue can hold, which means the head
+// subscription stopped delivering without erroring
+func (l *PolymarketListener) detectGap(ctx context.Context) {
+	// A catch-up in progress is already closing the gap
+	if l.backfilling.Load() {
+		return
+	}
+
+	head, err := l.rpc().HeaderByNumber(ctx, nil)
+	if err != nil {
+		slog.Error("failed to fetch latest header for gap check", "err", err)
+		return
+	}
+	l.observeHead(head.Number.Uint64())
+
+	last, err := l.db.GetLastProcessedBlock(ctx)
+	if err != nil || last == 0 {
+		return
+	}
+	// Blocks still in the work queue are not a gap
+	upTo := head.Number.Uint64()
+	if upTo <= uint64(l.cfg.HeaderBufferSize) {
+		return
+	}
+	upTo -= uint64(l.cfg.HeaderBufferSize)
+	if upTo <= last {
+		return
+	}
+
+	slog.Warn("checkpoint behind head, backfilling the gap", "checkpoint", last, "behind", head.Number.Uint64()-last, "head", head.Number.Uint64())
+	l.markMissed(ctx, last+1, upTo)
+}
+
+// catchUp scans the blocks after the checkpoint up to head. Each block
+// advances the checkpoint as it's processed, so a crash or shutdown midway
+// resumes from where it stopped rather than from scratch. A fresh database
+// starts at head.
+func (l *PolymarketListener) catchUp(ctx context.Context, head uint64) error {
+	last, err := l.db.GetLastProcessedBlock(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if last == 0 || last >= head {
+		return nil
+	}
+
+	slog.Info("resuming from checkpoint", "block_number", last+1, "behind", head-last)
+	l.backfill(ctx, last+1, head)
+	return ctx.Err()
+}
+
+// backfillMissed processes blocks that were dropped from the work queue
+func (l *PolymarketListener) backfillMissed(ctx context.Context) {
+	from, to, ok := l.takeMissed()
+	if !ok {
+		return
+	}
+	report := l.backfill(ctx, from, to)
+
+	switch {
+	case report.Interrupted:
+		// The unscanned rest was marked missed again
+	case len(report.Failed) > 0:
+		// The stored range still covers the failed blocks, so the next pass
+		// retries all of it; logs already stored are skipped as seen
+		l.markMissed(ctx, from, to)
+	default:
+		if err := l.db.ClearMissedBlocks(ctx, from, to); err != nil {
+			slog.Error("failed to clear backfilled blocks", "from", from, "to", to, "err", err)
+		}
+	}
+}
+
+// backfill scans blocks from..to in order and returns a RecoveryReport, also
+// kept for the recovery report endpoint. Batches that fail, and the unscanned
+// rest if ctx is cancelled, are marked missed for a later pass.
+func (l *PolymarketListener) backfill(ctx context.Context, from, to uint64) (report RecoveryReport) {
+	l.backfilling.Store(true)
+	defer l.backfilling.Store(false)
+
+	report = RecoveryReport{FromBlock: from, ToBlock: to, StartedAt: l.now(), Recovered: []database.Signal{}}
+	defer func() {
+		report.FinishedAt = l.now()
+		l.recoveries.add(report)
+		slog.Info("backfill recovered missed signals", "from", from, "to", to, "recovered", len(report.Recovered))
+	}()
+
+	// Ranges are capped per FilterLogs call to stay within provider limits
+	batch := uint64(l.cfg.BackfillBatchSize)
+	slog.Info("backfilling missed blocks", "from", from, "to", to, "batch_size", batch)
+	for n := from; n <= to; n += batch {
+		if ctx.Err() != nil {
+			l.markMissed(ctx, n, to)
+			report.Interrupted = true
+			return report
+		}
+		end := min(n+batch-1, to)
+		inserted, err := l.processRange(ctx, n, end)
+		report.Scanned += int(end - n + 1)
+		report.Recovered = append(report.Recovered, inserted...)
+		if err != nil {
+			slog.Error("error backfilling blocks, deferring to a later pass", "from", n, "to", end, "err", err)
+			l.markMissed(ctx, n, end)
+			for b := n; b <= end; b++ {
+				report.Failed = append(report.Failed, b)
+			}
 		}
 	}
+	return report
 }
 
 // Minimal CTF Exchange ABI (just the events we need)
@@ -369,7 +1050,7 @@ const CTFExchangeABI = `[
 		"anonymous": false,
 		"inputs": [
 			{"indexed": true, "name": "takerOrderHash", "type": "bytes32"},
-			{"indexed": false, "name": "takerOrderMaker", "type": "address"},
+			{"indexed": true, "name": "takerOrderMaker", "type": "address"},
 			{"indexed": false, "name": "makerAssetId", "type": "uint256"},
 			{"indexed": false, "name": "takerAssetId", "type": "uint256"},
 			{"indexed": false, "name": "makerAmountFilled", "type": "uint256"},
@@ -378,4 +1059,4 @@ const CTFExchangeABI = `[
 		"name": "OrdersMatched",
 		"type": "event"
 	}
-]`
\ No newline at end of file
+]`
@@ -0,0 +1,804 @@
+// internal/listener/listener_test.go
+package listener
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/askwhyharsh/lazytrader/internal/config"
+	"github.com/askwhyharsh/lazytrader/internal/database"
+	"github.com/askwhyharsh/lazytrader/internal/events"
+)
+
+var (
+	testMaker = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	testTaker = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	testToken = big.NewInt(12345)
+)
+
+// fill builds an OrderFilled event; amounts are whole units (USDC or tokens)
+func fill(makerAsset, takerAsset *big.Int, makerAmount, takerAmount int64) *OrderFilledEvent {
+	return &OrderFilledEvent{
+		Maker:             testMaker,
+		Taker:             testTaker,
+		MakerAssetId:      makerAsset,
+		TakerAssetId:      takerAsset,
+		MakerAmountFilled: big.NewInt(makerAmount * 1e6),
+		TakerAmountFilled: big.NewInt(takerAmount * 1e6),
+		Fee:               big.NewInt(1e6),
+	}
+}
+
+func TestExtractTradeSignal(t *testing.T) {
+	usdc := big.NewInt(0)
+
+	tests := []struct {
+		name       string
+		event      *OrderFilledEvent
+		makerIsTop bool
+		takerIsTop bool
+		trader     common.Address
+		side       string
+		amount     int64
+		price      int64
+		fee        float64
+	}{
+		{
+			// Maker pays 50 USDC for 100 tokens
+			name:       "maker buy",
+			event:      fill(usdc, testToken, 50, 100),
+			makerIsTop: true,
+			trader:     testMaker,
+			side:       "BUY",
+			amount:     100e6,
+			price:      500000,
+			fee:        -1,
+		},
+		{
+			// Maker sells 100 tokens for 40 USDC
+			name:       "maker sell",
+			event:      fill(testToken, usdc, 100, 40),
+			makerIsTop: true,
+			trader:     testMaker,
+			side:       "SELL",
+			amount:     100e6,
+			price:      400000,
+			fee:        -1,
+		},
+		{
+			// Taker pays 30 USDC for the maker's 100 tokens
+			name:       "taker buy",
+			event:      fill(testToken, usdc, 100, 30),
+			takerIsTop: true,
+			trader:     testTaker,
+			side:       "BUY",
+			amount:     100e6,
+			price:      300000,
+			fee:        1,
+		},
+		{
+			// Taker sells 100 tokens for the maker's 70 USDC
+			name:       "taker sell",
+			event:      fill(usdc, testToken, 70, 100),
+			takerIsTop: true,
+			trader:     testTaker,
+			side:       "SELL",
+			amount:     100e6,
+			price:      700000,
+			fee:        1,
+		},
+		{
+			// Both sides tracked, the maker's view wins
+			name:       "both tracked",
+			event:      fill(usdc, testToken, 50, 100),
+			makerIsTop: true,
+			takerIsTop: true,
+			trader:     testMaker,
+			side:       "BUY",
+			amount:     100e6,
+			price:      500000,
+			fee:        -1,
+		},
+	}
+
+	l := &PolymarketListener{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signal := l.extractTradeSignal(tt.event, tt.makerIsTop, tt.takerIsTop)
+
+			if signal.Trader != tt.trader.Hex() {
+				t.Errorf("trader = %s, want %s", signal.Trader, tt.trader.Hex())
+			}
+			if signal.Side != tt.side {
+				t.Errorf("side = %s, want %s", signal.Side, tt.side)
+			}
+			if signal.TokenID == nil || signal.TokenID.Cmp(testToken) != 0 {
+				t.Errorf("token = %v, want %v", signal.TokenID, testToken)
+			}
+			if signal.Amount == nil || signal.Amount.Int64() != tt.amount {
+				t.Errorf("amount = %v, want %d", signal.Amount, tt.amount)
+			}
+			if signal.Price == nil || signal.Price.Int64() != tt.price {
+				t.Errorf("price = %v, want %d", signal.Price, tt.price)
+			}
+			if signal.Fee != tt.fee {
+				t.Errorf("fee = %v, want %v", signal.Fee, tt.fee)
+			}
+		})
+	}
+}
+
+func TestFillPrice(t *testing.T) {
+	usdc := big.NewInt(0)
+
+	tests := []struct {
+		name  string
+		event *OrderFilledEvent
+		want  *big.Int
+	}{
+		{"maker pays usdc", fill(usdc, testToken, 25, 100), big.NewInt(250000)},
+		{"maker sells tokens", fill(testToken, usdc, 100, 25), big.NewInt(250000)},
+		{"no usdc leg", fill(testToken, big.NewInt(678), 100, 100), nil},
+		{"no tokens moved", fill(usdc, testToken, 25, 0), nil},
+		{"missing amount", &OrderFilledEvent{MakerAssetId: usdc, TakerAssetId: testToken, MakerAmountFilled: big.NewInt(1)}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fillPrice(tt.event)
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("fillPrice = %v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.Cmp(tt.want) != 0 {
+				t.Errorf("fillPrice = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// partial is a BUY signal from testMaker for one fill of order; amount is in
+// whole shares and price in micro-USDC
+func partial(order string, logIndex uint, amount, price int64) *TradeSignal {
+	return &TradeSignal{
+		Trader:    testMaker.Hex(),
+		Side:      "BUY",
+		TokenID:   testToken,
+		Amount:    big.NewInt(amount * 1e6),
+		Price:     big.NewInt(price),
+		OrderHash: order,
+		Fee:       -0.5,
+		LogIndex:  logIndex,
+	}
+}
+
+func TestAggregateFills(t *testing.T) {
+	tests := []struct {
+		name     string
+		signals  []*TradeSignal
+		want     int
+		amount   int64 // Of the first merged signal
+		price    int64
+		fee      float64
+		logIndex uint
+	}{
+		{
+			name:     "three fills of one order",
+			signals:  []*TradeSignal{partial("0x01", 3, 100, 400000), partial("0x01", 5, 100, 500000), partial("0x01", 9, 200, 600000)},
+			want:     1,
+			amount:   400e6,
+			price:    525000,
+			fee:      -1.5,
+			logIndex: 3,
+		},
+		{
+			name:     "separate orders stay separate",
+			signals:  []*TradeSignal{partial("0x01", 3, 100, 400000), partial("0x02", 5, 100, 500000)},
+			want:     2,
+			amount:   100e6,
+			price:    400000,
+			fee:      -0.5,
+			logIndex: 3,
+		},
+		{
+			name:     "single fill untouched",
+			signals:  []*TradeSignal{partial("0x01", 7, 50, 300000)},
+			want:     1,
+			amount:   50e6,
+			price:    300000,
+			fee:      -0.5,
+			logIndex: 7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := aggregateFills(tt.signals)
+			if len(got) != tt.want {
+				t.Fatalf("%d signals, want %d", len(got), tt.want)
+			}
+			first := got[0]
+			if first.Amount.Int64() != tt.amount {
+				t.Errorf("amount = %v, want %d", first.Amount, tt.amount)
+			}
+			if first.Price.Int64() != tt.price {
+				t.Errorf("price = %v, want %d", first.Price, tt.price)
+			}
+			if first.Fee != tt.fee {
+				t.Errorf("fee = %v, want %v", first.Fee, tt.fee)
+			}
+			if first.LogIndex != tt.logIndex {
+				t.Errorf("log index = %d, want %d", first.LogIndex, tt.logIndex)
+			}
+		})
+	}
+
+	// Merging must not write through to the fills it was given
+	fills := []*TradeSignal{partial("0x01", 0, 100, 400000), partial("0x01", 1, 100, 400000)}
+	aggregateFills(fills)
+	if fills[0].Amount.Int64() != 100e6 {
+		t.Errorf("input fill amount changed to %v", fills[0].Amount)
+	}
+}
+
+// chainStub answers the JSON-RPC calls the listener makes. eth_getLogs
+// returns the stub's logs in the requested range after waiting delay, or an
+// error while failing is set, and the blocks it was asked for are recorded.
+type chainStub struct {
+	delay time.Duration
+
+	mu      sync.Mutex
+	logs    []types.Log
+	failing bool
+	scanned map[uint64]bool
+}
+
+func (c *chainStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+
+	var result any
+	switch req.Method {
+	case "eth_chainId":
+		result = "0x89"
+	case "eth_getLogs":
+		var query struct {
+			FromBlock hexutil.Uint64 `json:"fromBlock"`
+			ToBlock   hexutil.Uint64 `json:"toBlock"`
+		}
+		json.Unmarshal(req.Params[0], &query)
+		time.Sleep(c.delay)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.failing {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32000,"message":"upstream unavailable"}}`, req.ID)
+			return
+		}
+		if c.scanned == nil {
+			c.scanned = make(map[uint64]bool)
+		}
+		for n := uint64(query.FromBlock); n <= uint64(query.ToBlock); n++ {
+			c.scanned[n] = true
+		}
+		logs := []types.Log{}
+		for _, vLog := range c.logs {
+			if vLog.BlockNumber >= uint64(query.FromBlock) && vLog.BlockNumber <= uint64(query.ToBlock) {
+				logs = append(logs, vLog)
+			}
+		}
+		result = logs
+	default:
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32601,"message":"unsupported"}}`, req.ID)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result})
+}
+
+func (c *chainStub) setFailing(failing bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failing = failing
+}
+
+// wasScanned reports whether eth_getLogs covered block n
+func (c *chainStub) wasScanned(n uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.scanned[n]
+}
+
+// newTestListener returns a listener on a fresh database, connected to chain
+func newTestListener(t *testing.T, cfg *config.Config, chain http.Handler) (*PolymarketListener, *database.DB) {
+	t.Helper()
+	db, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("database.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	srv := httptest.NewServer(chain)
+	t.Cleanup(srv.Close)
+	cfg.PolygonRPCURLs = []string{srv.URL}
+
+	l, err := NewPolymarketListener(cfg, db, events.New())
+	if err != nil {
+		t.Fatalf("NewPolymarketListener: %v", err)
+	}
+	return l, db
+}
+
+// track sets the listener's tracked traders
+func track(l *PolymarketListener, traders ...common.Address) {
+	tracked := make(map[string]bool)
+	for _, trader := range traders {
+		tracked[strings.ToLower(trader.Hex())] = true
+	}
+	l.topTraders.Store(&tracked)
+}
+
+// filledLog is the OrderFilled log of maker buying tokens of testToken from
+// testTaker for usdc, in whole units, on the CTF exchange
+func filledLog(t *testing.T, l *PolymarketListener, block uint64, index uint, order byte, tokens, usdc int64) types.Log {
+	t.Helper()
+	data, err := l.exchangeABI.Events["OrderFilled"].Inputs.NonIndexed().Pack(
+		big.NewInt(0), testToken, big.NewInt(usdc*1e6), big.NewInt(tokens*1e6), big.NewInt(0))
+	if err != nil {
+		t.Fatalf("pack OrderFilled: %v", err)
+	}
+	return types.Log{
+		Address: common.HexToAddress(CTF_EXCHANGE_ADDR),
+		Topics: []common.Hash{
+			l.orderFilledSig,
+			common.BytesToHash([]byte{order}),
+			common.BytesToHash(testMaker.Bytes()),
+			common.BytesToHash(testTaker.Bytes()),
+		},
+		Data:        data,
+		BlockNumber: block,
+		TxHash:      common.BytesToHash([]byte{byte(block), byte(index)}),
+		BlockHash:   common.BytesToHash([]byte{byte(block)}),
+		Index:       index,
+	}
+}
+
+// countSignals is the number of stored signals
+func countSignals(t *testing.T, db *database.DB) int {
+	t.Helper()
+	signals, err := db.GetSignalHistory(context.Background(), 1000)
+	if err != nil {
+		t.Fatalf("GetSignalHistory: %v", err)
+	}
+	return len(signals)
+}
+
+// testListenerConfig is a config as Load would leave it
+func testListenerConfig() *config.Config {
+	return &config.Config{
+		StrategyID:        "default",
+		HeaderBufferSize:  64,
+		BackfillBatchSize: 100,
+	}
+}
+
+func TestSlowProcessingLosesNoHeads(t *testing.T) {
+	const heads = 40
+
+	cfg := testListenerConfig()
+	cfg.HeaderBufferSize = 4
+	chain := &chainStub{delay: 20 * time.Millisecond}
+	l, db := newTestListener(t, cfg, chain)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	blocks := make(chan uint64, cfg.HeaderBufferSize)
+	go l.processBlocks(ctx, blocks)
+
+	// Heads arrive far faster than blocks are processed
+	for n := uint64(1); n <= heads; n++ {
+		l.enqueueBlock(ctx, blocks, n)
+	}
+
+	from, to, ok, err := db.GetMissedBlocks(ctx)
+	if err != nil || !ok {
+		t.Fatalf("GetMissedBlocks = ok %v, err %v, want the overflow recorded", ok, err)
+	}
+	missed := func(n uint64) bool { return n >= from && n <= to }
+
+	// Whatever was queued gets processed
+	deadline := time.Now().Add(5 * time.Second)
+	for n := uint64(1); n <= heads; n++ {
+		for !missed(n) && !chain.wasScanned(n) {
+			if time.Now().After(deadline) {
+				t.Fatalf("block %d neither processed nor deferred to the backfill", n)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	if to != heads {
+		t.Errorf("missed range ends at %d, want %d", to, heads)
+	}
+}
+
+func TestCrashMidBlockLosesNoSignals(t *testing.T) {
+	ctx := context.Background()
+	cfg := testListenerConfig()
+	chain := &chainStub{}
+	l, db := newTestListener(t, cfg, chain)
+	track(l, testMaker)
+	chain.logs = []types.Log{
+		filledLog(t, l, 10, 0, 1, 100, 50),
+		filledLog(t, l, 10, 1, 2, 200, 90),
+	}
+	if err := db.SetLastProcessedBlock(ctx, 9); err != nil {
+		t.Fatalf("SetLastProcessedBlock: %v", err)
+	}
+
+	// The first signal was stored, then the process died before the rest
+	// of the block and its checkpoint
+	first, err := l.processLog(chain.logs[0])
+	if err != nil {
+		t.Fatalf("processLog: %v", err)
+	}
+	if _, err := l.storeTradeSignal(ctx, first, first.TxHash); err != nil {
+		t.Fatalf("storeTradeSignal: %v", err)
+	}
+
+	// A live block that fails to process is handed to the backfill
+	chain.setFailing(true)
+	runCtx, cancel := context.WithCancel(ctx)
+	blocks := make(chan uint64, 1)
+	go l.processBlocks(runCtx, blocks)
+	blocks <- 10
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, _, ok, _ := db.GetMissedBlocks(ctx); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("failed block was not recorded for backfill")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if last, _ := db.GetLastProcessedBlock(ctx); last != 9 {
+		t.Errorf("checkpoint = %d after a failed block, want 9", last)
+	}
+
+	// On restart the block is backfilled, keeping the signal already stored
+	chain.setFailing(false)
+	restarted, err := NewPolymarketListener(cfg, db, events.New())
+	if err != nil {
+		t.Fatalf("NewPolymarketListener: %v", err)
+	}
+	track(restarted, testMaker)
+	restarted.loadMissed(ctx)
+	restarted.backfillMissed(ctx)
+
+	if n := countSignals(t, db); n != 2 {
+		t.Errorf("%d signals stored, want 2", n)
+	}
+	if last, _ := db.GetLastProcessedBlock(ctx); last != 10 {
+		t.Errorf("checkpoint = %d, want 10", last)
+	}
+	if _, _, ok, _ := db.GetMissedBlocks(ctx); ok {
+		t.Error("missed range still stored after the backfill")
+	}
+}
+
+func TestReSeenLogNotifiesOnce(t *testing.T) {
+	ctx := context.Background()
+	chain := &chainStub{}
+	l, db := newTestListener(t, testListenerConfig(), chain)
+	track(l, testMaker)
+	chain.logs = []types.Log{filledLog(t, l, 20, 0, 1, 100, 50)}
+
+	detected, unsubscribe := l.bus.Subscribe(events.SignalDetected, 10)
+	defer unsubscribe()
+
+	// The live subscription and a backfill both scan the block
+	for pass := 1; pass <= 2; pass++ {
+		inserted, err := l.processRange(ctx, 20, 20)
+		if err != nil {
+			t.Fatalf("pass %d: processRange: %v", pass, err)
+		}
+		if want := 2 - pass; len(inserted) != want {
+			t.Errorf("pass %d: %d signals inserted, want %d", pass, len(inserted), want)
+		}
+	}
+
+	// A crash between storing the signal and marking its log processed
+	// gets the signal to storeTradeSignal again
+	signal, err := l.processLog(chain.logs[0])
+	if err != nil {
+		t.Fatalf("processLog: %v", err)
+	}
+	if stored, err := l.storeTradeSignal(ctx, signal, signal.TxHash); err != nil || stored != nil {
+		t.Errorf("storing a seen signal again = %v, %v, want nil, nil", stored, err)
+	}
+
+	if n := len(detected); n != 1 {
+		t.Errorf("%d SignalDetected events, want 1", n)
+	}
+	if n := countSignals(t, db); n != 1 {
+		t.Errorf("%d signals stored, want 1", n)
+	}
+}
+
+func TestUntrackedLogNotMarkedProcessed(t *testing.T) {
+	ctx := context.Background()
+	chain := &chainStub{}
+	l, db := newTestListener(t, testListenerConfig(), chain)
+	chain.logs = []types.Log{filledLog(t, l, 30, 0, 1, 100, 50)}
+	vLog := chain.logs[0]
+
+	// Nobody in the fill is tracked yet
+	if inserted, err := l.processRange(ctx, 30, 30); err != nil || len(inserted) != 0 {
+		t.Fatalf("processRange = %d signals, %v, want none", len(inserted), err)
+	}
+	if seen, err := db.IsLogProcessed(ctx, vLog.TxHash.Hex(), vLog.Index); err != nil || seen {
+		t.Fatalf("IsLogProcessed = %v, %v, want an untracked fill left unprocessed", seen, err)
+	}
+
+	// The maker makes the leaderboard and the block is scanned again
+	track(l, testMaker)
+	if inserted, err := l.processRange(ctx, 30, 30); err != nil || len(inserted) != 1 {
+		t.Fatalf("processRange after tracking = %d signals, %v, want 1", len(inserted), err)
+	}
+	if seen, err := db.IsLogProcessed(ctx, vLog.TxHash.Hex(), vLog.Index); err != nil || !seen {
+		t.Errorf("IsLogProcessed = %v, %v, want the copied fill marked", seen, err)
+	}
+}
+
+func TestSyncStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		last     uint64 // 0 leaves no checkpoint
+		head     uint64
+		wantLag  uint64
+		backfill bool
+	}{
+		{"behind the head", 100, 150, 50, false},
+		{"caught up", 100, 100, 0, false},
+		{"cached head older than the checkpoint", 100, 90, 0, false},
+		{"no checkpoint yet", 0, 50, 50, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			l, db := newTestListener(t, testListenerConfig(), &chainStub{})
+			if tt.last > 0 {
+				if err := db.SetLastProcessedBlock(ctx, tt.last); err != nil {
+					t.Fatalf("SetLastProcessedBlock: %v", err)
+				}
+			}
+			l.chainHead.Store(tt.head)
+			l.backfilling.Store(tt.backfill)
+
+			status, err := l.SyncStatus(ctx)
+			if err != nil {
+				t.Fatalf("SyncStatus: %v", err)
+			}
+			if status.LastProcessedBlock != tt.last || status.ChainHead != tt.head || status.Lag != tt.wantLag {
+				t.Errorf("last %d head %d lag %d, want %d %d %d",
+					status.LastProcessedBlock, status.ChainHead, status.Lag, tt.last, tt.head, tt.wantLag)
+			}
+			if (status.LastProcessedAt != nil) != (tt.last > 0) {
+				t.Errorf("LastProcessedAt = %v with checkpoint %d", status.LastProcessedAt, tt.last)
+			}
+			if status.Backfilling != tt.backfill {
+				t.Errorf("Backfilling = %v, want %v", status.Backfilling, tt.backfill)
+			}
+		})
+	}
+}
+
+// captureLogs sends slog output to a buffer for the rest of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+func TestClockSkewAlert(t *testing.T) {
+	cfg := testListenerConfig()
+	cfg.MaxClockSkew = 30 * time.Second
+	l, _ := newTestListener(t, cfg, &chainStub{})
+	host := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return host }
+	logs := captureLogs(t)
+
+	steps := []struct {
+		name      string
+		blockTime time.Time
+		wantSkew  float64
+		wantAlert bool
+		wantLogs  int // alerts logged so far
+	}{
+		{"in range", host.Add(-10 * time.Second), 10, false, 0},
+		{"host clock ahead", host.Add(-2 * time.Minute), 120, true, 1},
+		{"still ahead alerts once", host.Add(-3 * time.Minute), 180, true, 1},
+		{"recovered", host, 0, false, 1},
+		{"host clock behind", host.Add(5 * time.Minute), -300, true, 2},
+	}
+
+	for _, step := range steps {
+		l.checkClockSkew(uint64(step.blockTime.Unix()))
+
+		status, err := l.SyncStatus(context.Background())
+		if err != nil {
+			t.Fatalf("%s: SyncStatus: %v", step.name, err)
+		}
+		if status.ClockSkewSeconds != step.wantSkew {
+			t.Errorf("%s: skew = %vs, want %vs", step.name, status.ClockSkewSeconds, step.wantSkew)
+		}
+		if l.skewAlerted.Load() != step.wantAlert {
+			t.Errorf("%s: alerted = %v, want %v", step.name, l.skewAlerted.Load(), step.wantAlert)
+		}
+		if n := strings.Count(logs.String(), "ALERT: host clock"); n != step.wantLogs {
+			t.Errorf("%s: %d skew alerts logged, want %d", step.name, n, step.wantLogs)
+		}
+	}
+	if !strings.Contains(logs.String(), "host clock back within range") {
+		t.Error("recovery was not logged")
+	}
+}
+
+func TestDedupeAcrossExchanges(t *testing.T) {
+	ctf, negRisk := CTF_EXCHANGE_ADDR, NEG_RISK_EXCHANGE_ADDR
+	fill := func(order, exchange string, logIndex uint) *TradeSignal {
+		return &TradeSignal{OrderHash: order, Exchange: exchange, LogIndex: logIndex}
+	}
+
+	tests := []struct {
+		name    string
+		signals []*TradeSignal
+		want    []uint // log indexes kept
+	}{
+		{"one order on both exchanges", []*TradeSignal{fill("0x1", ctf, 0), fill("0x1", negRisk, 1)}, []uint{0}},
+		{"first exchange wins", []*TradeSignal{fill("0x1", negRisk, 0), fill("0x1", ctf, 1), fill("0x1", negRisk, 2)}, []uint{0, 2}},
+		{"partial fills on one exchange", []*TradeSignal{fill("0x1", ctf, 0), fill("0x1", ctf, 1)}, []uint{0, 1}},
+		{"different orders", []*TradeSignal{fill("0x1", ctf, 0), fill("0x2", negRisk, 1)}, []uint{0, 1}},
+		{"exchange address casing", []*TradeSignal{fill("0x1", ctf, 0), fill("0x1", strings.ToLower(ctf), 1)}, []uint{0, 1}},
+		{"no order hash", []*TradeSignal{fill("", ctf, 0), fill("", negRisk, 1)}, []uint{0, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []uint
+			for _, signal := range dedupeAcrossExchanges(tt.signals) {
+				got = append(got, signal.LogIndex)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("kept log indexes %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFillOnBothExchangesStoredOnce(t *testing.T) {
+	tests := []struct {
+		name       string
+		keep       bool
+		wantAmount string
+	}{
+		{"deduped", false, "100000000"},
+		{"duplicates kept and merged", true, "200000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cfg := testListenerConfig()
+			cfg.KeepCrossExchangeDuplicates = tt.keep
+			chain := &chainStub{}
+			l, db := newTestListener(t, cfg, chain)
+			track(l, testMaker)
+
+			// The same order surfaces on the NegRisk exchange in the same tx
+			ctf := filledLog(t, l, 30, 0, 7, 100, 50)
+			negRisk := filledLog(t, l, 30, 1, 7, 100, 50)
+			negRisk.Address = common.HexToAddress(NEG_RISK_EXCHANGE_ADDR)
+			negRisk.TxHash = ctf.TxHash
+			chain.logs = []types.Log{ctf, negRisk}
+
+			if _, err := l.processRange(ctx, 30, 30); err != nil {
+				t.Fatalf("processRange: %v", err)
+			}
+			signals, err := db.GetSignalHistory(ctx, 10)
+			if err != nil {
+				t.Fatalf("GetSignalHistory: %v", err)
+			}
+			if len(signals) != 1 {
+				t.Fatalf("%d signals stored, want 1", len(signals))
+			}
+			if signals[0].Amount != tt.wantAmount || !strings.EqualFold(signals[0].Exchange, CTF_EXCHANGE_ADDR) {
+				t.Errorf("signal amount %s on %s, want %s on the CTF exchange", signals[0].Amount, signals[0].Exchange, tt.wantAmount)
+			}
+		})
+	}
+}
+
+func TestOutageBackfillRecoversMissedSignals(t *testing.T) {
+	ctx := context.Background()
+	chain := &chainStub{}
+	cfg := testListenerConfig()
+	cfg.BackfillBatchSize = 2
+	l, db := newTestListener(t, cfg, chain)
+	track(l, testMaker)
+	chain.logs = []types.Log{
+		filledLog(t, l, 101, 0, 1, 100, 50),
+		filledLog(t, l, 103, 0, 2, 100, 50),
+		filledLog(t, l, 104, 0, 3, 100, 50),
+		filledLog(t, l, 105, 0, 4, 100, 50),
+	}
+
+	// Block 101 was handled live before the outage
+	if _, err := l.processRange(ctx, 101, 101); err != nil {
+		t.Fatalf("processRange: %v", err)
+	}
+
+	// The RPC goes down: blocks 100-105 are dropped, and the first backfill
+	// fails and keeps them for a later pass
+	chain.setFailing(true)
+	l.markMissed(ctx, 100, 105)
+	l.backfillMissed(ctx)
+
+	if from, to, ok, _ := db.GetMissedBlocks(ctx); !ok || from != 100 || to != 105 {
+		t.Fatalf("missed range after a failed backfill = %d-%d ok %v, want 100-105 kept", from, to, ok)
+	}
+	failed := l.RecoveryReports()[0]
+	if len(failed.Failed) != 6 || len(failed.Recovered) != 0 {
+		t.Errorf("failed backfill reported %d failed blocks and %d recovered, want 6 and 0", len(failed.Failed), len(failed.Recovered))
+	}
+
+	// Once it's back the gap is recovered: the 3 signals we never stored
+	chain.setFailing(false)
+	l.backfillMissed(ctx)
+
+	report := l.RecoveryReports()[0]
+	if report.FromBlock != 100 || report.ToBlock != 105 || len(report.Failed) != 0 {
+		t.Errorf("recovery report %d-%d with %d failed blocks, want 100-105 and none", report.FromBlock, report.ToBlock, len(report.Failed))
+	}
+	var recovered []uint64
+	for _, sig := range report.Recovered {
+		recovered = append(recovered, sig.BlockNumber)
+	}
+	if fmt.Sprint(recovered) != "[103 104 105]" {
+		t.Errorf("recovered signals from blocks %v, want [103 104 105]", recovered)
+	}
+	if n := countSignals(t, db); n != 4 {
+		t.Errorf("%d signals stored, want 4", n)
+	}
+	if _, _, ok, _ := db.GetMissedBlocks(ctx); ok {
+		t.Error("missed range still stored after recovery")
+	}
+	if _, _, ok := l.takeMissed(); ok {
+		t.Error("missed range still pending in memory after recovery")
+	}
+}
@@ -0,0 +1,45 @@
+// internal/listener/logging.go
+package listener
+
+import (
+	"log/slog"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// logTopTraderFill always logs a tracked trader's fill, in full detail
+// unless SignalLogVerbosity is "summary"
+func (l *PolymarketListener) logTopTraderFill(event *OrderFilledEvent, vLog types.Log, makerIsTop, takerIsTop bool) {
+	if l.cfg.SignalLogVerbosity == "summary" {
+		slog.Info("top trader fill",
+			"maker", event.Maker.Hex(), "maker_top", makerIsTop,
+			"taker", event.Taker.Hex(), "taker_top", takerIsTop,
+			"tx_hash", vLog.TxHash.Hex(), "block_number", vLog.BlockNumber)
+		return
+	}
+
+	slog.Info("top trader fill",
+		"maker", event.Maker.Hex(), "maker_top", makerIsTop,
+		"taker", event.Taker.Hex(), "taker_top", takerIsTop,
+		"maker_asset", event.MakerAssetId.String(), "taker_asset", event.TakerAssetId.String(),
+		"maker_amount", event.MakerAmountFilled.String(), "taker_amount", event.TakerAmountFilled.String(),
+		"tx_hash", vLog.TxHash.Hex(), "log_index", vLog.Index, "block_number", vLog.BlockNumber)
+}
+
+// logSkippedFill logs 1 in SignalLogSampling fills from untracked traders,
+// which would otherwise drown the log at high activity
+func (l *PolymarketListener) logSkippedFill(maker, taker string, vLog types.Log) {
+	n := l.skippedFills.Add(1)
+	rate := uint64(l.cfg.SignalLogSampling)
+	if rate == 0 || n%rate != 0 {
+		return
+	}
+
+	if l.cfg.SignalLogVerbosity == "summary" {
+		slog.Info("skipped untracked fill", "tx_hash", vLog.TxHash.Hex(), "sample_rate", rate, "skipped", n)
+		return
+	}
+	slog.Info("skipped untracked fill", "maker", maker, "taker", taker,
+		"tx_hash", vLog.TxHash.Hex(), "log_index", vLog.Index, "block_number", vLog.BlockNumber,
+		"sample_rate", rate, "skipped", n)
+}
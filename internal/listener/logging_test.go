@@ -0,0 +1,56 @@
+// internal/listener/logging_test.go
+package listener
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestSignalLogSampling(t *testing.T) {
+	const untracked, tracked = 1000, 5
+	stranger := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+
+	tests := []struct {
+		name        string
+		sampling    int
+		wantSkipped int
+	}{
+		{"1 in 100", 100, 10},
+		{"every fill", 1, untracked},
+		{"off", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testListenerConfig()
+			cfg.SignalLogSampling = tt.sampling
+			cfg.SignalLogVerbosity = "full"
+			l, _ := newTestListener(t, cfg, &chainStub{})
+			track(l, testMaker)
+			logs := captureLogs(t)
+
+			for i := 0; i < untracked; i++ {
+				vLog := filledLog(t, l, 1, uint(i), 1, 10, 5)
+				vLog.Topics[2] = common.BytesToHash(stranger.Bytes())
+				if signal, err := l.processLog(vLog); err != nil || signal != nil {
+					t.Fatalf("untracked fill = %v, %v, want skipped", signal, err)
+				}
+				// Tracked fills are spread among them
+				if i%(untracked/tracked) == 0 {
+					if signal, err := l.processLog(filledLog(t, l, 2, uint(i), 2, 10, 5)); err != nil || signal == nil {
+						t.Fatalf("tracked fill = %v, %v, want a signal", signal, err)
+					}
+				}
+			}
+
+			if n := strings.Count(logs.String(), "skipped untracked fill"); n != tt.wantSkipped {
+				t.Errorf("%d untracked fills logged, want %d", n, tt.wantSkipped)
+			}
+			if n := strings.Count(logs.String(), "top trader fill"); n != tracked {
+				t.Errorf("%d tracked fills logged, want all %d", n, tracked)
+			}
+		})
+	}
+}
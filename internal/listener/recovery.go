@@ -0,0 +1,53 @@
+// internal/listener/recovery.go
+package listener
+
+import (
+	"sync"
+	"time"
+
+	"github.com/askwhyharsh/lazytrader/internal/database"
+)
+
+// Recovery reports kept in memory, oldest dropped first
+const maxRecoveryReports = 20
+
+// RecoveryReport describes one backfill of missed blocks: which blocks were
+// rescanned and which signals were genuinely missed, i.e. newly stored by
+// the backfill rather than already in the database
+type RecoveryReport struct {
+	FromBlock   uint64            `json:"from_block"`
+	ToBlock     uint64            `json:"to_block"`
+	StartedAt   time.Time         `json:"started_at"`
+	FinishedAt  time.Time         `json:"finished_at"`
+	Scanned     int               `json:"blocks_scanned"`
+	Failed      []uint64          `json:"failed_blocks"`
+	Interrupted bool              `json:"interrupted"` // Stopped early, the rest is backfilled later
+	Recovered   []database.Signal `json:"recovered"`
+}
+
+type recoveryLog struct {
+	mu      sync.Mutex
+	reports []RecoveryReport
+}
+
+func (r *recoveryLog) add(report RecoveryReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reports = append(r.reports, report)
+	if len(r.reports) > maxRecoveryReports {
+		r.reports = r.reports[len(r.reports)-maxRecoveryReports:]
+	}
+}
+
+// RecoveryReports returns the most recent backfill reports, newest first
+func (l *PolymarketListener) RecoveryReports() []RecoveryReport {
+	l.recoveries.mu.Lock()
+	defer l.recoveries.mu.Unlock()
+
+	reports := make([]RecoveryReport, 0, len(l.recoveries.reports))
+	for i := len(l.recoveries.reports) - 1; i >= 0; i-- {
+		reports = append(reports, l.recoveries.reports[i])
+	}
+	return reports
+}
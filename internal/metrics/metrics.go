@@ -0,0 +1,48 @@
+// internal/metrics/metrics.go
+package metrics
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/metrics/prometheus"
+)
+
+// registry holds every strategy's collectors, served by Handler
+var registry = metrics.NewRegistry()
+
+// Metrics are one strategy's collectors. The Prometheus names are
+// lazytrader_<strategy>_<metric>, with dashes in the strategy id turned into
+// underscores: lazytrader_default_trades_executed.
+type Metrics struct {
+	SignalsDetected   *metrics.Counter      // Newly stored trade signals
+	TradesExecuted    *metrics.Counter      // Including dry runs
+	TradesFailed      *metrics.Counter      // Orders that failed to submit
+	OpenPositions     *metrics.Gauge        // As of the last price refresh
+	VaultValue        *metrics.GaugeFloat64 // USDC balance plus open positions
+	LeaderboardSize   *metrics.Gauge        // Traders accepted from the last refresh
+	IngestionDegraded *metrics.Gauge        // 1 while leaderboard refreshes keep failing
+	BlocksProcessed   *metrics.Counter      // Scanned for OrderFilled logs
+}
+
+// For returns the collectors for a strategy, registering them on first use.
+// Every caller with the same id shares them.
+func For(strategyID string) *Metrics {
+	prefix := "lazytrader/" + strings.ReplaceAll(strategyID, "-", "_") + "/"
+	return &Metrics{
+		SignalsDetected:   metrics.GetOrRegisterCounter(prefix+"signals_detected", registry),
+		TradesExecuted:    metrics.GetOrRegisterCounter(prefix+"trades_executed", registry),
+		TradesFailed:      metrics.GetOrRegisterCounter(prefix+"trades_failed", registry),
+		OpenPositions:     metrics.GetOrRegisterGauge(prefix+"open_positions", registry),
+		VaultValue:        metrics.GetOrRegisterGaugeFloat64(prefix+"vault_value", registry),
+		LeaderboardSize:   metrics.GetOrRegisterGauge(prefix+"leaderboard_size", registry),
+		IngestionDegraded: metrics.GetOrRegisterGauge(prefix+"ingestion_degraded", registry),
+		BlocksProcessed:   metrics.GetOrRegisterCounter(prefix+"blocks_processed", registry),
+	}
+}
+
+// Handler serves every registered collector in the Prometheus text format
+func Handler() http.Handler {
+	return prometheus.Handler(registry)
+}
@@ -0,0 +1,210 @@
+// internal/notify/commands.go
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/askwhyharsh/lazytrader/internal/database"
+)
+
+// How long Telegram holds a getUpdates request open waiting for messages
+const longPollTimeout = 30 * time.Second
+
+// Trader is the executor surface the bot commands drive
+type Trader interface {
+	CalculateVaultValue(ctx context.Context) (float64, error)
+	Pause(ctx context.Context) error
+	Resume(ctx context.Context) error
+	Paused() bool
+}
+
+// Target is one strategy the bot reports on. Trader is nil while that
+// strategy's executor isn't running.
+type Target struct {
+	ID     string
+	DB     *database.DB
+	Trader Trader
+}
+
+type update struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Text string `json:"text"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+	} `json:"message"`
+}
+
+// Listen long-polls the Bot API for commands until ctx is cancelled and
+// answers /status, /leaderboard, /pause and /resume. Only messages from
+// the configured chat are acted on. It returns immediately when
+// notifications are disabled.
+func (n *TelegramNotifier) Listen(ctx context.Context, targets []Target) error {
+	if !n.Enabled() {
+		return nil
+	}
+
+	var offset int64
+	backoff := time.Second
+	for {
+		updates, err := n.getUpdates(ctx, offset)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			slog.Warn("failed to get Telegram updates, retrying", "backoff", backoff, "err", err)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, time.Minute)
+			continue
+		}
+		backoff = time.Second
+
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if u.Message == nil || !strings.HasPrefix(u.Message.Text, "/") {
+				continue
+			}
+			if u.Message.Chat.ID != n.chatID {
+				slog.Warn("ignoring Telegram command from unauthorized chat", "chat_id", u.Message.Chat.ID)
+				continue
+			}
+
+			reply := n.handleCommand(ctx, u.Message.Text, targets)
+			if err := n.Send(ctx, reply); err != nil && ctx.Err() == nil {
+				slog.Error("failed to reply to Telegram command", "err", err)
+			}
+		}
+	}
+}
+
+func (n *TelegramNotifier) getUpdates(ctx context.Context, offset int64) ([]update, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"offset":          offset,
+		"timeout":         int(longPollTimeout.Seconds()),
+		"allowed_updates": []string{"message"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		OK          bool     `json:"ok"`
+		Description string   `json:"description"`
+		Result      []update `json:"result"`
+	}
+	if err := n.call(ctx, "getUpdates", longPollTimeout+requestTimeout, body, &resp); err != nil {
+		return nil, err
+	}
+	if !resp.OK {
+		return nil, fmt.Errorf("telegram getUpdates failed: %s", resp.Description)
+	}
+	return resp.Result, nil
+}
+
+// handleCommand runs a command against every target and returns the reply
+func (n *TelegramNotifier) handleCommand(ctx context.Context, text string, targets []Target) string {
+	// "/status@my_bot extra" addresses the command to a bot in group chats
+	command, _, _ := strings.Cut(strings.Fields(text)[0], "@")
+
+	var sections []string
+	for _, t := range targets {
+		var section string
+		switch command {
+		case "/status":
+			section = n.status(ctx, t)
+		case "/leaderboard":
+			section = n.leaderboard(ctx, t)
+		case "/pause", "/resume":
+			section = setPaused(ctx, t, command == "/pause")
+		default:
+			return "Unknown command. Try /status, /leaderboard, /pause or /resume"
+		}
+		if len(targets) > 1 {
+			section = fmt.Sprintf("[%s]\n%s", t.ID, section)
+		}
+		sections = append(sections, section)
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+func (n *TelegramNotifier) status(ctx context.Context, t Target) string {
+	var lines []string
+
+	vault := "unavailable (executor not running)"
+	if t.Trader != nil {
+		if value, err := t.Trader.CalculateVaultValue(ctx); err != nil {
+			vault = "unavailable: " + err.Error()
+		} else {
+			vault = fmt.Sprintf("$%.2f", value)
+		}
+	}
+	lines = append(lines, "📊 Vault value: "+vault)
+
+	if open, err := t.DB.CountOpenPositions(ctx); err != nil {
+		lines = append(lines, "Open positions: unavailable")
+	} else {
+		lines = append(lines, fmt.Sprintf("Open positions: %d", open))
+	}
+
+	if pnl, err := t.DB.GetRealizedPnL(ctx); err != nil {
+		lines = append(lines, "Realized PnL: unavailable")
+	} else {
+		lines = append(lines, fmt.Sprintf("Realized PnL: $%.2f", pnl))
+	}
+
+	switch {
+	case t.Trader == nil:
+		lines = append(lines, "Copy trading: not running")
+	case t.Trader.Paused():
+		lines = append(lines, "Copy trading: paused")
+	default:
+		lines = append(lines, "Copy trading: active")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (n *TelegramNotifier) leaderboard(ctx context.Context, t Target) string {
+	traders, err := t.DB.GetTopTradersDetailed(ctx, n.topTraders)
+	if err != nil {
+		return "Failed to load the leaderboard"
+	}
+	if len(traders) == 0 {
+		return "No traders tracked yet"
+	}
+
+	lines := []string{fmt.Sprintf("🏆 Top %d tracked traders", len(traders))}
+	for i, tr := range traders {
+		name := tr.UserName
+		if name == "" {
+			name = shortAddress(tr.Address)
+		}
+		lines = append(lines, fmt.Sprintf("%d. %s  PnL $%.0f  win rate %.0f%%", i+1, name, tr.PnL, tr.WinRate*100))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func setPaused(ctx context.Context, t Target, pause bool) string {
+	if t.Trader == nil {
+		return "Copy trading is not running"
+	}
+	if pause {
+		if err := t.Trader.Pause(ctx); err != nil {
+			return "Failed to pause: " + err.Error()
+		}
+		return "⏸ Copy trading paused, new signals will be skipped"
+	}
+	if err := t.Trader.Resume(ctx); err != nil {
+		return "Failed to resume: " + err.Error()
+	}
+	return "▶️ Copy trading resumed"
+}
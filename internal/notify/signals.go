@@ -0,0 +1,153 @@
+// internal/notify/signals.go
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/askwhyharsh/lazytrader/internal/database"
+	"github.com/askwhyharsh/lazytrader/internal/events"
+	"github.com/askwhyharsh/lazytrader/internal/units"
+)
+
+const POLYGONSCAN_TX_URL = "https://polygonscan.com/tx/"
+
+// Undelivered events buffered per subscription
+const eventBuffer = 100
+
+// Telegram rejects messages longer than this
+const maxMessageLen = 4096
+
+// Start forwards detected signals and trade outcomes from the bus to
+// Telegram until ctx is cancelled. Past MaxMessages in one DigestWindow,
+// messages are held and sent together as a digest when the window ends.
+// It returns immediately when notifications are disabled.
+func (n *TelegramNotifier) Start(ctx context.Context, bus *events.Bus) error {
+	if !n.Enabled() {
+		return nil
+	}
+
+	signals, unsubscribeSignals := bus.Subscribe(events.SignalDetected, eventBuffer)
+	defer unsubscribeSignals()
+	executed, unsubscribeExecuted := bus.Subscribe(events.TradeExecuted, eventBuffer)
+	defer unsubscribeExecuted()
+	failed, unsubscribeFailed := bus.Subscribe(events.TradeFailed, eventBuffer)
+	defer unsubscribeFailed()
+
+	window := time.NewTicker(n.digestWindow)
+	defer window.Stop()
+
+	for {
+		var text string
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-window.C:
+			n.flushDigest(ctx)
+			continue
+		case event := <-signals:
+			sig, ok := event.Payload.(database.Signal)
+			if !ok {
+				continue
+			}
+			text = formatSignal(sig, n.tokens.Name(ctx, sig.TokenID))
+		case event := <-executed:
+			result, ok := event.Payload.(events.TradeResult)
+			if !ok {
+				continue
+			}
+			text = formatTrade(result)
+		case event := <-failed:
+			result, ok := event.Payload.(events.TradeResult)
+			if !ok {
+				continue
+			}
+			text = formatTrade(result)
+		}
+
+		if n.sentInWindow >= n.maxMessages {
+			n.pending = append(n.pending, text)
+			continue
+		}
+		n.sentInWindow++
+		if err := n.Send(ctx, text); err != nil && ctx.Err() == nil {
+			slog.Error("failed to send Telegram notification", "err", err)
+		}
+	}
+}
+
+// flushDigest sends the messages held back during the window as one, and
+// opens the next window
+func (n *TelegramNotifier) flushDigest(ctx context.Context) {
+	n.sentInWindow = 0
+	if len(n.pending) == 0 {
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📦 %d more notifications in the last %s:", len(n.pending), n.digestWindow)
+	for i, text := range n.pending {
+		line := "\n• " + strings.ReplaceAll(text, "\n", " | ")
+		if b.Len()+len(line) > maxMessageLen-64 {
+			fmt.Fprintf(&b, "\n…and %d more", len(n.pending)-i)
+			break
+		}
+		b.WriteString(line)
+	}
+	n.pending = nil
+
+	n.sentInWindow++
+	if err := n.Send(ctx, b.String()); err != nil && ctx.Err() == nil {
+		slog.Error("failed to send Telegram digest", "err", err)
+	}
+}
+
+// formatSignal describes a signal; market is its token's readable name
+func formatSignal(sig database.Signal, market string) string {
+	msg := fmt.Sprintf("🔔 %s signal from %s\nMarket: %s\nAmount: %.2f shares",
+		sig.Side, shortAddress(sig.Trader), market, units.ParseToFloat(sig.Amount, units.Decimals))
+	if sig.Price != "" {
+		msg += fmt.Sprintf(" @ $%.4f", units.ParseToFloat(sig.Price, units.Decimals))
+	}
+	if sig.TxHash != "" {
+		msg += "\nTx: " + POLYGONSCAN_TX_URL + sig.TxHash
+	}
+	return msg
+}
+
+func formatTrade(r events.TradeResult) string {
+	head := "✅ Copied trade executed"
+	switch {
+	case r.Error != "":
+		head = "❌ Copied trade failed"
+	case r.DryRun:
+		head = "🧪 Copied trade recorded (dry run)"
+	}
+
+	market := r.MarketID
+	if r.Question != "" {
+		market = fmt.Sprintf("%s (%s)", r.Question, r.Outcome)
+	}
+	msg := fmt.Sprintf("%s\nMarket: %s\n%s %.2f shares @ $%.4f",
+		head, market, strings.ToUpper(r.Side), r.Amount, r.Price)
+	switch {
+	case r.Error != "":
+		msg += "\nError: " + r.Error
+	case r.DryRun:
+		msg += "\nSimulated tx: " + r.TxHash
+	case r.TxHash != "":
+		msg += "\nTx: " + POLYGONSCAN_TX_URL + r.TxHash
+	}
+	return msg
+}
+
+// shortAddress abbreviates an address as 0x1234…abcd
+func shortAddress(address string) string {
+	if len(address) < 12 {
+		return address
+	}
+	return address[:6] + "…" + address[len(address)-4:]
+}
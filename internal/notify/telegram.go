@@ -0,0 +1,122 @@
+// internal/notify/telegram.go
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/askwhyharsh/lazytrader/internal/config"
+	"github.com/askwhyharsh/lazytrader/internal/polymarket"
+)
+
+const TELEGRAM_API = "https://api.telegram.org"
+
+// Timeout for Bot API requests, on top of any long-poll wait
+const requestTimeout = 10 * time.Second
+
+// TelegramNotifier sends messages to the configured chat through the Bot
+// API. Without a bot token or chat ID it is disabled and Send does nothing.
+type TelegramNotifier struct {
+	token      string
+	chatID     int64
+	topTraders int // Traders listed by /leaderboard
+	httpClient *http.Client
+	tokens     *polymarket.TokenResolver // Names tokens in signal messages
+
+	// Messages past maxMessages per digestWindow wait in pending
+	maxMessages  int
+	digestWindow time.Duration
+	sentInWindow int
+	pending      []string
+}
+
+func NewTelegramNotifier(cfg *config.Config, tokens *polymarket.TokenResolver) *TelegramNotifier {
+	n := &TelegramNotifier{
+		token:      cfg.TelegramBotToken,
+		chatID:     cfg.TelegramChatID,
+		topTraders: cfg.TopTradersCount,
+		httpClient: &http.Client{},
+		tokens:     tokens,
+
+		maxMessages:  cfg.TelegramMaxMessages,
+		digestWindow: cfg.TelegramDigestWindow,
+	}
+	if !n.Enabled() {
+		slog.Warn("telegram_bot_token or telegram_chat_id not set, Telegram notifications disabled")
+	}
+	return n
+}
+
+// Enabled reports whether a bot token and chat ID are configured
+func (n *TelegramNotifier) Enabled() bool {
+	return n.token != "" && n.chatID != 0
+}
+
+// Send posts text to the configured chat
+func (n *TelegramNotifier) Send(ctx context.Context, text string) error {
+	if !n.Enabled() {
+		return nil
+	}
+	return n.sendMessage(ctx, n.chatID, text)
+}
+
+func (n *TelegramNotifier) sendMessage(ctx context.Context, chatID int64, text string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := n.call(ctx, "sendMessage", requestTimeout, body, &resp); err != nil {
+		return err
+	}
+	if !resp.OK {
+		return fmt.Errorf("telegram sendMessage failed: %s", resp.Description)
+	}
+	return nil
+}
+
+// call POSTs a JSON body to a Bot API method and decodes the response.
+// Errors never include the request URL, which carries the bot token.
+func (n *TelegramNotifier) call(ctx context.Context, method string, timeout time.Duration, body []byte, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/bot%s/%s", TELEGRAM_API, n.token, method)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create telegram request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("telegram %s request failed", method)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read telegram response: %w", err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("telegram %s returned status %d", method, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+// internal/polygon/client.go
+package polygon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/url"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Client is a Polygon RPC client over a list of endpoints. It talks to one
+// endpoint at a time and moves on to the next when that one fails, wrapping
+// around the list.
+type Client struct {
+	urls []string
+
+	mu      sync.Mutex // Serializes failovers
+	current atomic.Int32
+	client  atomic.Pointer[ethclient.Client]
+}
+
+// Dial connects to the first reachable endpoint in urls, in order
+func Dial(ctx context.Context, urls []string) (*Client, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no Polygon RPC endpoints configured")
+	}
+
+	c := &Client{urls: urls}
+	if err := c.dialFrom(ctx, 0); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Eth returns the client for the current endpoint, replaced on failover
+func (c *Client) Eth() *ethclient.Client {
+	return c.client.Load()
+}
+
+// URL is the endpoint currently in use, without credentials or path
+func (c *Client) URL() string {
+	return redact(c.urls[c.current.Load()])
+}
+
+// Failover switches to the next endpoint that accepts a connection, trying
+// each one in turn and finally the current one again
+func (c *Client) Failover(ctx context.Context) error {
+	return c.failover(ctx, c.Eth())
+}
+
+// failover replaces failed unless a concurrent caller already has
+func (c *Client) failover(ctx context.Context, failed *ethclient.Client) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.Eth() != failed {
+		return nil
+	}
+	from := (int(c.current.Load()) + 1) % len(c.urls)
+	return c.dialFrom(ctx, from)
+}
+
+func (c *Client) dialFrom(ctx context.Context, from int) error {
+	var errs []error
+	for n := 0; n < len(c.urls); n++ {
+		i := (from + n) % len(c.urls)
+		client, err := ethclient.DialContext(ctx, c.urls[i])
+		if err == nil {
+			// Dialing HTTP endpoints doesn't touch the network, so check
+			// the endpoint actually answers before switching to it
+			_, err = client.ChainID(ctx)
+			if err != nil {
+				client.Close()
+			}
+		}
+		if err != nil {
+			slog.Warn("Polygon RPC endpoint unavailable", "endpoint", redact(c.urls[i]), "err", err)
+			errs = append(errs, err)
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+
+		c.current.Store(int32(i))
+		if old := c.client.Swap(client); old != nil {
+			old.Close()
+		}
+		slog.Info("connected to Polygon", "endpoint", redact(c.urls[i]))
+		return nil
+	}
+	return fmt.Errorf("failed to connect to Polygon: %w", errors.Join(errs...))
+}
+
+// Close closes the current connection
+func (c *Client) Close() {
+	if client := c.client.Load(); client != nil {
+		client.Close()
+	}
+}
+
+// ChainID, SuggestGasPrice and CallContract fail over and retry on endpoint
+// errors, at most once per endpoint
+
+func (c *Client) ChainID(ctx context.Context) (*big.Int, error) {
+	var id *big.Int
+	err := c.withFailover(ctx, func(client *ethclient.Client) (err error) {
+		id, err = client.ChainID(ctx)
+		return err
+	})
+	return id, err
+}
+
+func (c *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	var price *big.Int
+	err := c.withFailover(ctx, func(client *ethclient.Client) (err error) {
+		price, err = client.SuggestGasPrice(ctx)
+		return err
+	})
+	return price, err
+}
+
+func (c *Client) CallContract(ctx context.Context, msg ethereum.CallMsg, block *big.Int) ([]byte, error) {
+	var result []byte
+	err := c.withFailover(ctx, func(client *ethclient.Client) (err error) {
+		result, err = client.CallContract(ctx, msg, block)
+		return err
+	})
+	return result, err
+}
+
+func (c *Client) withFailover(ctx context.Context, call func(*ethclient.Client) error) error {
+	var err error
+	for attempt := 0; attempt < len(c.urls); attempt++ {
+		client := c.Eth()
+		if err = call(client); !IsEndpointError(ctx, err) {
+			return err
+		}
+		slog.Warn("Polygon RPC call failed, failing over", "endpoint", c.URL(), "err", err)
+		if ferr := c.failover(ctx, client); ferr != nil {
+			return fmt.Errorf("%w (%w)", err, ferr)
+		}
+	}
+	return err
+}
+
+// IsEndpointError reports whether err means the endpoint itself is failing
+// (unreachable, rate limited, erroring) rather than the node rejecting the
+// request, which any other endpoint would reject too
+func IsEndpointError(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return true
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		// -32005 is the conventional "limit exceeded" code
+		return rpcErr.ErrorCode() == -32005
+	}
+	return true
+}
+
+// redact drops everything but the scheme and host, since providers put API
+// keys in the path or query
+func redact(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "<invalid url>"
+	}
+	return u.Scheme + "://" + u.Host
+}
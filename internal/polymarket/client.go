@@ -0,0 +1,331 @@
+// internal/polymarket/client.go
+package polymarket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DATA_API_URL = "https://data-api.polymarket.com"
+)
+
+// ErrNonJSONResponse is returned when the API answers with something other
+// than JSON, typically a Cloudflare challenge page when we are being blocked
+var ErrNonJSONResponse = errors.New("non-JSON response from Polymarket API")
+
+// Max bytes of an unexpected response body to include in logs and errors
+const bodySnippetLen = 200
+
+// Longest Retry-After honored; anything longer is cut to this
+const maxRetryAfter = 2 * time.Minute
+
+// Client is a typed client for the Polymarket Data API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+
+	// Attempts per request and the initial backoff between them. Network
+	// errors, 429s and 5xxs are retried with jittered exponential backoff, or
+	// after the server's Retry-After when it sends one.
+	Retries int
+	Backoff time.Duration
+
+	// OnResponse, when set, receives every raw response body before parsing
+	OnResponse func(endpoint string, body []byte)
+}
+
+func NewClient(httpClient *http.Client) *Client {
+	return &Client{
+		baseURL:    DATA_API_URL,
+		httpClient: httpClient,
+		Retries:    1,
+		Backoff:    time.Second,
+	}
+}
+
+// WithBaseURL points the client at another host, e.g. a local stub
+func (c *Client) WithBaseURL(baseURL string) *Client {
+	c.baseURL = strings.TrimRight(baseURL, "/")
+	return c
+}
+
+// LeaderboardParams select a leaderboard page.
+// TimePeriod: "day", "week", "month"; OrderBy: "VOL" or "PNL"
+type LeaderboardParams struct {
+	TimePeriod string
+	OrderBy    string
+	Category   string
+	Limit      int
+	Offset     int
+}
+
+type LeaderboardEntry struct {
+	Rank         string  `json:"rank"`
+	ProxyWallet  string  `json:"proxyWallet"`
+	UserName     string  `json:"userName"`
+	Vol          float64 `json:"vol"`
+	PnL          float64 `json:"pnl"`
+	ProfileImage string  `json:"profileImage"`
+}
+
+// Position is a trader's holding in one outcome token
+type Position struct {
+	ProxyWallet  string  `json:"proxyWallet"`
+	Asset        string  `json:"asset"` // Outcome token ID
+	ConditionID  string  `json:"conditionId"`
+	Size         float64 `json:"size"`
+	AvgPrice     float64 `json:"avgPrice"`
+	CurPrice     float64 `json:"curPrice"`
+	CashPnL      float64 `json:"cashPnl"`
+	RealizedPnL  float64 `json:"realizedPnl"`
+	Title        string  `json:"title"`
+	Outcome      string  `json:"outcome"`
+	NegativeRisk bool    `json:"negativeRisk"`
+}
+
+// Trade is one fill from a wallet's trade history
+type Trade struct {
+	ProxyWallet     string  `json:"proxyWallet"`
+	Side            string  `json:"side"`  // "BUY" or "SELL"
+	Asset           string  `json:"asset"` // Outcome token ID
+	ConditionID     string  `json:"conditionId"`
+	Size            float64 `json:"size"`
+	Price           float64 `json:"price"`
+	Timestamp       int64   `json:"timestamp"` // Unix seconds
+	TransactionHash string  `json:"transactionHash"`
+	Outcome         string  `json:"outcome"`
+	Title           string  `json:"title"`
+}
+
+func (p LeaderboardParams) query() url.Values {
+	q := url.Values{}
+	q.Set("timePeriod", p.TimePeriod)
+	q.Set("orderBy", p.OrderBy)
+	q.Set("limit", fmt.Sprint(p.Limit))
+	q.Set("offset", fmt.Sprint(p.Offset))
+	category := p.Category
+	if category == "" {
+		category = "overall"
+	}
+	q.Set("category", category)
+	return q
+}
+
+// Leaderboard fetches one page of the trader leaderboard
+func (c *Client) Leaderboard(ctx context.Context, params LeaderboardParams) ([]LeaderboardEntry, error) {
+	var entries []LeaderboardEntry
+	if err := c.get(ctx, "/v1/leaderboard", params.query(), &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// TraderPositions fetches a wallet's current positions
+func (c *Client) TraderPositions(ctx context.Context, wallet string) ([]Position, error) {
+	q := url.Values{}
+	q.Set("user", wallet)
+
+	var positions []Position
+	if err := c.get(ctx, "/positions", q, &positions); err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+// ClosedPosition is a position a wallet has fully exited or that resolved
+type ClosedPosition struct {
+	ProxyWallet string  `json:"proxyWallet"`
+	Asset       string  `json:"asset"`
+	ConditionID string  `json:"conditionId"`
+	AvgPrice    float64 `json:"avgPrice"`
+	TotalBought float64 `json:"totalBought"`
+	RealizedPnL float64 `json:"realizedPnl"`
+	Title       string  `json:"title"`
+	Outcome     string  `json:"outcome"`
+	Timestamp   int64   `json:"timestamp"`
+}
+
+// Closed positions per page, the most the API returns at once
+const closedPositionsPageSize = 50
+
+// ClosedPositions returns up to max of a wallet's closed positions
+func (c *Client) ClosedPositions(ctx context.Context, wallet string, max int) ([]ClosedPosition, error) {
+	var all []ClosedPosition
+	for offset := 0; offset < max; offset += closedPositionsPageSize {
+		q := url.Values{}
+		q.Set("user", wallet)
+		q.Set("limit", fmt.Sprint(closedPositionsPageSize))
+		q.Set("offset", fmt.Sprint(offset))
+
+		var page []ClosedPosition
+		if err := c.get(ctx, "/closed-positions", q, &page); err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < closedPositionsPageSize {
+			break
+		}
+	}
+	if len(all) > max {
+		all = all[:max]
+	}
+	return all, nil
+}
+
+// TraderTrades fetches a wallet's most recent trades, newest first
+func (c *Client) TraderTrades(ctx context.Context, wallet string, limit int) ([]Trade, error) {
+	q := url.Values{}
+	q.Set("user", wallet)
+	q.Set("limit", fmt.Sprint(limit))
+
+	var trades []Trade
+	if err := c.get(ctx, "/trades", q, &trades); err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
+
+// URL builds the full request URL for an endpoint
+func (c *Client) URL(path string, query url.Values) string {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// get performs a GET with retries and decodes the JSON response into out
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	backoff := c.Backoff
+
+	var err error
+	for attempt := 1; attempt <= c.Retries; attempt++ {
+		var retry bool
+		var retryAfter time.Duration
+		retry, retryAfter, err = c.doGet(ctx, path, query, out)
+		if err == nil || !retry || attempt == c.Retries {
+			break
+		}
+
+		delay := jitter(backoff)
+		if retryAfter > 0 {
+			delay = min(retryAfter, maxRetryAfter)
+		}
+		slog.Warn("Polymarket API request failed, retrying", "path", path, "attempt", attempt,
+			"max_attempts", c.Retries, "err", err, "backoff", delay.Round(time.Millisecond))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		backoff *= 2
+	}
+	return err
+}
+
+// jitter spreads a backoff over [d/2, 3d/2) so clients that failed together
+// don't retry together
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d)))
+}
+
+// parseRetryAfter reads a Retry-After header in seconds or HTTP-date form,
+// returning 0 when absent or unparseable
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
+
+// doGet performs a single request and reports whether a failure is
+// retryable, and how long the server asked us to wait if it said
+func (c *Client) doGet(ctx context.Context, path string, query url.Values, out interface{}) (bool, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.URL(path, query), nil)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "lazytrader")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return true, 0, fmt.Errorf("failed to fetch from Polymarket API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return true, 0, fmt.Errorf("failed to read response: %w", err)
+	}
+	if c.OnResponse != nil {
+		c.OnResponse(path, body)
+	}
+
+	// Rate limits and server errors are worth retrying whatever the body,
+	// proxies in front of the API answer those with HTML
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return true, parseRetryAfter(resp.Header.Get("Retry-After")),
+			fmt.Errorf("API returned status %d: %s", resp.StatusCode, snippet(body))
+	}
+
+	if err := checkJSONResponse(resp, body); err != nil {
+		return false, 0, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return false, 0, fmt.Errorf("API returned status %d: %s", resp.StatusCode, body)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return false, 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return false, 0, nil
+}
+
+// checkJSONResponse rejects bodies that aren't JSON. Cloudflare challenge
+// pages are HTML and sometimes arrive with a 200 and a JSON content type, so
+// the body itself is sniffed as well as the header.
+func checkJSONResponse(resp *http.Response, body []byte) error {
+	contentType := strings.ToLower(resp.Header.Get("Content-Type"))
+	trimmed := bytes.TrimSpace(body)
+	looksJSON := len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{')
+
+	if strings.Contains(contentType, "json") && looksJSON {
+		return nil
+	}
+
+	slog.Warn("Polymarket API returned a non-JSON body", "status", resp.StatusCode,
+		"content_type", contentType, "body", snippet(trimmed))
+	return fmt.Errorf("%w (status %d, content-type %q)", ErrNonJSONResponse, resp.StatusCode, contentType)
+}
+
+// snippet shortens a response body for logs and errors
+func snippet(body []byte) string {
+	s := string(bytes.TrimSpace(body))
+	if len(s) > bodySnippetLen {
+		s = s[:bodySnippetLen] + "..."
+	}
+	return s
+}
@@ -0,0 +1,268 @@
+// internal/polymarket/client_test.go
+package polymarket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// apiStub serves the Data API from handle, keyed on the request, and records
+// every request URL
+type apiStub struct {
+	handle func(r *http.Request) string
+
+	mu       sync.Mutex
+	requests []*url.URL
+}
+
+func (a *apiStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	a.requests = append(a.requests, r.URL)
+	a.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(a.handle(r)))
+}
+
+func newAPIStub(t *testing.T, handle func(r *http.Request) string) (*Client, *apiStub) {
+	t.Helper()
+	api := &apiStub{handle: handle}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	return NewClient(srv.Client()).WithBaseURL(srv.URL + "/"), api
+}
+
+func TestURL(t *testing.T) {
+	c := NewClient(http.DefaultClient).WithBaseURL("https://example.com/")
+
+	if got := c.URL("/trades", nil); got != "https://example.com/trades" {
+		t.Errorf("URL without query = %s", got)
+	}
+	q := url.Values{}
+	q.Set("user", "0xabc")
+	q.Set("limit", "5")
+	if got := c.URL("/trades", q); got != "https://example.com/trades?limit=5&user=0xabc" {
+		t.Errorf("URL with query = %s", got)
+	}
+}
+
+func TestLeaderboard(t *testing.T) {
+	c, api := newAPIStub(t, func(r *http.Request) string {
+		return `[{"rank":"1","proxyWallet":"0xabc","userName":"alice","vol":1500.5,"pnl":320.25}]`
+	})
+
+	entries, err := c.Leaderboard(context.Background(), LeaderboardParams{TimePeriod: "week", OrderBy: "PNL", Limit: 20, Offset: 40})
+	if err != nil {
+		t.Fatalf("Leaderboard: %v", err)
+	}
+	want := LeaderboardEntry{Rank: "1", ProxyWallet: "0xabc", UserName: "alice", Vol: 1500.5, PnL: 320.25}
+	if len(entries) != 1 || entries[0] != want {
+		t.Errorf("entries = %+v, want [%+v]", entries, want)
+	}
+
+	got := api.requests[0]
+	if got.Path != "/v1/leaderboard" {
+		t.Errorf("path = %s, want /v1/leaderboard", got.Path)
+	}
+	for key, want := range map[string]string{"timePeriod": "week", "orderBy": "PNL", "limit": "20", "offset": "40", "category": "overall"} {
+		if v := got.Query().Get(key); v != want {
+			t.Errorf("%s = %q, want %q", key, v, want)
+		}
+	}
+}
+
+func TestTraderPositionsAndTrades(t *testing.T) {
+	c, api := newAPIStub(t, func(r *http.Request) string {
+		if r.URL.Path == "/positions" {
+			return `[{"asset":"123","conditionId":"0xc","size":10,"avgPrice":0.4,"curPrice":0.5,"outcome":"Yes","negativeRisk":true}]`
+		}
+		return `[{"side":"BUY","asset":"123","size":5,"price":0.45,"timestamp":1700000000,"transactionHash":"0xtx"}]`
+	})
+	ctx := context.Background()
+
+	positions, err := c.TraderPositions(ctx, "0xabc")
+	if err != nil {
+		t.Fatalf("TraderPositions: %v", err)
+	}
+	if len(positions) != 1 || positions[0].Asset != "123" || positions[0].Size != 10 || !positions[0].NegativeRisk {
+		t.Errorf("positions = %+v", positions)
+	}
+
+	trades, err := c.TraderTrades(ctx, "0xabc", 25)
+	if err != nil {
+		t.Fatalf("TraderTrades: %v", err)
+	}
+	if len(trades) != 1 || trades[0].Side != "BUY" || trades[0].Price != 0.45 || trades[0].Timestamp != 1700000000 {
+		t.Errorf("trades = %+v", trades)
+	}
+
+	if q := api.requests[0].Query(); api.requests[0].Path != "/positions" || q.Get("user") != "0xabc" {
+		t.Errorf("positions request = %s", api.requests[0])
+	}
+	if q := api.requests[1].Query(); api.requests[1].Path != "/trades" || q.Get("user") != "0xabc" || q.Get("limit") != "25" {
+		t.Errorf("trades request = %s", api.requests[1])
+	}
+}
+
+func TestClosedPositionsPages(t *testing.T) {
+	// 120 closed positions in total, served a page at a time
+	c, api := newAPIStub(t, func(r *http.Request) string {
+		var offset, limit int
+		fmt.Sscan(r.URL.Query().Get("offset"), &offset)
+		fmt.Sscan(r.URL.Query().Get("limit"), &limit)
+		body := "["
+		for n := offset; n < min(offset+limit, 120); n++ {
+			if n > offset {
+				body += ","
+			}
+			body += fmt.Sprintf(`{"asset":"%d","realizedPnl":1}`, n)
+		}
+		return body + "]"
+	})
+
+	tests := []struct {
+		max       int
+		want      int
+		wantPages int
+	}{
+		{max: 30, want: 30, wantPages: 1},
+		{max: 100, want: 100, wantPages: 2},
+		{max: 500, want: 120, wantPages: 3},
+	}
+	for _, tt := range tests {
+		api.requests = nil
+		closed, err := c.ClosedPositions(context.Background(), "0xabc", tt.max)
+		if err != nil {
+			t.Fatalf("ClosedPositions(%d): %v", tt.max, err)
+		}
+		if len(closed) != tt.want {
+			t.Errorf("ClosedPositions(%d) = %d positions, want %d", tt.max, len(closed), tt.want)
+		}
+		if len(api.requests) != tt.wantPages {
+			t.Errorf("ClosedPositions(%d) fetched %d pages, want %d", tt.max, len(api.requests), tt.wantPages)
+		}
+	}
+}
+
+type stubResponse struct {
+	status      int
+	contentType string
+	body        string
+}
+
+// stubServer answers requests with responses in turn, repeating the last,
+// and counts the requests it gets
+func stubServer(t *testing.T, responses ...stubResponse) (*Client, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(hits.Add(1)) - 1
+		resp := responses[min(i, len(responses)-1)]
+		w.Header().Set("Content-Type", resp.contentType)
+		w.WriteHeader(resp.status)
+		w.Write([]byte(resp.body))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient(srv.Client()).WithBaseURL(srv.URL)
+	c.Retries = 3
+	c.Backoff = time.Millisecond
+	return c, &hits
+}
+
+func TestGetRetries(t *testing.T) {
+	var (
+		ok       = stubResponse{http.StatusOK, "application/json", `{"value":1}`}
+		down     = stubResponse{http.StatusServiceUnavailable, "text/html", "<html>bad gateway</html>"}
+		limited  = stubResponse{http.StatusTooManyRequests, "application/json", `{"error":"slow down"}`}
+		bad      = stubResponse{http.StatusBadRequest, "application/json", `{"error":"bad request"}`}
+		notFound = stubResponse{http.StatusNotFound, "application/json", `{"error":"not found"}`}
+		html     = stubResponse{http.StatusOK, "application/json", "<html>challenge</html>"}
+		garbled  = stubResponse{http.StatusOK, "application/json", `{"value":`}
+	)
+
+	tests := []struct {
+		name      string
+		responses []stubResponse
+		wantHits  int32
+		wantErr   bool
+		nonJSON   bool
+	}{
+		{name: "success", responses: []stubResponse{ok}, wantHits: 1},
+		{name: "server error then success", responses: []stubResponse{down, ok}, wantHits: 2},
+		{name: "rate limited then success", responses: []stubResponse{limited, ok}, wantHits: 2},
+		{name: "server error every time", responses: []stubResponse{down}, wantHits: 3, wantErr: true},
+		{name: "client error not retried", responses: []stubResponse{bad, ok}, wantHits: 1, wantErr: true},
+		{name: "not found not retried", responses: []stubResponse{notFound, ok}, wantHits: 1, wantErr: true},
+		{name: "html body not retried", responses: []stubResponse{html, ok}, wantHits: 1, wantErr: true, nonJSON: true},
+		{name: "undecodable body not retried", responses: []stubResponse{garbled, ok}, wantHits: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, hits := stubServer(t, tt.responses...)
+
+			var out struct{ Value int }
+			err := c.get(context.Background(), "/test", nil, &out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.nonJSON && !errors.Is(err, ErrNonJSONResponse) {
+				t.Errorf("err = %v, want ErrNonJSONResponse", err)
+			}
+			if got := hits.Load(); got != tt.wantHits {
+				t.Errorf("requests = %d, want %d", got, tt.wantHits)
+			}
+			if !tt.wantErr && out.Value != 1 {
+				t.Errorf("decoded value = %d, want 1", out.Value)
+			}
+		})
+	}
+}
+
+func TestGetStopsOnCancel(t *testing.T) {
+	c, hits := stubServer(t, stubResponse{http.StatusBadGateway, "text/plain", "down"})
+	c.Backoff = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	var out struct{}
+	if err := c.get(ctx, "/test", nil, &out); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", 0},
+		{"5", 5 * time.Second},
+		{"0", 0},
+		{"-3", 0},
+		{"soon", 0},
+		{time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.header); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+
+	future := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(future); got <= 0 || got > time.Minute {
+		t.Errorf("parseRetryAfter(%q) = %v, want up to a minute", future, got)
+	}
+}
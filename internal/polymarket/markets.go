@@ -0,0 +1,147 @@
+// internal/polymarket/markets.go
+package polymarket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sync"
+)
+
+const (
+	GAMMA_API_URL = "https://gamma-api.polymarket.com"
+)
+
+// ErrMarketNotFound is returned for a token no market lists
+var ErrMarketNotFound = errors.New("no market found")
+
+// Market is a Gamma API market. Outcomes and ClobTokenIDs line up: the
+// token at index i pays out on outcome i.
+type Market struct {
+	ConditionID  string     `json:"conditionId"`
+	Question     string     `json:"question"`
+	Slug         string     `json:"slug"`
+	Category     string     `json:"category"` // Not set on every market
+	Outcomes     stringList `json:"outcomes"`
+	ClobTokenIDs stringList `json:"clobTokenIds"`
+	NegRisk      bool       `json:"negRisk"`
+}
+
+// stringList decodes the Gamma API's JSON-encoded string arrays, e.g.
+// "[\"Yes\", \"No\"]", as well as plain arrays
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var encoded string
+	if err := json.Unmarshal(data, &encoded); err == nil {
+		if encoded == "" {
+			*l = nil
+			return nil
+		}
+		data = []byte(encoded)
+	}
+	return json.Unmarshal(data, (*[]string)(l))
+}
+
+// MarketByToken fetches the market an outcome token belongs to. The client
+// must point at the Gamma API (WithBaseURL(GAMMA_API_URL)).
+func (c *Client) MarketByToken(ctx context.Context, tokenID string) (*Market, error) {
+	q := url.Values{}
+	q.Set("clob_token_ids", tokenID)
+
+	var markets []Market
+	if err := c.get(ctx, "/markets", q, &markets); err != nil {
+		return nil, err
+	}
+	for i := range markets {
+		if slices.Contains(markets[i].ClobTokenIDs, tokenID) {
+			return &markets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: token %s", ErrMarketNotFound, tokenID)
+}
+
+// Token is an outcome token resolved to its market
+type Token struct {
+	MarketID string // Condition ID
+	Slug     string
+	Category string
+	Question string
+	Outcome  string // "Yes", "No", or a candidate/team in multi-outcome markets
+	NegRisk  bool
+}
+
+// String reads "Will X happen? (Yes)"
+func (t Token) String() string {
+	return fmt.Sprintf("%s (%s)", t.Question, t.Outcome)
+}
+
+// TokenResolver maps outcome token IDs to their market and outcome through
+// the Gamma API, caching every token it has resolved. Markets don't change
+// what their tokens mean, so entries never expire.
+type TokenResolver struct {
+	client *Client
+
+	mu    sync.Mutex
+	cache map[string]Token
+}
+
+// NewTokenResolver resolves tokens with client, which must point at the
+// Gamma API
+func NewTokenResolver(client *Client) *TokenResolver {
+	return &TokenResolver{client: client, cache: make(map[string]Token)}
+}
+
+// Resolve returns the market and outcome of tokenID. Failures aren't
+// cached, the next call tries again. A nil *TokenResolver resolves nothing.
+func (r *TokenResolver) Resolve(ctx context.Context, tokenID string) (Token, error) {
+	if r == nil {
+		return Token{}, fmt.Errorf("no token resolver")
+	}
+
+	r.mu.Lock()
+	token, ok := r.cache[tokenID]
+	r.mu.Unlock()
+	if ok {
+		return token, nil
+	}
+
+	market, err := r.client.MarketByToken(ctx, tokenID)
+	if err != nil {
+		return Token{}, fmt.Errorf("failed to resolve token %s: %w", tokenID, err)
+	}
+
+	// Cache every outcome of the market, the other side is often traded next
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, id := range market.ClobTokenIDs {
+		if i >= len(market.Outcomes) {
+			break
+		}
+		r.cache[id] = Token{
+			MarketID: market.ConditionID,
+			Slug:     market.Slug,
+			Category: market.Category,
+			Question: market.Question,
+			Outcome:  market.Outcomes[i],
+			NegRisk:  market.NegRisk,
+		}
+	}
+	token, ok = r.cache[tokenID]
+	if !ok {
+		return Token{}, fmt.Errorf("failed to resolve token %s: market %s lists no outcome for it", tokenID, market.ConditionID)
+	}
+	return token, nil
+}
+
+// Name is tokenID's readable name, or the raw ID when it can't be resolved
+func (r *TokenResolver) Name(ctx context.Context, tokenID string) string {
+	token, err := r.Resolve(ctx, tokenID)
+	if err != nil {
+		return tokenID
+	}
+	return token.String()
+}
@@ -0,0 +1,222 @@
+// internal/server/auth.go
+package server
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/websocket"
+)
+
+// How long an issued nonce can be used to sign a request
+const nonceTTL = 5 * time.Minute
+
+// Unexpired nonces kept per address; issuing more drops the oldest
+const maxNoncesPerAddress = 16
+
+// Nonces each client IP may request per minute. Issuance is open, so this
+// keeps one client from cycling out another's pending nonces.
+const (
+	nonceRateLimit = 10
+	nonceBurst     = 5
+)
+
+var errInvalidSignature = errors.New("invalid wallet signature")
+
+type nonceEntry struct {
+	nonce   string
+	expires time.Time
+}
+
+// nonceStore hands out single-use nonces per wallet address. Several can be
+// outstanding at once, so requesting a nonce never invalidates another
+// client's pending sign-in.
+type nonceStore struct {
+	mu     sync.Mutex
+	nonces map[string][]nonceEntry // Oldest first
+}
+
+func newNonceStore() *nonceStore {
+	return &nonceStore{nonces: make(map[string][]nonceEntry)}
+}
+
+// issue creates a fresh nonce for an address, alongside its outstanding ones
+func (n *nonceStore) issue(address string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	nonce := hex.EncodeToString(buf)
+	now := time.Now()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	key := strings.ToLower(address)
+	entries := append(unexpired(n.nonces[key], now), nonceEntry{nonce: nonce, expires: now.Add(nonceTTL)})
+	if len(entries) > maxNoncesPerAddress {
+		entries = entries[len(entries)-maxNoncesPerAddress:]
+	}
+	n.nonces[key] = entries
+
+	// Addresses nobody signed in as would otherwise stay forever
+	for k, e := range n.nonces {
+		if len(unexpired(e, now)) == 0 {
+			delete(n.nonces, k)
+		}
+	}
+	return nonce, nil
+}
+
+// consume invalidates and returns the address's outstanding nonce that
+// matches reports true for, so each signature can be used once
+func (n *nonceStore) consume(address string, matches func(nonce string) bool) (string, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	key := strings.ToLower(address)
+	entries := unexpired(n.nonces[key], time.Now())
+	for i, entry := range entries {
+		if matches(entry.nonce) {
+			n.nonces[key] = append(entries[:i:i], entries[i+1:]...)
+			return entry.nonce, true
+		}
+	}
+	n.nonces[key] = entries
+	return "", false
+}
+
+// unexpired returns the entries still valid at now, reusing entries' array
+func unexpired(entries []nonceEntry, now time.Time) []nonceEntry {
+	kept := entries[:0]
+	for _, e := range entries {
+		if now.Before(e.expires) {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// authMessage is the text a wallet signs (personal_sign) to prove ownership
+func authMessage(nonce string) string {
+	return "Sign in to LazyTrader\nNonce: " + nonce
+}
+
+// verifyWalletSignature checks that signature is a personal_sign, by the
+// address's key, over one of the address's outstanding nonces, and uses
+// that nonce up
+func (s *Server) verifyWalletSignature(address, signature string) error {
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("%w: bad address", errInvalidSignature)
+	}
+
+	sig, err := hexutil.Decode(signature)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return fmt.Errorf("%w: malformed signature", errInvalidSignature)
+	}
+	// Wallets return V as 27/28; ecrecover wants 0/1
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	want := common.HexToAddress(address)
+	_, ok := s.nonces.consume(address, func(nonce string) bool {
+		pubKey, err := crypto.SigToPub(accounts.TextHash([]byte(authMessage(nonce))), sig)
+		return err == nil && crypto.PubkeyToAddress(*pubKey) == want
+	})
+	if !ok {
+		return fmt.Errorf("%w: not signed by the address over an outstanding nonce (missing, used or expired)", errInvalidSignature)
+	}
+	return nil
+}
+
+func (s *Server) handleAuthNonce(w http.ResponseWriter, r *http.Request) {
+	address := r.URL.Query().Get("address")
+	if !common.IsHexAddress(address) {
+		s.jsonError(w, "Invalid address", http.StatusBadRequest)
+		return
+	}
+
+	if ok, wait := s.nonceLimiter.allow(clientIP(r), time.Now()); !ok {
+		s.tooManyRequests(w, "Too many nonce requests, try again later", wait)
+		return
+	}
+
+	nonce, err := s.nonces.issue(address)
+	if err != nil {
+		s.jsonError(w, "Failed to issue nonce", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonResponse(w, Response{Success: true, Data: map[string]interface{}{
+		"address":    address,
+		"nonce":      nonce,
+		"message":    authMessage(nonce),
+		"expires_in": int(nonceTTL.Seconds()),
+	}})
+}
+
+// Routes open without an API key: health checks and Prometheus scrapes
+var publicPaths = map[string]bool{
+	"/health":  true,
+	"/livez":   true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// requireAPIKey rejects requests without an "Authorization: Bearer <key>"
+// header matching api_key, or for WebSocket handshakes an api_key query
+// parameter. It lets everything through when no key is set.
+func (s *Server) requireAPIKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.cfg.APIKey == "" || publicPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			key = ""
+			// Browsers can't set headers on a WebSocket handshake
+			if websocket.IsWebSocketUpgrade(r) {
+				key = r.URL.Query().Get("api_key")
+			}
+		}
+		if key == "" {
+			s.jsonError(w, "missing API key", http.StatusUnauthorized)
+			return
+		}
+		// Hashing first keeps the comparison constant-time even when the
+		// lengths differ
+		got, want := sha256.Sum256([]byte(key)), sha256.Sum256([]byte(s.cfg.APIKey))
+		if subtle.ConstantTimeCompare(got[:], want[:]) != 1 {
+			s.jsonError(w, "invalid API key", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireOperator only lets through requests signed by the configured
+// operator wallet: a personal_sign over a nonce from GET /auth/nonce for
+// wallet_address, sent in the X-Signature header
+func (s *Server) requireOperator(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := s.verifyWalletSignature(s.cfg.WalletAddress, r.Header.Get("X-Signature")); err != nil {
+			s.jsonError(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,194 @@
+// internal/server/auth_test.go
+package server
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/askwhyharsh/lazytrader/internal/config"
+)
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+func TestRequireAPIKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		apiKey    string
+		path      string
+		header    string
+		query     string
+		websocket bool
+		want      int
+	}{
+		{name: "no key configured", path: "/positions", want: http.StatusOK},
+		{name: "valid bearer", apiKey: "secret", path: "/positions", header: "Bearer secret", want: http.StatusOK},
+		{name: "wrong key", apiKey: "secret", path: "/positions", header: "Bearer nope", want: http.StatusUnauthorized},
+		{name: "missing header", apiKey: "secret", path: "/positions", want: http.StatusUnauthorized},
+		{name: "not a bearer", apiKey: "secret", path: "/positions", header: "secret", want: http.StatusUnauthorized},
+		{name: "public path", apiKey: "secret", path: "/health", want: http.StatusOK},
+		{name: "websocket query key", apiKey: "secret", path: "/ws/signals", query: "api_key=secret", websocket: true, want: http.StatusOK},
+		{name: "query key without upgrade", apiKey: "secret", path: "/positions", query: "api_key=secret", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{cfg: &config.Config{APIKey: tt.apiKey}}
+			url := tt.path
+			if tt.query != "" {
+				url += "?" + tt.query
+			}
+			r := httptest.NewRequest(http.MethodGet, url, nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			if tt.websocket {
+				r.Header.Set("Connection", "Upgrade")
+				r.Header.Set("Upgrade", "websocket")
+			}
+			w := httptest.NewRecorder()
+
+			s.requireAPIKey(okHandler).ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+// newWallet returns a fresh key and its address
+func newWallet(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, crypto.PubkeyToAddress(key.PublicKey).Hex()
+}
+
+// signNonce signs the auth message for nonce the way wallets do, V as 27/28
+func signNonce(t *testing.T, key *ecdsa.PrivateKey, nonce string) string {
+	t.Helper()
+	sig, err := crypto.Sign(accounts.TextHash([]byte(authMessage(nonce))), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return hexutil.Encode(sig)
+}
+
+func TestVerifyWalletSignature(t *testing.T) {
+	key, address := newWallet(t)
+	s := &Server{nonces: newNonceStore()}
+
+	first, _ := s.nonces.issue(address)
+	second, _ := s.nonces.issue(address)
+
+	// Issuing a second nonce leaves the first usable
+	sig := signNonce(t, key, first)
+	if err := s.verifyWalletSignature(address, sig); err != nil {
+		t.Fatalf("first nonce: %v", err)
+	}
+	if err := s.verifyWalletSignature(address, sig); !errors.Is(err, errInvalidSignature) {
+		t.Errorf("reused signature err = %v, want errInvalidSignature", err)
+	}
+	if err := s.verifyWalletSignature(address, signNonce(t, key, second)); err != nil {
+		t.Errorf("second nonce: %v", err)
+	}
+
+	// A signature by another key over an outstanding nonce is rejected
+	third, _ := s.nonces.issue(address)
+	other, _ := newWallet(t)
+	if err := s.verifyWalletSignature(address, signNonce(t, other, third)); !errors.Is(err, errInvalidSignature) {
+		t.Errorf("other key err = %v, want errInvalidSignature", err)
+	}
+
+	for _, bad := range []string{"", "0x1234", "not hex"} {
+		if err := s.verifyWalletSignature(address, bad); !errors.Is(err, errInvalidSignature) {
+			t.Errorf("signature %q err = %v, want errInvalidSignature", bad, err)
+		}
+	}
+}
+
+func TestNonceStore(t *testing.T) {
+	const address = "0x00000000000000000000000000000000000000Aa"
+	n := newNonceStore()
+
+	var issued []string
+	for i := 0; i < maxNoncesPerAddress+2; i++ {
+		nonce, err := n.issue(address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		issued = append(issued, nonce)
+	}
+	is := func(want string) func(string) bool {
+		return func(nonce string) bool { return nonce == want }
+	}
+
+	// The oldest are dropped past the cap
+	if _, ok := n.consume(address, is(issued[0])); ok {
+		t.Error("nonce past the per-address cap was still outstanding")
+	}
+	// Addresses match regardless of casing, and each nonce is single use
+	last := issued[len(issued)-1]
+	if got, ok := n.consume("0x00000000000000000000000000000000000000aa", is(last)); !ok || got != last {
+		t.Errorf("consume latest = %q, %v", got, ok)
+	}
+	if _, ok := n.consume(address, is(last)); ok {
+		t.Error("nonce consumed twice")
+	}
+	if _, ok := n.consume(address, is(issued[2])); !ok {
+		t.Error("older outstanding nonce was invalidated by newer ones")
+	}
+}
+
+func TestNonceExpiry(t *testing.T) {
+	const address = "0x00000000000000000000000000000000000000aa"
+	n := newNonceStore()
+	nonce, _ := n.issue(address)
+
+	n.mu.Lock()
+	n.nonces[address][0].expires = time.Now().Add(-time.Second)
+	n.mu.Unlock()
+
+	if _, ok := n.consume(address, func(string) bool { return true }); ok {
+		t.Errorf("expired nonce %s was consumed", nonce)
+	}
+}
+
+func TestHandleAuthNonceRateLimit(t *testing.T) {
+	s := &Server{
+		cfg:          &config.Config{},
+		nonces:       newNonceStore(),
+		nonceLimiter: newRateLimiter(nonceRateLimit, nonceBurst),
+	}
+	request := func(address string) int {
+		r := httptest.NewRequest(http.MethodGet, "/auth/nonce?address="+address, nil)
+		r.RemoteAddr = "203.0.113.7:4000"
+		w := httptest.NewRecorder()
+		s.handleAuthNonce(w, r)
+		return w.Code
+	}
+
+	if code := request("nope"); code != http.StatusBadRequest {
+		t.Errorf("invalid address status = %d, want 400", code)
+	}
+	for i := 0; i < nonceBurst; i++ {
+		if code := request("0x00000000000000000000000000000000000000aa"); code != http.StatusOK {
+			t.Fatalf("request %d status = %d, want 200", i+1, code)
+		}
+	}
+	if code := request("0x00000000000000000000000000000000000000bb"); code != http.StatusTooManyRequests {
+		t.Errorf("status past the burst = %d, want 429", code)
+	}
+}
@@ -0,0 +1,54 @@
+// internal/server/cors.go
+package server
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// What a cross-origin page may send; X-Signature and Idempotency-Key are
+// the headers wallet-signed and retried requests carry
+var (
+	corsMethods = strings.Join([]string{http.MethodGet, http.MethodPost, http.MethodOptions}, ", ")
+	corsHeaders = strings.Join([]string{"Authorization", "Content-Type", "X-Signature", "Idempotency-Key"}, ", ")
+)
+
+// How long browsers may cache a preflight response, in seconds
+const corsMaxAge = "600"
+
+// cors adds CORS headers for requests from allowed_origins and answers their
+// preflight OPTIONS requests itself, ahead of routing and the API key check
+// (browsers send preflights without credentials). Without allowed origins it
+// adds nothing, leaving the API same-origin only.
+func (s *Server) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || len(s.cfg.AllowedOrigins) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		if !s.originAllowed(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Methods", corsMethods)
+		w.Header().Set("Access-Control-Allow-Headers", corsHeaders)
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Max-Age", corsMaxAge)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// originAllowed reports whether allowed_origins lets origin call the API
+func (s *Server) originAllowed(origin string) bool {
+	return slices.Contains(s.cfg.AllowedOrigins, "*") || slices.Contains(s.cfg.AllowedOrigins, origin)
+}
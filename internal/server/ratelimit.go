@@ -0,0 +1,118 @@
+// internal/server/ratelimit.go
+package server
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Requests a client can make back to back before the per-minute rate applies
+const rateLimitBurst = 20
+
+// Idle clients' buckets are dropped after this long, by then they're full
+const rateLimitIdle = 10 * time.Minute
+
+// bucket is a token bucket: it holds up to burst tokens, refilled at the
+// limiter's rate, and each request takes one
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter keeps a token bucket per key (client IP)
+type rateLimiter struct {
+	perSecond float64
+	burst     float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func newRateLimiter(perMinute float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		perSecond: perMinute / 60,
+		burst:     float64(burst),
+		buckets:   make(map[string]*bucket),
+	}
+}
+
+// allow takes a token from key's bucket. When it's empty it returns false
+// and how long until the next token.
+func (l *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > rateLimitIdle {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > rateLimitIdle {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.perSecond)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.perSecond * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// clientIP is the request's remote address. X-Forwarded-For is ignored,
+// any client could set it to dodge the limit.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// tooManyRequests answers 429 with a Retry-After in whole seconds
+func (s *Server) tooManyRequests(w http.ResponseWriter, message string, wait time.Duration) {
+	w.Header().Set("Retry-After", fmt.Sprint(int(math.Ceil(wait.Seconds()))))
+	s.jsonError(w, message, http.StatusTooManyRequests)
+}
+
+// rateLimit allows each client IP http_rate_limit requests per minute.
+// Health checks and /metrics aren't limited, probes and scrapers poll them.
+func (s *Server) rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if publicPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if ok, wait := s.limiter.allow(clientIP(r), time.Now()); !ok {
+			s.tooManyRequests(w, "Rate limit exceeded", wait)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// refreshCooldown lets leaderboard refreshes through at most once per
+// leaderboardRefreshCooldown, whoever asks. Each one is a full ingestion
+// cycle against the Polymarket API.
+func (s *Server) refreshCooldown(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if ok, wait := s.refreshLimiter.allow("", time.Now()); !ok {
+			s.tooManyRequests(w, "Leaderboard was refreshed recently, try again later", wait)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,105 @@
+// internal/server/ratelimit_test.go
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	l := newRateLimiter(60, 3) // One token a second
+	now := time.Unix(1_700_000_000, 0)
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.allow("a", now); !ok {
+			t.Fatalf("request %d within burst was refused", i+1)
+		}
+	}
+	ok, wait := l.allow("a", now)
+	if ok {
+		t.Fatal("request past the burst was allowed")
+	}
+	if wait != time.Second {
+		t.Errorf("wait = %v, want 1s", wait)
+	}
+
+	// Other keys have their own bucket
+	if ok, _ := l.allow("b", now); !ok {
+		t.Error("another key was limited")
+	}
+
+	// Tokens refill at the rate, up to the burst
+	if ok, _ := l.allow("a", now.Add(time.Second)); !ok {
+		t.Error("request after refill was refused")
+	}
+	later := now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.allow("a", later); !ok {
+			t.Fatalf("request %d after idle was refused", i+1)
+		}
+	}
+	if ok, _ := l.allow("a", later); ok {
+		t.Error("bucket refilled past the burst")
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	s := &Server{limiter: newRateLimiter(60, 1)}
+	handler := s.rateLimit(okHandler)
+	request := func(path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.RemoteAddr = "198.51.100.1:1234"
+		r.Header.Set("X-Forwarded-For", "10.0.0.1")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := request("/positions"); w.Code != http.StatusOK {
+		t.Fatalf("first request status = %d", w.Code)
+	}
+	w := request("/positions")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request status = %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After = %q, want 1", got)
+	}
+
+	// Health checks and scrapes aren't limited
+	if w := request("/health"); w.Code != http.StatusOK {
+		t.Errorf("/health status = %d, want 200", w.Code)
+	}
+}
+
+func TestRefreshCooldown(t *testing.T) {
+	s, _, _ := newTestServer(t)
+	handler := s.refreshCooldown(okHandler)
+
+	// Different callers share the one cooldown
+	for i, ip := range []string{"198.51.100.1:1234", "198.51.100.2:1234"} {
+		r := httptest.NewRequest(http.MethodPost, "/leaderboard/refresh", nil)
+		r.RemoteAddr = ip
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		want := http.StatusOK
+		if i > 0 {
+			want = http.StatusTooManyRequests
+		}
+		if w.Code != want {
+			t.Fatalf("refresh %d from %s status = %d, want %d", i+1, ip, w.Code, want)
+		}
+		if i > 0 {
+			if got := w.Header().Get("Retry-After"); got != "60" {
+				t.Errorf("Retry-After = %q, want 60", got)
+			}
+			if !strings.Contains(w.Body.String(), `"error"`) {
+				t.Errorf("429 body %s isn't a JSON error", w.Body)
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// internal/server/readiness.go
+package server
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// readiness tracks the critical components that must be up before trading
+// and mutating endpoints are served
+type readiness struct {
+	mu      sync.Mutex
+	pending map[string]bool
+}
+
+func newReadiness() *readiness {
+	return &readiness{pending: make(map[string]bool)}
+}
+
+// Require registers a component the server waits for and returns the func
+// the component calls once it's up. Register everything before Start.
+func (s *Server) Require(component string) (markReady func()) {
+	s.readiness.mu.Lock()
+	s.readiness.pending[component] = true
+	s.readiness.mu.Unlock()
+
+	return func() {
+		s.readiness.mu.Lock()
+		delete(s.readiness.pending, component)
+		s.readiness.mu.Unlock()
+	}
+}
+
+// notReady lists the components still starting, sorted
+func (s *Server) notReady() []string {
+	s.readiness.mu.Lock()
+	defer s.readiness.mu.Unlock()
+
+	pending := make([]string, 0, len(s.readiness.pending))
+	for component := range s.readiness.pending {
+		pending = append(pending, component)
+	}
+	sort.Strings(pending)
+	return pending
+}
+
+// requireReady answers 503 until every registered component is up. It wraps
+// the routes that trade or change state; read-only ones answer throughout.
+func (s *Server) requireReady(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if pending := s.notReady(); len(pending) > 0 {
+			w.Header().Set("Retry-After", "5")
+			s.jsonError(w, "Service not ready, waiting for: "+strings.Join(pending, ", "), http.StatusServiceUnavailable)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleLivez reports the process is up, whatever its components are doing
+func (s *Server) handleLivez(w http.ResponseWriter, r *http.Request) {
+	s.jsonResponse(w, Response{Success: true, Data: "OK"})
+}
+
+// handleReadyz reports whether trading endpoints are being served
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if pending := s.notReady(); len(pending) > 0 {
+		s.jsonError(w, "Service not ready, waiting for: "+strings.Join(pending, ", "), http.StatusServiceUnavailable)
+		return
+	}
+	s.jsonResponse(w, Response{Success: true, Data: "OK"})
+}
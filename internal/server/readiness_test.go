@@ -0,0 +1,58 @@
+// internal/server/readiness_test.go
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReadinessGate(t *testing.T) {
+	s, _, handler := newTestServer(t)
+	markReady := s.Require("listener")
+	key, address := newWallet(t)
+
+	// deposit is a signed POST /deposit over a fresh nonce
+	deposit := func() *http.Request {
+		nonce, _ := s.nonces.issue(address)
+		body := fmt.Sprintf(`{"address":%q,"amount":10,"signature":%q}`, address, signNonce(t, key, nonce))
+		return httptest.NewRequest(http.MethodPost, "/deposit", strings.NewReader(body))
+	}
+	get := func(path string) func() *http.Request {
+		return func() *http.Request { return httptest.NewRequest(http.MethodGet, path, nil) }
+	}
+
+	tests := []struct {
+		name        string
+		request     func() *http.Request
+		beforeReady int
+	}{
+		{"deposit", deposit, http.StatusServiceUnavailable},
+		{"readyz", get("/readyz"), http.StatusServiceUnavailable},
+		{"health", get("/health"), http.StatusOK},
+		{"livez", get("/livez"), http.StatusOK},
+		{"positions", get("/positions"), http.StatusOK},
+	}
+
+	for _, ready := range []bool{false, true} {
+		if ready {
+			markReady()
+		}
+		for _, tt := range tests {
+			want := tt.beforeReady
+			if ready {
+				want = http.StatusOK
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, tt.request())
+			if w.Code != want {
+				t.Errorf("%s with ready %v = %d %s, want %d", tt.name, ready, w.Code, w.Body, want)
+			}
+			if w.Code == http.StatusServiceUnavailable && !strings.Contains(w.Body.String(), "listener") {
+				t.Errorf("%s: 503 body %s doesn't name the pending component", tt.name, w.Body)
+			}
+		}
+	}
+}
@@ -2,22 +2,61 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
 	"net/http"
-	// "strconv"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/gorilla/mux"
 	"github.com/askwhyharsh/lazytrader/internal/config"
 	"github.com/askwhyharsh/lazytrader/internal/database"
-	// "github.com/askwhyharsh/lazytrader/internal/executor"
+	"github.com/askwhyharsh/lazytrader/internal/events"
+	"github.com/askwhyharsh/lazytrader/internal/executor"
+	"github.com/askwhyharsh/lazytrader/internal/ingestion"
+	"github.com/askwhyharsh/lazytrader/internal/listener"
+	"github.com/askwhyharsh/lazytrader/internal/metrics"
+	"github.com/askwhyharsh/lazytrader/internal/polymarket"
+	"github.com/askwhyharsh/lazytrader/internal/strategy"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/mux"
 )
 
+// How long POST /leaderboard/refresh waits for the refresh, kept under the
+// default HTTP write timeout
+const leaderboardRefreshTimeout = 25 * time.Second
+
+// Minimum time between POST /leaderboard/refresh calls
+const leaderboardRefreshCooldown = time.Minute
+
 type Server struct {
-	cfg  *config.Config
-	db   *database.DB
-	// exec *executor.Executor
+	cfg      *config.Config
+	db       *database.DB
+	exec     *executor.Executor // nil while the executor isn't running
+	listener *listener.PolymarketListener
+	ingestor *ingestion.Ingestion
+	metrics  *metrics.Metrics
+	bus      *events.Bus // Detected signals, streamed on /ws/signals
+	tokens   *polymarket.TokenResolver
+
+	nonces     *nonceStore
+	readiness  *readiness
+	httpServer *http.Server
+
+	limiter        *rateLimiter // Per client IP, across all routes
+	refreshLimiter *rateLimiter // POST /leaderboard/refresh, across all callers
+	nonceLimiter   *rateLimiter // GET /auth/nonce, per client IP
+
+	// Closed on Shutdown, which doesn't wait for WebSocket connections
+	closing chan struct{}
+
+	// Per-strategy servers mounted under /strategies/{id}, in config order
+	strategies  map[string]*Server
+	strategyIDs []string
 }
 
 type Response struct {
@@ -26,9 +65,12 @@ type Response struct {
 	Error   string      `json:"error,omitempty"`
 }
 
+// Wallet-owned actions carry a personal_sign signature over the nonce from
+// GET /auth/nonce, verified against Address before acting
 type DepositRequest struct {
-	Address string  `json:"address"`
-	Amount  float64 `json:"amount"`
+	Address   string  `json:"address"`
+	Amount    float64 `json:"amount"`
+	Signature string  `json:"signature"`
 }
 
 type TradeRequestAPI struct {
@@ -40,37 +82,161 @@ type TradeRequestAPI struct {
 	Price    float64 `json:"price"`
 }
 
-func New(cfg *config.Config, db *database.DB) *Server {
-	return &Server{
-		cfg:  cfg,
-		db:   db,
-		// exec: exec,
+// PositionView is a position with its unrealized PnL at the current price
+type PositionView struct {
+	database.Position
+	UnrealizedPnL float64
+}
+
+// PositionsResponse lists positions with their PnL totals. Closed positions
+// carry no unrealized PnL.
+type PositionsResponse struct {
+	Positions          []PositionView `json:"positions"`
+	TotalUnrealizedPnL float64        `json:"total_unrealized_pnl"`
+	TotalRealizedPnL   float64        `json:"total_realized_pnl"`
+}
+
+type PnLSummary struct {
+	Realized   float64 `json:"realized"`
+	Unrealized float64 `json:"unrealized"`
+	Total      float64 `json:"total"`
+}
+
+// Stats summarizes whether copy trading is paying off
+type Stats struct {
+	RealizedPnL    float64 `json:"realized_pnl"` // From fully exited positions
+	OpenPositions  int     `json:"open_positions"`
+	TotalDeposited float64 `json:"total_deposited"`
+}
+
+type TraderSettingsRequest struct {
+	Multiplier float64 `json:"multiplier"` // 0 removes the override
+}
+
+type BacktestRequest struct {
+	strategy.Params
+	Limit int `json:"limit"` // Most recent signals to replay
+}
+
+func New(cfg *config.Config, db *database.DB, bus *events.Bus, tokens *polymarket.TokenResolver, exec *executor.Executor, lister *listener.PolymarketListener, ingestor *ingestion.Ingestion) *Server {
+	s := &Server{
+		cfg:      cfg,
+		db:       db,
+		exec:     exec,
+		listener: lister,
+		ingestor: ingestor,
+		bus:      bus,
+		tokens:   tokens,
+		// Registered up front so /metrics lists every series from the start
+		metrics:        metrics.For(cfg.StrategyID),
+		nonces:         newNonceStore(),
+		readiness:      newReadiness(),
+		limiter:        newRateLimiter(float64(cfg.HTTPRateLimit), min(rateLimitBurst, cfg.HTTPRateLimit)),
+		refreshLimiter: newRateLimiter(float64(time.Minute)/float64(leaderboardRefreshCooldown), 1),
+		nonceLimiter:   newRateLimiter(nonceRateLimit, nonceBurst),
+		closing:        make(chan struct{}),
+		httpServer: &http.Server{
+			Addr:              fmt.Sprintf(":%s", cfg.HTTPPort),
+			ReadTimeout:       cfg.HTTPReadTimeout,
+			ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+			WriteTimeout:      cfg.HTTPWriteTimeout,
+			IdleTimeout:       cfg.HTTPIdleTimeout,
+		},
 	}
+	s.httpServer.RegisterOnShutdown(func() { close(s.closing) })
+	return s
+}
+
+// AddStrategy mounts a strategy's components under /strategies/{id}. The
+// components passed to New keep serving the unprefixed routes.
+func (s *Server) AddStrategy(id string, cfg *config.Config, db *database.DB, exec *executor.Executor, lister *listener.PolymarketListener, ingestor *ingestion.Ingestion) {
+	if s.strategies == nil {
+		s.strategies = make(map[string]*Server)
+	}
+	child := New(cfg, db, s.bus, s.tokens, exec, lister, ingestor)
+	child.nonces = s.nonces
+	child.nonceLimiter = s.nonceLimiter
+	child.readiness = s.readiness
+	s.strategies[id] = child
+	s.strategyIDs = append(s.strategyIDs, id)
 }
 
 func (s *Server) Start() error {
+	if s.cfg.APIKey == "" {
+		slog.Warn("api_key not set, the HTTP API is unauthenticated")
+	}
+
+	s.httpServer.Handler = s.routes()
+	slog.Info("starting HTTP server", "addr", s.httpServer.Addr)
+	if err := s.httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// routes builds the full API handler, middleware included
+func (s *Server) routes() http.Handler {
 	r := mux.NewRouter()
+	r.Use(s.rateLimit, s.requireAPIKey)
 
 	// API routes
 	r.HandleFunc("/health", s.handleHealth).Methods("GET")
+	r.HandleFunc("/livez", s.handleLivez).Methods("GET")
+	r.HandleFunc("/readyz", s.handleReadyz).Methods("GET")
+	r.HandleFunc("/auth/nonce", s.handleAuthNonce).Methods("GET")
+	r.HandleFunc("/strategies", s.handleStrategies).Methods("GET")
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
+	r.HandleFunc("/ws/signals", s.handleSignalStream).Methods("GET")
+	s.registerRoutes(r)
+	for _, id := range s.strategyIDs {
+		s.strategies[id].registerRoutes(r.PathPrefix("/strategies/" + id).Subrouter())
+	}
+	return s.cors(r)
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests to
+// finish, or for ctx to end. Start returns once it's called.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
+
+// registerRoutes adds the strategy-scoped routes
+func (s *Server) registerRoutes(r *mux.Router) {
 	// r.HandleFunc("/vault/info", s.handleVaultInfo).Methods("GET")
-	// r.HandleFunc("/users", s.handleGetUsers).Methods("GET")
+	r.HandleFunc("/users", s.handleGetUsers).Methods("GET")
 	// r.HandleFunc("/users/{address}", s.handleGetUser).Methods("GET")
-	// r.HandleFunc("/deposit", s.handleDeposit).Methods("POST")
-	// r.HandleFunc("/positions", s.handleGetPositions).Methods("GET")
-	// r.HandleFunc("/trades/execute", s.handleExecuteTrade).Methods("POST")
+	r.HandleFunc("/deposit", s.requireReady(s.handleDeposit)).Methods("POST")
+	// No /withdraw yet: withdrawals go through the vault contract, which
+	// holds the deposits, and are out of scope for this API
+	r.HandleFunc("/positions", s.handleGetPositions).Methods("GET")
+	r.HandleFunc("/trades", s.handleGetTrades).Methods("GET")
+	r.HandleFunc("/trades/execute", s.requireReady(s.requireOperator(s.handleExecuteTrade))).Methods("POST")
 	r.HandleFunc("/leaderboard", s.handleLeaderboard).Methods("GET")
-	r.HandleFunc("/leaderboard/refresh", s.handleRefreshLeaderboard).Methods("POST")
-
-	// addr := fmt.Sprintf(":%s", s.cfg.HTTPPort)
-	log.Printf("Starting HTTP server on %s", ":4000")
-	return http.ListenAndServe(":4000", r)
+	r.HandleFunc("/leaderboard/refresh", s.requireReady(s.refreshCooldown(s.handleRefreshLeaderboard))).Methods("POST")
+	r.HandleFunc("/leaderboard/decisions", s.handleLeaderboardDecisions).Methods("GET")
+	r.HandleFunc("/pause", s.requireReady(s.requireOperator(s.handlePause))).Methods("POST")
+	r.HandleFunc("/resume", s.requireReady(s.requireOperator(s.handleResume))).Methods("POST")
+	r.HandleFunc("/traders/{address}/settings", s.requireReady(s.requireOperator(s.handleTraderSettings))).Methods("POST")
+	r.HandleFunc("/simulate/backtest", s.handleBacktest).Methods("POST")
+	r.HandleFunc("/positions/stale", s.handleStalePositions).Methods("GET")
+	r.HandleFunc("/sync", s.handleSync).Methods("GET")
+	r.HandleFunc("/pnl", s.handlePnL).Methods("GET")
+	r.HandleFunc("/stats", s.handleStats).Methods("GET")
+	r.HandleFunc("/admin/recovery-report", s.requireOperator(s.handleRecoveryReport)).Methods("GET")
+	r.HandleFunc("/admin/vacuum", s.requireReady(s.requireOperator(s.handleVacuum))).Methods("POST")
+	r.HandleFunc("/admin/recompute-winrates", s.requireReady(s.requireOperator(s.handleRecomputeWinRates))).Methods("POST")
 }
 
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	s.jsonResponse(w, Response{Success: true, Data: "OK"})
 }
 
+// handleStrategies lists the strategies mounted under /strategies/{id}
+func (s *Server) handleStrategies(w http.ResponseWriter, r *http.Request) {
+	ids := append([]string{}, s.strategyIDs...)
+	s.jsonResponse(w, Response{Success: true, Data: ids})
+}
+
 // func (s *Server) handleVaultInfo(w http.ResponseWriter, r *http.Request) {
 // 	balance, _ := s.exec.GetVaultBalance()
 // 	shares, _ := s.exec.CalculateTotalShares()
@@ -84,16 +250,21 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 // 	s.jsonResponse(w, Response{Success: true, Data: info})
 // }
 
-// func (s *Server) handleGetUsers(w http.ResponseWriter, r *http.Request) {
-// 	// TODO: Implement get all users
-// 	s.jsonResponse(w, Response{Success: true, Data: []interface{}{}})
-// }
+func (s *Server) handleGetUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := s.db.GetAllUsers(r.Context())
+	if err != nil {
+		s.jsonError(w, fmt.Sprintf("Failed to get users: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonResponse(w, Response{Success: true, Data: users})
+}
 
 // func (s *Server) handleGetUser(w http.ResponseWriter, r *http.Request) {
 // 	vars := mux.Vars(r)
 // 	address := vars["address"]
 
-// 	user, err := s.db.GetUser(address)
+// 	user, err := s.db.GetUser(r.Context(), address)
 // 	if err != nil {
 // 		s.jsonError(w, fmt.Sprintf("Failed to get user: %v", err), http.StatusInternalServerError)
 // 		return
@@ -107,55 +278,285 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 // 	s.jsonResponse(w, Response{Success: true, Data: user})
 // }
 
-// func (s *Server) handleDeposit(w http.ResponseWriter, r *http.Request) {
-// 	var req DepositRequest
-// 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-// 		s.jsonError(w, "Invalid request", http.StatusBadRequest)
-// 		return
-// 	}
+func (s *Server) handleDeposit(w http.ResponseWriter, r *http.Request) {
+	var req DepositRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if req.Amount <= 0 || math.IsInf(req.Amount, 0) || math.IsNaN(req.Amount) {
+		s.jsonError(w, "Amount must be positive", http.StatusBadRequest)
+		return
+	}
+	if !common.IsHexAddress(req.Address) {
+		s.jsonError(w, "Invalid address", http.StatusBadRequest)
+		return
+	}
 
-// 	user, err := s.db.CreateUser(req.Address, req.Amount)
-// 	if err != nil {
-// 		s.jsonError(w, fmt.Sprintf("Failed to create user: %v", err), http.StatusInternalServerError)
-// 		return
-// 	}
+	if err := s.verifyWalletSignature(req.Address, req.Signature); err != nil {
+		s.jsonError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
 
-// 	s.jsonResponse(w, Response{Success: true, Data: user})
-// }
+	user, err := s.db.Deposit(r.Context(), req.Address, req.Amount)
+	if err != nil {
+		s.jsonError(w, fmt.Sprintf("Failed to record deposit: %v", err), http.StatusInternalServerError)
+		return
+	}
 
-// func (s *Server) handleGetPositions(w http.ResponseWriter, r *http.Request) {
-// 	positions, err := s.db.GetOpenPositions()
-// 	if err != nil {
-// 		s.jsonError(w, fmt.Sprintf("Failed to get positions: %v", err), http.StatusInternalServerError)
-// 		return
-// 	}
+	s.jsonResponse(w, Response{Success: true, Data: user})
+}
 
-// 	s.jsonResponse(w, Response{Success: true, Data: positions})
-// }
+// handleGetPositions lists open positions with their unrealized PnL, or
+// closed ones with ?status=closed
+func (s *Server) handleGetPositions(w http.ResponseWriter, r *http.Request) {
+	var positions []database.Position
+	var err error
+	switch status := r.URL.Query().Get("status"); status {
+	case "", "open":
+		positions, err = s.db.GetOpenPositions(r.Context())
+	case "closed":
+		positions, err = s.db.GetClosedPositions(r.Context())
+	default:
+		s.jsonError(w, fmt.Sprintf("Invalid status %q, must be 'open' or 'closed'", status), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		s.jsonError(w, fmt.Sprintf("Failed to get positions: %v", err), http.StatusInternalServerError)
+		return
+	}
 
-// func (s *Server) handleExecuteTrade(w http.ResponseWriter, r *http.Request) {
-// 	var req TradeRequestAPI
-// 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-// 		s.jsonError(w, "Invalid request", http.StatusBadRequest)
-// 		return
-// 	}
+	resp := PositionsResponse{Positions: make([]PositionView, 0, len(positions))}
+	for _, p := range positions {
+		view := PositionView{Position: s.withMarketNames(r.Context(), p)}
+		if p.Status == "open" {
+			view.UnrealizedPnL = (p.CurrentPrice - p.AvgPrice) * p.Amount
+		}
+		resp.Positions = append(resp.Positions, view)
+		resp.TotalUnrealizedPnL += view.UnrealizedPnL
+		resp.TotalRealizedPnL += p.RealizedPnL
+	}
+	s.jsonResponse(w, Response{Success: true, Data: resp})
+}
 
-// 	tradeReq := executor.TradeRequest{
-// 		MarketID: req.MarketID,
-// 		TokenID:  req.TokenID,
-// 		Outcome:  req.Outcome,
-// 		Side:     req.Side,
-// 		Amount:   req.Amount,
-// 		Price:    req.Price,
-// 	}
+// handleGetTrades lists copied trades, filtered by trader_address, status
+// and side, paged with limit (default 100) and offset
+func (s *Server) handleGetTrades(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := database.TradeFilter{
+		TraderAddress: q.Get("trader_address"),
+		Status:        q.Get("status"),
+		Side:          q.Get("side"),
+		Limit:         100,
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > 1000 {
+			s.jsonError(w, "limit must be between 1 and 1000", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+	if v := q.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			s.jsonError(w, "offset must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		filter.Offset = offset
+	}
 
-// 	if err := s.exec.ExecuteTrade(tradeReq); err != nil {
-// 		s.jsonError(w, fmt.Sprintf("Failed to execute trade: %v", err), http.StatusInternalServerError)
-// 		return
-// 	}
+	trades, err := s.db.GetTrades(r.Context(), filter)
+	if errors.Is(err, database.ErrInvalidAddress) {
+		s.jsonError(w, "Invalid trader_address", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		s.jsonError(w, fmt.Sprintf("Failed to get trades: %v", err), http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, Response{Success: true, Data: trades})
+}
 
-// 	s.jsonResponse(w, Response{Success: true, Data: "Trade executed"})
-// }
+// handleSync reports listener progress against the chain head
+func (s *Server) handleSync(w http.ResponseWriter, r *http.Request) {
+	if s.listener == nil {
+		s.jsonError(w, "Listener not running", http.StatusServiceUnavailable)
+		return
+	}
+
+	status, err := s.listener.SyncStatus(r.Context())
+	if err != nil {
+		s.jsonError(w, fmt.Sprintf("Failed to get sync status: %v", err), http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, Response{Success: true, Data: status})
+}
+
+// handlePnL reports locked-in vs paper PnL
+func (s *Server) handlePnL(w http.ResponseWriter, r *http.Request) {
+	realized, unrealized, err := s.db.GetPnLSummary(r.Context())
+	if err != nil {
+		s.jsonError(w, fmt.Sprintf("Failed to get PnL: %v", err), http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, Response{Success: true, Data: PnLSummary{
+		Realized:   realized,
+		Unrealized: unrealized,
+		Total:      realized + unrealized,
+	}})
+}
+
+// handlePause stops the executor acting on signals, persisted across restarts
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	s.setPaused(w, r, true)
+}
+
+// handleResume undoes POST /pause
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	s.setPaused(w, r, false)
+}
+
+func (s *Server) setPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	if s.exec == nil {
+		s.jsonError(w, "Executor not running", http.StatusServiceUnavailable)
+		return
+	}
+
+	set := s.exec.Resume
+	if paused {
+		set = s.exec.Pause
+	}
+	if err := set(r.Context()); err != nil {
+		s.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, Response{Success: true, Data: map[string]bool{"paused": s.exec.Paused()}})
+}
+
+// handleStats reports realized PnL next to open positions and deposits
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	realized, err := s.db.GetRealizedPnL(r.Context())
+	if err != nil {
+		s.jsonError(w, fmt.Sprintf("Failed to get realized PnL: %v", err), http.StatusInternalServerError)
+		return
+	}
+	open, err := s.db.CountOpenPositions(r.Context())
+	if err != nil {
+		s.jsonError(w, fmt.Sprintf("Failed to count open positions: %v", err), http.StatusInternalServerError)
+		return
+	}
+	deposited, err := s.db.GetTotalDeposited(r.Context())
+	if err != nil {
+		s.jsonError(w, fmt.Sprintf("Failed to get deposits: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonResponse(w, Response{Success: true, Data: Stats{
+		RealizedPnL:    realized,
+		OpenPositions:  open,
+		TotalDeposited: deposited,
+	}})
+}
+
+// handleRecoveryReport lists recent backfills and the signals each one
+// recovered that had been missed
+func (s *Server) handleRecoveryReport(w http.ResponseWriter, r *http.Request) {
+	if s.listener == nil {
+		s.jsonError(w, "Listener not running", http.StatusServiceUnavailable)
+		return
+	}
+	s.jsonResponse(w, Response{Success: true, Data: s.listener.RecoveryReports()})
+}
+
+// handleRecomputeWinRates re-derives every tracked trader's win rate. It runs
+// on the request context, so a client disconnect cancels it.
+func (s *Server) handleRecomputeWinRates(w http.ResponseWriter, r *http.Request) {
+	updated, err := s.ingestor.RecomputeWinRates(r.Context())
+	if err != nil {
+		s.jsonError(w, fmt.Sprintf("Recompute stopped after %d traders: %v", updated, err), http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, Response{Success: true, Data: map[string]int{"updated": updated}})
+}
+
+// handleVacuum runs an on-demand VACUUM, refusing while transactions are open
+func (s *Server) handleVacuum(w http.ResponseWriter, r *http.Request) {
+	before, _ := s.db.SizeBytes(r.Context())
+	start := time.Now()
+	if err := s.db.Vacuum(r.Context()); err != nil {
+		if errors.Is(err, database.ErrWritersActive) {
+			s.jsonError(w, "Database is busy, retry shortly", http.StatusConflict)
+			return
+		}
+		s.jsonError(w, fmt.Sprintf("Vacuum failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+	after, _ := s.db.SizeBytes(r.Context())
+
+	s.jsonResponse(w, Response{Success: true, Data: map[string]interface{}{
+		"size_before": before,
+		"size_after":  after,
+		"duration_ms": time.Since(start).Milliseconds(),
+	}})
+}
+
+// handleStalePositions lists open positions older than ?olderThan (default 7d)
+func (s *Server) handleStalePositions(w http.ResponseWriter, r *http.Request) {
+	olderThan := 7 * 24 * time.Hour
+	if v := r.URL.Query().Get("olderThan"); v != "" {
+		d, err := parseAge(v)
+		if err != nil || d <= 0 {
+			s.jsonError(w, fmt.Sprintf("Invalid olderThan %q", v), http.StatusBadRequest)
+			return
+		}
+		olderThan = d
+	}
+
+	positions, err := s.db.GetPositionsOlderThan(r.Context(), olderThan)
+	if err != nil {
+		s.jsonError(w, fmt.Sprintf("Failed to get positions: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	stale := make([]PositionView, 0, len(positions))
+	for _, p := range positions {
+		stale = append(stale, PositionView{
+			Position:      s.withMarketNames(r.Context(), p),
+			UnrealizedPnL: (p.CurrentPrice - p.AvgPrice) * p.Amount,
+		})
+	}
+	s.jsonResponse(w, Response{Success: true, Data: stale})
+}
+
+// withMarketNames fills in the question and outcome of positions opened
+// before tokens were resolved, or while resolving failed
+func (s *Server) withMarketNames(ctx context.Context, p database.Position) database.Position {
+	if p.Question != "" && p.Outcome != "" {
+		return p
+	}
+	token, err := s.tokens.Resolve(ctx, p.TokenID)
+	if err != nil {
+		return p
+	}
+	p.Question = token.Question
+	if p.Outcome == "" {
+		p.Outcome = token.Outcome
+	}
+	return p
+}
+
+// parseAge parses a duration, additionally accepting whole days ("7d")
+func parseAge(v string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(v)
+}
 
 func (s *Server) handleLeaderboard(w http.ResponseWriter, r *http.Request) {
 	limit := 20
@@ -163,28 +564,128 @@ func (s *Server) handleLeaderboard(w http.ResponseWriter, r *http.Request) {
 		fmt.Sscanf(limitStr, "%d", &limit)
 	}
 
-	traders, err := s.db.GetTopTraders(limit)
+	traders, err := s.db.GetTopTradersDetailed(r.Context(), limit)
 	if err != nil {
 		s.jsonError(w, fmt.Sprintf("Failed to get leaderboard: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	// Get full details for each trader
-	var leaderboard []map[string]interface{}
-	for _, trader := range traders {
-		// TODO: Get full trader details from database
-		leaderboard = append(leaderboard, map[string]interface{}{
-			"address": trader,
-		})
+	s.jsonResponse(w, Response{Success: true, Data: traders})
+}
+
+// handleTraderSettings sets per-trader copy settings. A multiplier of 0
+// reverts the trader to copy_trade_multiplier.
+func (s *Server) handleTraderSettings(w http.ResponseWriter, r *http.Request) {
+	address := mux.Vars(r)["address"]
+
+	var req TraderSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if req.Multiplier < 0 || math.IsInf(req.Multiplier, 0) || math.IsNaN(req.Multiplier) {
+		s.jsonError(w, "Multiplier must be zero or positive", http.StatusBadRequest)
+		return
+	}
+
+	err := s.db.SetTraderMultiplier(r.Context(), address, req.Multiplier)
+	if errors.Is(err, database.ErrInvalidAddress) {
+		s.jsonError(w, "Invalid address", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		s.jsonError(w, fmt.Sprintf("Failed to save trader settings: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	multiplier, err := s.db.GetTraderMultiplier(r.Context(), address, s.cfg.CopyTradeMultiplier)
+	if err != nil {
+		s.jsonError(w, fmt.Sprintf("Failed to get trader settings: %v", err), http.StatusInternalServerError)
+		return
 	}
+	s.jsonResponse(w, Response{Success: true, Data: map[string]interface{}{
+		"address":    address,
+		"multiplier": multiplier,
+	}})
+}
 
-	s.jsonResponse(w, Response{Success: true, Data: leaderboard})
+// handleLeaderboardDecisions shows why traders were or weren't tracked,
+// optionally for a single ?address=
+func (s *Server) handleLeaderboardDecisions(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		fmt.Sscanf(limitStr, "%d", &limit)
+	}
+
+	decisions, err := s.db.GetLeaderboardDecisions(r.Context(), r.URL.Query().Get("address"), limit)
+	if errors.Is(err, database.ErrInvalidAddress) {
+		s.jsonError(w, "Invalid address", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		s.jsonError(w, fmt.Sprintf("Failed to get leaderboard decisions: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonResponse(w, Response{Success: true, Data: decisions})
 }
 
+// handleRefreshLeaderboard fetches the leaderboard from the Data API right
+// away and waits for it, up to leaderboardRefreshTimeout
 func (s *Server) handleRefreshLeaderboard(w http.ResponseWriter, r *http.Request) {
-	// Trigger an immediate leaderboard refresh
-	// This would need to be implemented in the ingestion service
-	s.jsonResponse(w, Response{Success: true, Data: "Leaderboard refresh triggered"})
+	ctx, cancel := context.WithTimeout(r.Context(), leaderboardRefreshTimeout)
+	defer cancel()
+
+	updated, err := s.ingestor.Refresh(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		s.jsonError(w, "Leaderboard refresh still running, check /leaderboard shortly", http.StatusGatewayTimeout)
+		return
+	}
+	if err != nil {
+		s.jsonError(w, fmt.Sprintf("Leaderboard refresh failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonResponse(w, Response{Success: true, Data: map[string]int{"updated": updated}})
+}
+
+// handleBacktest replays stored signals through a strategy built from the
+// request params. Nothing is written, so live positions are untouched.
+func (s *Server) handleBacktest(w http.ResponseWriter, r *http.Request) {
+	req := BacktestRequest{
+		Params: strategy.Params{Multiplier: s.cfg.CopyTradeMultiplier},
+		Limit:  1000,
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if req.Limit <= 0 {
+		s.jsonError(w, "limit must be positive", http.StatusBadRequest)
+		return
+	}
+	if req.Mode == strategy.ModeFixed && req.FixedAmount <= 0 {
+		s.jsonError(w, "fixed_amount must be positive in fixed mode", http.StatusBadRequest)
+		return
+	}
+	if req.Mode != strategy.ModeFixed && req.Multiplier <= 0 {
+		s.jsonError(w, "multiplier must be positive", http.StatusBadRequest)
+		return
+	}
+
+	stored, err := s.db.GetSignalHistory(r.Context(), req.Limit)
+	if err != nil {
+		s.jsonError(w, fmt.Sprintf("Failed to get signals: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	signals := make([]strategy.Signal, 0, len(stored))
+	for _, sig := range stored {
+		signals = append(signals, strategy.FromSignal(sig))
+	}
+
+	result := strategy.Backtest(strategy.New(req.Params), signals)
+	s.jsonResponse(w, Response{Success: true, Data: result})
 }
 
 func (s *Server) jsonResponse(w http.ResponseWriter, resp Response) {
@@ -196,4 +697,4 @@ func (s *Server) jsonError(w http.ResponseWriter, message string, statusCode int
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 	json.NewEncoder(w).Encode(Response{Success: false, Error: message})
-}
\ No newline at end of file
+}
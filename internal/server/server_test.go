@@ -0,0 +1,170 @@
+// internal/server/server_test.go
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/askwhyharsh/lazytrader/internal/config"
+	"github.com/askwhyharsh/lazytrader/internal/database"
+	"github.com/askwhyharsh/lazytrader/internal/events"
+	"github.com/askwhyharsh/lazytrader/internal/executor"
+)
+
+// newTestServer returns a server on a fresh database, without an executor,
+// listener or ingestion, and its API handler
+func newTestServer(t *testing.T) (*Server, *database.DB, http.Handler) {
+	t.Helper()
+	db, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("database.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	s := New(&config.Config{
+		StrategyID:        "default",
+		HTTPRateLimit:     1000,
+		IdempotencyKeyTTL: time.Hour,
+	}, db, events.New(), nil, nil, nil, nil)
+	return s, db, s.routes()
+}
+
+func TestStalledClientDisconnected(t *testing.T) {
+	s := New(&config.Config{
+		StrategyID:            "default",
+		HTTPReadTimeout:       200 * time.Millisecond,
+		HTTPReadHeaderTimeout: 100 * time.Millisecond,
+		HTTPWriteTimeout:      time.Second,
+		HTTPIdleTimeout:       time.Second,
+	}, nil, nil, nil, nil, nil, nil)
+	s.httpServer.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.httpServer.Serve(ln)
+	t.Cleanup(func() { s.httpServer.Close() })
+
+	tests := []struct {
+		name    string
+		partial string
+	}{
+		{"stalls in the headers", "GET /health HTTP/1.1\r\nHost: localhost\r\n"},
+		{"stalls in the body", "POST /deposit HTTP/1.1\r\nHost: localhost\r\nContent-Length: 100\r\n\r\n{"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := net.Dial("tcp", ln.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			if _, err := conn.Write([]byte(tt.partial)); err != nil {
+				t.Fatal(err)
+			}
+
+			// The server hangs up well before the client gives up waiting
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			start := time.Now()
+			_, err = io.ReadAll(conn)
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				t.Fatalf("connection still open after %v", time.Since(start))
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("connection closed after %v, want within the read timeout", elapsed)
+			}
+		})
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "7d", want: 7 * 24 * time.Hour},
+		{in: "36h", want: 36 * time.Hour},
+		{in: "90m", want: 90 * time.Minute},
+		{in: "xd", wantErr: true},
+		{in: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseAge(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseAge(%q) err = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseAge(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExecuteTradeIdempotency(t *testing.T) {
+	ctx := context.Background()
+	s, db, _ := newTestServer(t)
+	// A dry-run sell against a held position needs neither the chain nor
+	// the CLOB, and still records a trade per execution
+	s.exec = executor.New(&config.Config{StrategyID: "default", DryRun: true}, db, events.New(), nil)
+	if _, err := db.CreatePosition(ctx, "m", "123", "Yes", 100, 0.5); err != nil {
+		t.Fatalf("CreatePosition: %v", err)
+	}
+
+	const body = `{"market_id":"m","token_id":"123","outcome":"Yes","side":"sell","amount":10,"price":0.6}`
+	execute := func(key string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/trades/execute", strings.NewReader(body))
+		r.Header.Set("Idempotency-Key", key)
+		w := httptest.NewRecorder()
+		s.handleExecuteTrade(w, r)
+		return w
+	}
+
+	tests := []struct {
+		name         string
+		key          string
+		wantReplayed bool
+		wantTrades   int
+	}{
+		{"first request executes", "key-1", false, 1},
+		{"retry with the same key replays", "key-1", true, 1},
+		{"different key executes", "key-2", false, 2},
+	}
+
+	var first string
+	for _, tt := range tests {
+		w := execute(tt.key)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status %d %s, want 200", tt.name, w.Code, w.Body)
+		}
+		if replayed := w.Header().Get("Idempotent-Replayed") == "true"; replayed != tt.wantReplayed {
+			t.Errorf("%s: replayed %v, want %v", tt.name, replayed, tt.wantReplayed)
+		}
+		if first == "" {
+			first = w.Body.String()
+		} else if tt.wantReplayed && w.Body.String() != first {
+			t.Errorf("%s: body %s, want the original %s", tt.name, w.Body, first)
+		}
+		trades, err := db.GetTrades(ctx, database.TradeFilter{Limit: 10})
+		if err != nil {
+			t.Fatalf("GetTrades: %v", err)
+		}
+		if len(trades) != tt.wantTrades {
+			t.Errorf("%s: %d trades executed, want %d", tt.name, len(trades), tt.wantTrades)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// internal/server/stream.go
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/askwhyharsh/lazytrader/internal/events"
+)
+
+const (
+	// Undelivered signals buffered per client; a client further behind
+	// misses signals rather than holding up the listener
+	streamBuffer = 64
+
+	// A write taking longer than this means the client is gone or stuck
+	streamWriteWait = 10 * time.Second
+
+	// Clients are pinged every streamPingInterval and dropped when no pong
+	// arrives within streamPongWait
+	streamPongWait     = 60 * time.Second
+	streamPingInterval = streamPongWait * 9 / 10
+)
+
+// handleSignalStream upgrades to a WebSocket and sends every newly detected
+// signal, as a JSON database.Signal, until the client disconnects or the
+// server shuts down. Signals detected before connecting aren't replayed.
+func (s *Server) handleSignalStream(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{CheckOrigin: s.checkStreamOrigin}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // Upgrade has already answered
+	}
+	defer conn.Close()
+
+	signals, unsubscribe := s.bus.Subscribe(events.SignalDetected, streamBuffer)
+	defer unsubscribe()
+
+	// Clients only listen; reading is how their pongs and close frames arrive
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		conn.SetReadLimit(512)
+		conn.SetReadDeadline(time.Now().Add(streamPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(streamPongWait))
+		})
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(streamPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-gone:
+			return
+		case <-s.closing:
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"),
+				time.Now().Add(streamWriteWait))
+			return
+		case event, ok := <-signals:
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+			if err := conn.WriteJSON(event.Payload); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// checkStreamOrigin accepts handshakes from allowed_origins, from the API's
+// own origin, and from non-browser clients, which send no Origin
+func (s *Server) checkStreamOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || s.originAllowed(origin) {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
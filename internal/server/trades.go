@@ -0,0 +1,120 @@
+// internal/server/trades.go
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/askwhyharsh/lazytrader/internal/executor"
+)
+
+// Longest Idempotency-Key accepted
+const maxIdempotencyKeyLen = 255
+
+// handleExecuteTrade places a manual trade. Clients retrying after a timeout
+// send the same Idempotency-Key header; a repeat within idempotency_key_ttl
+// replays the original response instead of trading again.
+func (s *Server) handleExecuteTrade(w http.ResponseWriter, r *http.Request) {
+	if s.exec == nil {
+		s.jsonError(w, "Executor not running", http.StatusServiceUnavailable)
+		return
+	}
+
+	// A trade that reached the CLOB must still be recorded if the client
+	// hangs up, so the request's cancellation isn't passed on
+	ctx := context.WithoutCancel(r.Context())
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.jsonError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	var req TradeRequestAPI
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.jsonError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	key := r.Header.Get("Idempotency-Key")
+	if key == "" {
+		status, resp := s.executeTrade(ctx, req)
+		s.writeJSON(w, status, resp)
+		return
+	}
+	if len(key) > maxIdempotencyKeyLen {
+		s.jsonError(w, "Idempotency-Key too long", http.StatusBadRequest)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	requestHash := hex.EncodeToString(sum[:])
+	prior, claimed, err := s.db.ClaimIdempotencyKey(ctx, key, requestHash, s.cfg.IdempotencyKeyTTL)
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !claimed {
+		switch {
+		case prior.RequestHash != requestHash:
+			s.jsonError(w, "Idempotency-Key was already used with a different request", http.StatusUnprocessableEntity)
+		case prior.StatusCode == 0:
+			s.jsonError(w, "A request with this Idempotency-Key is still in progress", http.StatusConflict)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(prior.StatusCode)
+			w.Write(prior.Body)
+		}
+		return
+	}
+
+	status, resp := s.executeTrade(ctx, req)
+	raw := encodeResponse(resp)
+	if err := s.db.CompleteIdempotencyKey(ctx, key, status, raw); err != nil {
+		slog.Error("failed to store idempotent response", "idempotency_key", key, "err", err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(raw)
+}
+
+// executeTrade runs a manual trade and returns the response to send
+func (s *Server) executeTrade(ctx context.Context, req TradeRequestAPI) (int, Response) {
+	err := s.exec.ExecuteTrade(ctx, executor.TradeRequest{
+		MarketID: req.MarketID,
+		TokenID:  req.TokenID,
+		Outcome:  req.Outcome,
+		Side:     req.Side,
+		Amount:   req.Amount,
+		Price:    req.Price,
+	})
+	switch executor.Classify(err) {
+	case "":
+		return http.StatusOK, Response{Success: true, Data: "Trade executed"}
+	case executor.CategorySkip:
+		return http.StatusUnprocessableEntity, Response{Error: fmt.Sprintf("Trade skipped: %v", err)}
+	default:
+		return http.StatusInternalServerError, Response{Error: fmt.Sprintf("Failed to execute trade: %v", err)}
+	}
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(encodeResponse(resp))
+}
+
+// encodeResponse matches what jsonResponse writes, so replayed bodies are
+// byte-identical to the original
+func encodeResponse(resp Response) []byte {
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(resp)
+	return buf.Bytes()
+}
@@ -0,0 +1,97 @@
+// internal/strategy/backtest.go
+package strategy
+
+import "sort"
+
+// BacktestResult aggregates a simulated replay of historical signals
+type BacktestResult struct {
+	Signals     int     `json:"signals"`
+	Trades      int     `json:"trades"`
+	Skipped     int     `json:"skipped"`
+	Invested    float64 `json:"invested"`
+	RealizedPnL float64 `json:"realized_pnl"`
+	OpenPnL     float64 `json:"open_pnl"`
+	TotalPnL    float64 `json:"total_pnl"`
+	HitRate     float64 `json:"hit_rate"` // Share of copied buys priced below the final mark
+}
+
+type simPosition struct {
+	amount   float64
+	avgPrice float64
+}
+
+// Backtest replays signals (oldest first) through a strategy. Buys average
+// into a simulated book and sells reduce it at the signal price, realizing
+// PnL. Whatever remains open is marked at the last price seen for its token,
+// so results depend only on the stored signals.
+func Backtest(s Strategy, signals []Signal) BacktestResult {
+	result := BacktestResult{Signals: len(signals)}
+
+	marks := make(map[string]float64)
+	for _, sig := range signals {
+		if sig.Price > 0 {
+			marks[sig.TokenID] = sig.Price
+		}
+	}
+
+	book := make(map[string]*simPosition)
+	var buys []Signal
+	for _, sig := range signals {
+		decision := s.Size(sig)
+		if decision.Skip != "" {
+			result.Skipped++
+			continue
+		}
+
+		pos := book[sig.TokenID]
+		if sig.Side == "sell" {
+			if pos == nil || pos.amount <= 0 {
+				result.Skipped++
+				continue
+			}
+			amount := decision.Amount
+			if amount > pos.amount {
+				amount = pos.amount
+			}
+			result.RealizedPnL += (sig.Price - pos.avgPrice) * amount
+			pos.amount -= amount
+			result.Trades++
+			continue
+		}
+
+		if pos == nil {
+			pos = &simPosition{}
+			book[sig.TokenID] = pos
+		}
+		cost := decision.Amount * sig.Price
+		pos.avgPrice = (pos.avgPrice*pos.amount + cost) / (pos.amount + decision.Amount)
+		pos.amount += decision.Amount
+		result.Invested += cost
+		result.Trades++
+		buys = append(buys, sig)
+	}
+
+	// Sum in a fixed order so repeated runs give identical floats
+	tokenIDs := make([]string, 0, len(book))
+	for tokenID := range book {
+		tokenIDs = append(tokenIDs, tokenID)
+	}
+	sort.Strings(tokenIDs)
+	for _, tokenID := range tokenIDs {
+		pos := book[tokenID]
+		result.OpenPnL += (marks[tokenID] - pos.avgPrice) * pos.amount
+	}
+	result.TotalPnL = result.RealizedPnL + result.OpenPnL
+
+	if len(buys) > 0 {
+		hits := 0
+		for _, sig := range buys {
+			if marks[sig.TokenID] > sig.Price {
+				hits++
+			}
+		}
+		result.HitRate = float64(hits) / float64(len(buys))
+	}
+
+	return result
+}
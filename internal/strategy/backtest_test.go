@@ -0,0 +1,97 @@
+// internal/strategy/backtest_test.go
+package strategy
+
+import (
+	"math"
+	"testing"
+)
+
+// backtestSignals is a short history over two tokens, oldest first
+var backtestSignals = []Signal{
+	{Trader: "0xa", Side: "buy", TokenID: "A", Amount: 1000, Price: 0.40},
+	{Trader: "0xb", Side: "buy", TokenID: "B", Amount: 500, Price: 0.60},
+	{Trader: "0xa", Side: "sell", TokenID: "A", Amount: 500, Price: 0.55},
+	{Trader: "0xa", Side: "buy", TokenID: "A", Amount: 200, Price: 0.50},
+	{Trader: "0xb", Side: "buy", TokenID: "B", Amount: 100, Price: 0.30},  // Below min notional
+	{Trader: "0xc", Side: "sell", TokenID: "C", Amount: 100, Price: 0.50}, // Nothing to sell
+	{Trader: "0xb", Side: "buy", TokenID: "B", Amount: 100, Price: 0.70},
+}
+
+func TestBacktest(t *testing.T) {
+	tests := []struct {
+		name   string
+		params Params
+		want   BacktestResult
+	}{
+		{
+			name:   "proportional",
+			params: Params{Multiplier: 0.1, MinNotional: 5},
+			want: BacktestResult{
+				Signals:     7,
+				Trades:      5,
+				Skipped:     2,
+				Invested:    87,
+				RealizedPnL: 7.5,
+				OpenPnL:     10,
+				TotalPnL:    17.5,
+				HitRate:     0.5,
+			},
+		},
+		{
+			name:   "buys only",
+			params: Params{Multiplier: 0.1, MinNotional: 5, Sides: []string{"buy"}},
+			want: BacktestResult{
+				Signals:  7,
+				Trades:   4,
+				Skipped:  3,
+				Invested: 87,
+				OpenPnL:  15,
+				TotalPnL: 15,
+				HitRate:  0.5,
+			},
+		},
+		{
+			name:   "capped",
+			params: Params{Multiplier: 0.1, MaxNotional: 30, MinNotional: 5},
+			want: BacktestResult{
+				Signals:     7,
+				Trades:      5,
+				Skipped:     2,
+				Invested:    77,
+				RealizedPnL: 7.5,
+				OpenPnL:     7.5,
+				TotalPnL:    15,
+				HitRate:     0.5,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Backtest(New(tt.params), backtestSignals)
+			if got.Signals != tt.want.Signals || got.Trades != tt.want.Trades || got.Skipped != tt.want.Skipped {
+				t.Errorf("signals, trades, skipped = %d, %d, %d, want %d, %d, %d",
+					got.Signals, got.Trades, got.Skipped, tt.want.Signals, tt.want.Trades, tt.want.Skipped)
+			}
+			for _, f := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"Invested", got.Invested, tt.want.Invested},
+				{"RealizedPnL", got.RealizedPnL, tt.want.RealizedPnL},
+				{"OpenPnL", got.OpenPnL, tt.want.OpenPnL},
+				{"TotalPnL", got.TotalPnL, tt.want.TotalPnL},
+				{"HitRate", got.HitRate, tt.want.HitRate},
+			} {
+				if math.Abs(f.got-f.want) > 1e-9 {
+					t.Errorf("%s = %v, want %v", f.name, f.got, f.want)
+				}
+			}
+
+			// Replays are deterministic down to the last bit
+			if again := Backtest(New(tt.params), backtestSignals); again != got {
+				t.Errorf("second run = %+v, want %+v", again, got)
+			}
+		})
+	}
+}
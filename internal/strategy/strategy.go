@@ -0,0 +1,166 @@
+// internal/strategy/strategy.go
+package strategy
+
+import (
+	"strings"
+
+	"github.com/askwhyharsh/lazytrader/internal/config"
+	"github.com/askwhyharsh/lazytrader/internal/database"
+	"github.com/askwhyharsh/lazytrader/internal/units"
+)
+
+// Signal is a top trader fill as seen by a strategy, in human units
+type Signal struct {
+	Trader  string
+	Side    string // "buy" or "sell"
+	TokenID string
+	Amount  float64 // Shares traded by the source trader
+	Price   float64 // USDC per share, between 0 and 1
+
+	// Per-trader copy multiplier, 0 uses the strategy's
+	Multiplier float64
+}
+
+// Decision is how much of a signal to copy. Skip holds the reason when the
+// signal shouldn't be copied at all.
+type Decision struct {
+	Amount float64
+	Skip   string
+}
+
+// Strategy sizes copy trades for incoming signals
+type Strategy interface {
+	Size(sig Signal) Decision
+}
+
+// Sizing modes
+const (
+	ModeProportional = "proportional"
+	ModeFixed        = "fixed"
+)
+
+// Params configure the copy strategies
+type Params struct {
+	Mode        string   `json:"mode"` // "proportional" (default) or "fixed"
+	Multiplier  float64  `json:"multiplier"`
+	FixedAmount float64  `json:"fixed_amount"` // USDC per copied buy in fixed mode
+	MaxNotional float64  `json:"max_notional"` // USDC cap per copy, 0 disables
+	MinNotional float64  `json:"min_notional"` // Smaller copies are skipped
+	Traders     []string `json:"traders"`      // Only copy these traders (empty copies all)
+	Sides       []string `json:"sides"`        // Only copy these sides (empty copies both)
+}
+
+// Proportional copies a fixed fraction of each source trade
+type Proportional struct {
+	Params
+}
+
+func NewProportional(params Params) *Proportional {
+	return &Proportional{Params: params}
+}
+
+// Fixed buys the same USDC notional on every copied buy, regardless of the
+// source trader's size. Sells are sized proportionally.
+type Fixed struct {
+	Params
+}
+
+func NewFixed(params Params) *Fixed {
+	return &Fixed{Params: params}
+}
+
+// New builds the strategy selected by params.Mode
+func New(params Params) Strategy {
+	if params.Mode == ModeFixed {
+		return NewFixed(params)
+	}
+	return NewProportional(params)
+}
+
+// FromConfig builds the live strategy from config
+func FromConfig(cfg *config.Config) Strategy {
+	return New(Params{
+		Mode:        cfg.SizingMode,
+		Multiplier:  cfg.CopyTradeMultiplier,
+		FixedAmount: cfg.FixedCopyAmount,
+		MaxNotional: cfg.MaxTradeNotional,
+		MinNotional: cfg.MinTradeNotional,
+	})
+}
+
+func (p *Proportional) Size(sig Signal) Decision {
+	if skip := p.filter(sig); skip != "" {
+		return Decision{Skip: skip}
+	}
+	return p.applyCaps(sig.Amount*p.multiplierFor(sig), sig.Price)
+}
+
+func (f *Fixed) Size(sig Signal) Decision {
+	if skip := f.filter(sig); skip != "" {
+		return Decision{Skip: skip}
+	}
+	if sig.Side != "buy" {
+		return f.applyCaps(sig.Amount*f.multiplierFor(sig), sig.Price)
+	}
+	return f.applyCaps(f.FixedAmount/sig.Price, sig.Price)
+}
+
+// multiplierFor is the signal's per-trader multiplier if set, else the
+// strategy's
+func (p Params) multiplierFor(sig Signal) float64 {
+	if sig.Multiplier > 0 {
+		return sig.Multiplier
+	}
+	return p.Multiplier
+}
+
+// filter returns the skip reason for signals the params exclude
+func (p Params) filter(sig Signal) string {
+	if len(p.Traders) > 0 && !containsFold(p.Traders, sig.Trader) {
+		return "skipped_trader_filtered"
+	}
+	if len(p.Sides) > 0 && !containsFold(p.Sides, sig.Side) {
+		return "skipped_side_filtered"
+	}
+	if sig.Price <= 0 {
+		return "skipped_no_price"
+	}
+	return ""
+}
+
+// applyCaps trims a share amount to the notional cap and skips it when it
+// ends up below the minimum size
+func (p Params) applyCaps(amount, price float64) Decision {
+	if p.MaxNotional > 0 && amount*price > p.MaxNotional {
+		amount = p.MaxNotional / price
+	}
+	if amount*price < p.MinNotional || amount <= 0 {
+		return Decision{Skip: "skipped_below_min_size"}
+	}
+	return Decision{Amount: amount}
+}
+
+// FromSignal converts a stored signal's raw 6-decimal amounts to a strategy signal
+func FromSignal(sig database.Signal) Signal {
+	return Signal{
+		Trader:  sig.Trader,
+		Side:    strings.ToLower(sig.Side),
+		TokenID: sig.TokenID,
+		Amount:  FromBaseUnits(sig.Amount),
+		Price:   FromBaseUnits(sig.Price),
+	}
+}
+
+// FromBaseUnits converts a raw 6-decimal on-chain integer string to a float
+func FromBaseUnits(raw string) float64 {
+	return units.ParseToFloat(raw, units.Decimals)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
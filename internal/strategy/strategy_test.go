@@ -0,0 +1,121 @@
+// internal/strategy/strategy_test.go
+package strategy
+
+import (
+	"math"
+	"testing"
+
+	"github.com/askwhyharsh/lazytrader/internal/database"
+)
+
+func TestSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     Params
+		sig        Signal
+		wantAmount float64
+		wantSkip   string
+	}{
+		{
+			name:       "proportional",
+			params:     Params{Multiplier: 0.1},
+			sig:        Signal{Side: "buy", Amount: 1000, Price: 0.5},
+			wantAmount: 100,
+		},
+		{
+			name:       "per-trader multiplier",
+			params:     Params{Multiplier: 0.1},
+			sig:        Signal{Side: "buy", Amount: 1000, Price: 0.5, Multiplier: 0.25},
+			wantAmount: 250,
+		},
+		{
+			name:       "capped at max notional",
+			params:     Params{Multiplier: 0.1, MaxNotional: 20},
+			sig:        Signal{Side: "buy", Amount: 1000, Price: 0.5},
+			wantAmount: 40,
+		},
+		{
+			name:     "below min notional",
+			params:   Params{Multiplier: 0.1, MinNotional: 5},
+			sig:      Signal{Side: "buy", Amount: 50, Price: 0.5},
+			wantSkip: "skipped_below_min_size",
+		},
+		{
+			name:       "fixed buy",
+			params:     Params{Mode: ModeFixed, Multiplier: 0.1, FixedAmount: 10},
+			sig:        Signal{Side: "buy", Amount: 1000, Price: 0.25},
+			wantAmount: 40,
+		},
+		{
+			name:       "fixed buy ignores the multiplier",
+			params:     Params{Mode: ModeFixed, Multiplier: 5, FixedAmount: 10},
+			sig:        Signal{Side: "buy", Amount: 1, Price: 0.5, Multiplier: 2},
+			wantAmount: 20,
+		},
+		{
+			name:       "fixed buy capped at max notional",
+			params:     Params{Mode: ModeFixed, FixedAmount: 50, MaxNotional: 20},
+			sig:        Signal{Side: "buy", Amount: 1000, Price: 0.25},
+			wantAmount: 80,
+		},
+		{
+			name:     "fixed buy below min notional",
+			params:   Params{Mode: ModeFixed, FixedAmount: 2, MinNotional: 5},
+			sig:      Signal{Side: "buy", Amount: 1000, Price: 0.25},
+			wantSkip: "skipped_below_min_size",
+		},
+		{
+			name:       "fixed sell is proportional",
+			params:     Params{Mode: ModeFixed, Multiplier: 0.1, FixedAmount: 10},
+			sig:        Signal{Side: "sell", Amount: 1000, Price: 0.25},
+			wantAmount: 100,
+		},
+		{
+			name:     "no price",
+			params:   Params{Multiplier: 0.1},
+			sig:      Signal{Side: "buy", Amount: 1000},
+			wantSkip: "skipped_no_price",
+		},
+		{
+			name:       "trader filter matches any case",
+			params:     Params{Multiplier: 0.1, Traders: []string{"0xABC"}},
+			sig:        Signal{Trader: "0xabc", Side: "buy", Amount: 1000, Price: 0.5},
+			wantAmount: 100,
+		},
+		{
+			name:     "trader filtered",
+			params:   Params{Multiplier: 0.1, Traders: []string{"0xabc"}},
+			sig:      Signal{Trader: "0xdef", Side: "buy", Amount: 1000, Price: 0.5},
+			wantSkip: "skipped_trader_filtered",
+		},
+		{
+			name:     "side filtered",
+			params:   Params{Multiplier: 0.1, Sides: []string{"buy"}},
+			sig:      Signal{Side: "sell", Amount: 1000, Price: 0.5},
+			wantSkip: "skipped_side_filtered",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New(tt.params).Size(tt.sig)
+			if got.Skip != tt.wantSkip {
+				t.Fatalf("Skip = %q, want %q", got.Skip, tt.wantSkip)
+			}
+			if math.Abs(got.Amount-tt.wantAmount) > 1e-9 {
+				t.Errorf("Amount = %v, want %v", got.Amount, tt.wantAmount)
+			}
+		})
+	}
+}
+
+func TestFromSignal(t *testing.T) {
+	sig := FromSignal(database.Signal{Trader: "0xabc", Side: "BUY", TokenID: "42", Amount: "12500000", Price: "450000"})
+
+	if sig.Side != "buy" {
+		t.Errorf("Side = %q, want buy", sig.Side)
+	}
+	if sig.Amount != 12.5 || sig.Price != 0.45 {
+		t.Errorf("Amount, Price = %v, %v, want 12.5, 0.45", sig.Amount, sig.Price)
+	}
+}
@@ -0,0 +1,76 @@
+// internal/units/units.go
+package units
+
+import (
+	"log/slog"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// USDC and Polymarket outcome tokens both use 6 decimals
+const Decimals = 6
+
+// Largest integer a float64 represents exactly (2^53)
+var maxExactFloat = new(big.Int).Lsh(big.NewInt(1), 53)
+
+// ExceedsFloat64 reports whether raw can't be held exactly in a float64
+func ExceedsFloat64(raw *big.Int) bool {
+	return raw != nil && raw.CmpAbs(maxExactFloat) > 0
+}
+
+// ToDecimalString formats raw scaled down by decimals without any rounding,
+// e.g. 1234500000 with 6 decimals is "1234.5"
+func ToDecimalString(raw *big.Int, decimals int) string {
+	if raw == nil {
+		return "0"
+	}
+
+	digits := new(big.Int).Abs(raw).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	whole, frac := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+
+	s := whole
+	if frac != "" {
+		s += "." + frac
+	}
+	if raw.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// ToFloat scales raw down by decimals. Values beyond float64's exact integer
+// range are rounded to the nearest float64 and logged rather than silently
+// losing precision; keep ToDecimalString for anything that must be exact.
+func ToFloat(raw *big.Int, decimals int) float64 {
+	if raw == nil {
+		return 0
+	}
+	if ExceedsFloat64(raw) {
+		slog.Warn("amount exceeds float64 precision, rounding", "raw", raw.String(), "exact", ToDecimalString(raw, decimals))
+	}
+
+	scale := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(raw), scale).Float64()
+	return f
+}
+
+// FromFloat scales v up by decimals, rounding to the nearest base unit
+func FromFloat(v float64, decimals int) *big.Int {
+	scaled := math.Round(v * math.Pow10(decimals))
+	raw, _ := new(big.Float).SetFloat64(scaled).Int(nil)
+	return raw
+}
+
+// ParseToFloat is ToFloat for a raw integer string, returning 0 for anything
+// that isn't an integer
+func ParseToFloat(raw string, decimals int) float64 {
+	v, ok := new(big.Int).SetString(strings.TrimSpace(raw), 10)
+	if !ok {
+		return 0
+	}
+	return ToFloat(v, decimals)
+}
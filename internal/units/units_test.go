@@ -0,0 +1,110 @@
+// internal/units/units_test.go
+package units
+
+import (
+	"bytes"
+	"log/slog"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// bigInt parses a base-10 integer, failing the test on bad input
+func bigInt(t *testing.T, s string) *big.Int {
+	t.Helper()
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		t.Fatalf("bad integer %q", s)
+	}
+	return v
+}
+
+func TestToDecimalString(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"0", "0"},
+		{"1", "0.000001"},
+		{"1234500000", "1234.5"},
+		{"-2500000", "-2.5"},
+		{"9007199254740993", "9007199254.740993"}, // 2^53 + 1, not representable as a float64
+		{"123456789012345678901234567", "123456789012345678901.234567"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := ToDecimalString(bigInt(t, tt.raw), Decimals); got != tt.want {
+				t.Errorf("ToDecimalString(%s) = %s, want %s", tt.raw, got, tt.want)
+			}
+		})
+	}
+	if got := ToDecimalString(nil, Decimals); got != "0" {
+		t.Errorf("ToDecimalString(nil) = %s, want 0", got)
+	}
+}
+
+func TestExceedsFloat64(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want bool
+	}{
+		{"9007199254740992", false}, // 2^53
+		{"9007199254740993", true},
+		{"-9007199254740993", true},
+		{"1000000", false},
+	}
+
+	for _, tt := range tests {
+		if got := ExceedsFloat64(bigInt(t, tt.raw)); got != tt.want {
+			t.Errorf("ExceedsFloat64(%s) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestToFloatWarnsBeyondPrecision(t *testing.T) {
+	var logs bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(prev)
+
+	if got := ToFloat(bigInt(t, "1234500000"), Decimals); got != 1234.5 {
+		t.Errorf("ToFloat = %v, want 1234.5", got)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("warning logged for an exact value: %s", logs.String())
+	}
+
+	// 2^53 + 1 rounds, and the warning carries the exact value
+	ToFloat(bigInt(t, "9007199254740993"), Decimals)
+	if !strings.Contains(logs.String(), "exceeds float64 precision") || !strings.Contains(logs.String(), "9007199254.740993") {
+		t.Errorf("no precision warning with the exact value, got: %s", logs.String())
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{0, "0"},
+		{1.5, "1500000"},
+		{0.0000014, "1"}, // rounded to the nearest base unit
+		{-2.25, "-2250000"},
+	}
+
+	for _, tt := range tests {
+		if got := FromFloat(tt.v, Decimals).String(); got != tt.want {
+			t.Errorf("FromFloat(%v) = %s, want %s", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestParseToFloat(t *testing.T) {
+	if got := ParseToFloat(" 2500000 ", Decimals); got != 2.5 {
+		t.Errorf("ParseToFloat = %v, want 2.5", got)
+	}
+	if got := ParseToFloat("2.5", Decimals); got != 0 {
+		t.Errorf("ParseToFloat of a non-integer = %v, want 0", got)
+	}
+}